@@ -54,6 +54,9 @@ const (
 	mgmtUploadIDMarker mgmtQueryKey = "upload-id-marker"
 	mgmtMaxUploads     mgmtQueryKey = "max-uploads"
 	mgmtUploadID       mgmtQueryKey = "upload-id"
+	mgmtStorageClass   mgmtQueryKey = "storage-class"
+	mgmtSample         mgmtQueryKey = "sample"
+	mgmtMaxObjects     mgmtQueryKey = "max-objects"
 )
 
 // ServerVersion - server version
@@ -238,6 +241,55 @@ type ServerHTTPStats struct {
 	SuccessDELETEStats ServerHTTPMethodStats `json:"successDELETEs"`
 }
 
+// ServerStorageClassCounter holds the number of objects and bytes
+// stored with a storage class, and the bytes freed on disks by
+// deleting objects of the storage class
+type ServerStorageClassCounter struct {
+	Objects    uint64 `json:"objects"`
+	Bytes      uint64 `json:"bytes"`
+	FreedBytes uint64 `json:"freedBytes"`
+}
+
+// ServerStorageClassStats holds the objects and bytes written
+// by the server per storage class
+type ServerStorageClassStats struct {
+	Standard      ServerStorageClassCounter `json:"STANDARD"`
+	RRS           ServerStorageClassCounter `json:"REDUCED_REDUNDANCY"`
+	MaxDurability ServerStorageClassCounter `json:"MAX_DURABILITY"`
+	Scratch       ServerStorageClassCounter `json:"SCRATCH"`
+}
+
+// ServerQuorumMarginStats holds the number of objects read per quorum
+// margin, the number of valid xl.json(s) found above the data blocks
+// needed. Margins[i] is the number of reads with margin i, the last
+// element also counts larger margins. Lost is the number of reads
+// below read quorum. Empty if the stats are disabled.
+type ServerQuorumMarginStats struct {
+	Lost    uint64   `json:"lost"`
+	Margins []uint64 `json:"margins,omitempty"`
+}
+
+// ServerStorageClassLayout holds the data and parity disks a
+// storage class resolves to, Scheme is empty and Source is
+// "default" if the storage class parity is not configured.
+type ServerStorageClassLayout struct {
+	Scheme string `json:"scheme,omitempty"`
+	Data   int    `json:"data"`
+	Parity int    `json:"parity"`
+	Source string `json:"source"`
+}
+
+// ServerStorageClassConfig holds the active storage class
+// configuration of a server. Layouts are empty if the server
+// is not erasure coded.
+type ServerStorageClassConfig struct {
+	StorageClasses []string                 `json:"storageClasses"`
+	Standard       ServerStorageClassLayout `json:"STANDARD"`
+	RRS            ServerStorageClassLayout `json:"REDUCED_REDUNDANCY"`
+	MaxDurability  ServerStorageClassLayout `json:"MAX_DURABILITY"`
+	Scratch        ServerStorageClassLayout `json:"SCRATCH"`
+}
+
 // ServerInfoData holds storage, connections and other
 // information of a given server.
 type ServerInfoData struct {
@@ -245,6 +297,11 @@ type ServerInfoData struct {
 	ConnStats   ServerConnStats  `json:"network"`
 	HTTPStats   ServerHTTPStats  `json:"http"`
 	Properties  ServerProperties `json:"server"`
+
+	StorageClassStats ServerStorageClassStats `json:"storageClass"`
+	QuorumMarginStats ServerQuorumMarginStats `json:"quorumMargin"`
+
+	StorageClassConfig ServerStorageClassConfig `json:"storageClassConfig"`
 }
 
 // ServerInfo holds server information result of one node
@@ -1042,3 +1099,394 @@ func (adminAPI adminAPIHandlers) SetConfigHandler(w http.ResponseWriter, r *http
 	// Restart all node for the modified config to take effect.
 	sendServiceCmd(globalAdminPeers, serviceRestart)
 }
+
+// GetBucketStorageClassHandler - GET /?storageclass&bucket=mybucket
+// - x-minio-operation = get
+// - bucket is mandatory query parameter
+// Get storage class config of a given bucket.
+func (adminAPI adminAPIHandlers) GetBucketStorageClassHandler(w http.ResponseWriter, r *http.Request) {
+	// Get current object layer instance.
+	objectAPI := newObjectLayerFn()
+	if objectAPI == nil {
+		writeErrorResponse(w, ErrServerNotInitialized, r.URL)
+		return
+	}
+
+	// Validate request signature.
+	adminAPIErr := checkRequestAuthType(r, "", "", "")
+	if adminAPIErr != ErrNone {
+		writeErrorResponse(w, adminAPIErr, r.URL)
+		return
+	}
+
+	// Storage class is only applicable to erasure coded setups.
+	if !globalIsXL {
+		writeErrorResponse(w, ErrNotImplemented, r.URL)
+		return
+	}
+
+	// Validate bucket name and check if it exists.
+	bucket := r.URL.Query().Get(string(mgmtBucket))
+	if err := checkBucketExist(bucket, objectAPI); err != nil {
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	scCfg, _ := globalBucketStorageClass.GetBucketStorageClass(bucket)
+	jsonBytes, err := json.Marshal(&scCfg)
+	if err != nil {
+		writeErrorResponse(w, ErrInternalError, r.URL)
+		errorIf(err, "Failed to marshal bucket storage class into json.")
+		return
+	}
+
+	writeSuccessResponseJSON(w, jsonBytes)
+}
+
+// SetBucketStorageClassHandler - PUT /?storageclass&bucket=mybucket
+// - x-minio-operation = set
+// - bucket is mandatory query parameter
+// Set storage class config of a given bucket, a config with no
+// storage class set removes the bucket storage class config.
+func (adminAPI adminAPIHandlers) SetBucketStorageClassHandler(w http.ResponseWriter, r *http.Request) {
+	// Get current object layer instance.
+	objectAPI := newObjectLayerFn()
+	if objectAPI == nil {
+		writeErrorResponse(w, ErrServerNotInitialized, r.URL)
+		return
+	}
+
+	// Validate request signature.
+	adminAPIErr := checkRequestAuthType(r, "", "", "")
+	if adminAPIErr != ErrNone {
+		writeErrorResponse(w, adminAPIErr, r.URL)
+		return
+	}
+
+	// Storage class is only applicable to erasure coded setups.
+	if !globalIsXL {
+		writeErrorResponse(w, ErrNotImplemented, r.URL)
+		return
+	}
+
+	// Validate bucket name and check if it exists.
+	bucket := r.URL.Query().Get(string(mgmtBucket))
+	if err := checkBucketExist(bucket, objectAPI); err != nil {
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	scCfgBytes, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		errorIf(err, "Failed to read bucket storage class from request body.")
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	var scCfg bucketStorageClassConfig
+	if err = json.Unmarshal(scCfgBytes, &scCfg); err != nil {
+		writeErrorResponse(w, ErrInvalidStorageClass, r.URL)
+		return
+	}
+
+	if err = validateBucketStorageClassConfig(scCfg); err != nil {
+		writeErrorResponse(w, ErrInvalidStorageClass, r.URL)
+		return
+	}
+
+	// An empty config removes the bucket storage class config.
+	scCfgPtr := &scCfg
+	if scCfg.Standard.Scheme == "" && scCfg.RRS.Scheme == "" && scCfg.Default == "" && len(scCfg.Quota) == 0 && !scCfg.DowngradeProtection &&
+		len(scCfg.Transitions) == 0 {
+		scCfgPtr = nil
+	}
+
+	if err = persistAndNotifyBucketStorageClassChange(bucket, scCfgPtr, objectAPI); err != nil {
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	// Return 200 on success.
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// GetBucketStorageClassUsageHandler - GET /?storageclass&bucket=mybucket
+// - x-minio-operation = usage
+// - bucket is mandatory query parameter
+// Get objects and bytes stored in a given bucket per storage class,
+// computed by listing all the objects of the bucket.
+func (adminAPI adminAPIHandlers) GetBucketStorageClassUsageHandler(w http.ResponseWriter, r *http.Request) {
+	// Get current object layer instance.
+	objectAPI := newObjectLayerFn()
+	if objectAPI == nil {
+		writeErrorResponse(w, ErrServerNotInitialized, r.URL)
+		return
+	}
+
+	// Validate request signature.
+	adminAPIErr := checkRequestAuthType(r, "", "", "")
+	if adminAPIErr != ErrNone {
+		writeErrorResponse(w, adminAPIErr, r.URL)
+		return
+	}
+
+	// Storage class is only applicable to erasure coded setups.
+	if !globalIsXL {
+		writeErrorResponse(w, ErrNotImplemented, r.URL)
+		return
+	}
+
+	// Validate bucket name and check if it exists.
+	bucket := r.URL.Query().Get(string(mgmtBucket))
+	if err := checkBucketExist(bucket, objectAPI); err != nil {
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	usage, err := getBucketStorageClassUsage(bucket, objectAPI)
+	if err != nil {
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	jsonBytes, err := json.Marshal(usage)
+	if err != nil {
+		writeErrorResponse(w, ErrInternalError, r.URL)
+		errorIf(err, "Failed to marshal bucket storage class usage into json.")
+		return
+	}
+
+	writeSuccessResponseJSON(w, jsonBytes)
+}
+
+// EstimateStorageClassMigrationHandler - GET /?storageclass&bucket=mybucket&storage-class=REDUCED_REDUNDANCY
+// - x-minio-operation = estimate
+// - bucket and storage-class are mandatory query parameters
+// Estimate the space reclaimed and the objects re-encoded by migrating all
+// the objects of a given bucket to a storage class, no object is modified.
+func (adminAPI adminAPIHandlers) EstimateStorageClassMigrationHandler(w http.ResponseWriter, r *http.Request) {
+	// Get current object layer instance.
+	objectAPI := newObjectLayerFn()
+	if objectAPI == nil {
+		writeErrorResponse(w, ErrServerNotInitialized, r.URL)
+		return
+	}
+
+	// Validate request signature.
+	adminAPIErr := checkRequestAuthType(r, "", "", "")
+	if adminAPIErr != ErrNone {
+		writeErrorResponse(w, adminAPIErr, r.URL)
+		return
+	}
+
+	// Storage class is only applicable to erasure coded setups.
+	xl, ok := objectAPI.(*xlObjects)
+	if !ok {
+		writeErrorResponse(w, ErrNotImplemented, r.URL)
+		return
+	}
+
+	// Validate bucket name and check if it exists.
+	bucket := r.URL.Query().Get(string(mgmtBucket))
+	if err := checkBucketExist(bucket, objectAPI); err != nil {
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	targetClass := r.URL.Query().Get(string(mgmtStorageClass))
+	if !isValidStorageClassMeta(targetClass) {
+		writeErrorResponse(w, ErrInvalidStorageClass, r.URL)
+		return
+	}
+
+	estimate, err := xl.estimateStorageClassMigration(bucket, targetClass)
+	if err != nil {
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	jsonBytes, err := json.Marshal(estimate)
+	if err != nil {
+		writeErrorResponse(w, ErrInternalError, r.URL)
+		errorIf(err, "Failed to marshal storage class migration estimate into json.")
+		return
+	}
+
+	writeSuccessResponseJSON(w, jsonBytes)
+}
+
+// GetDurabilityReportHandler - GET /?storageclass&bucket=mybucket&prefix=myprefix&sample=100&max-objects=10000
+// - x-minio-operation = durability
+// - bucket, prefix, sample and max-objects are optional query parameters
+// Report the distribution of the parity objects are laid out with, read
+// from their xl.json, for a given bucket or all the buckets, optionally
+// only for the objects under prefix. Only every sample'th object listed
+// is examined, 1 by default, and the listing stops once max-objects
+// objects are examined if set, no object is modified.
+func (adminAPI adminAPIHandlers) GetDurabilityReportHandler(w http.ResponseWriter, r *http.Request) {
+	// Get current object layer instance.
+	objectAPI := newObjectLayerFn()
+	if objectAPI == nil {
+		writeErrorResponse(w, ErrServerNotInitialized, r.URL)
+		return
+	}
+
+	// Validate request signature.
+	adminAPIErr := checkRequestAuthType(r, "", "", "")
+	if adminAPIErr != ErrNone {
+		writeErrorResponse(w, adminAPIErr, r.URL)
+		return
+	}
+
+	// Storage class is only applicable to erasure coded setups.
+	xl, ok := objectAPI.(*xlObjects)
+	if !ok {
+		writeErrorResponse(w, ErrNotImplemented, r.URL)
+		return
+	}
+
+	// Validate bucket name and check if it exists, if set.
+	vars := r.URL.Query()
+	bucket := vars.Get(string(mgmtBucket))
+	if bucket != "" {
+		if err := checkBucketExist(bucket, objectAPI); err != nil {
+			writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+			return
+		}
+	}
+
+	// empty prefix is valid.
+	prefix := vars.Get(string(mgmtPrefix))
+	if !IsValidObjectPrefix(prefix) {
+		writeErrorResponse(w, ErrInvalidObjectName, r.URL)
+		return
+	}
+
+	sample := 1
+	if sampleStr := vars.Get(string(mgmtSample)); sampleStr != "" {
+		var err error
+		if sample, err = strconv.Atoi(sampleStr); err != nil || sample < 1 {
+			writeErrorResponse(w, ErrInvalidDurabilitySample, r.URL)
+			return
+		}
+	}
+	var maxObjects int
+	if maxObjectsStr := vars.Get(string(mgmtMaxObjects)); maxObjectsStr != "" {
+		var err error
+		if maxObjects, err = strconv.Atoi(maxObjectsStr); err != nil || maxObjects < 0 {
+			writeErrorResponse(w, ErrInvalidDurabilityMaxObjects, r.URL)
+			return
+		}
+	}
+
+	report, err := xl.getDurabilityReport(r.Context(), bucket, prefix, sample, maxObjects)
+	if err != nil {
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	jsonBytes, err := json.Marshal(report)
+	if err != nil {
+		writeErrorResponse(w, ErrInternalError, r.URL)
+		errorIf(err, "Failed to marshal durability report into json.")
+		return
+	}
+
+	writeSuccessResponseJSON(w, jsonBytes)
+}
+
+// NormalizeStorageClassesHandler - POST /?storageclass&bucket=mybucket&dry-run
+// - x-minio-operation = normalize
+// - bucket and dry-run are optional query parameters
+// Stamp Standard storage class in the xl.json of the objects of a given
+// bucket, or all the buckets, written without storage class. Objects are
+// not re-encoded, objects with a storage class are skipped. Objects which
+// would be stamped are only counted if dry-run is set.
+func (adminAPI adminAPIHandlers) NormalizeStorageClassesHandler(w http.ResponseWriter, r *http.Request) {
+	// Get current object layer instance.
+	objectAPI := newObjectLayerFn()
+	if objectAPI == nil {
+		writeErrorResponse(w, ErrServerNotInitialized, r.URL)
+		return
+	}
+
+	// Validate request signature.
+	adminAPIErr := checkRequestAuthType(r, "", "", "")
+	if adminAPIErr != ErrNone {
+		writeErrorResponse(w, adminAPIErr, r.URL)
+		return
+	}
+
+	// Storage class is only applicable to erasure coded setups.
+	xl, ok := objectAPI.(*xlObjects)
+	if !ok {
+		writeErrorResponse(w, ErrNotImplemented, r.URL)
+		return
+	}
+
+	// Validate bucket name and check if it exists, if set.
+	vars := r.URL.Query()
+	bucket := vars.Get(string(mgmtBucket))
+	if bucket != "" {
+		if err := checkBucketExist(bucket, objectAPI); err != nil {
+			writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+			return
+		}
+	}
+
+	result, err := xl.normalizeStorageClasses(r.Context(), bucket, isDryRun(vars))
+	if err != nil {
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		writeErrorResponse(w, ErrInternalError, r.URL)
+		errorIf(err, "Failed to marshal storage class normalization result into json.")
+		return
+	}
+
+	writeSuccessResponseJSON(w, jsonBytes)
+}
+
+// GetStorageClassInfoHandler - GET /?storageclass
+// - x-minio-operation = info
+// Get the effective data and parity disks of all storage classes
+// based on the current disk count and configured storage classes.
+func (adminAPI adminAPIHandlers) GetStorageClassInfoHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate request signature.
+	adminAPIErr := checkRequestAuthType(r, "", "", "")
+	if adminAPIErr != ErrNone {
+		writeErrorResponse(w, adminAPIErr, r.URL)
+		return
+	}
+
+	// Get object layer instance.
+	objLayer := newObjectLayerFn()
+	if objLayer == nil {
+		writeErrorResponse(w, ErrServerNotInitialized, r.URL)
+		return
+	}
+
+	// Warn about storage classes which can not be written
+	// with the disks currently online.
+	onlineDisks := objLayer.StorageInfo().Backend.OnlineDisks
+	scInfo := getStorageClassInfo()
+	scInfo.checkFeasibility(onlineDisks)
+
+	// Report storage classes which are no longer consistent
+	// with the disks currently online.
+	if err := checkStorageClassHealth(onlineDisks); err != nil {
+		scInfo.HealthError = err.Error()
+	}
+
+	jsonBytes, err := json.Marshal(scInfo)
+	if err != nil {
+		writeErrorResponse(w, ErrInternalError, r.URL)
+		errorIf(err, "Failed to marshal storage class info into json.")
+		return
+	}
+
+	writeSuccessResponseJSON(w, jsonBytes)
+}
@@ -73,4 +73,22 @@ func registerAdminRouter(mux *router.Router) {
 	adminRouter.Methods("GET").Queries("config", "").Headers(minioAdminOpHeader, "get").HandlerFunc(adminAPI.GetConfigHandler)
 	// Set Config
 	adminRouter.Methods("PUT").Queries("config", "").Headers(minioAdminOpHeader, "set").HandlerFunc(adminAPI.SetConfigHandler)
+
+	/// Storage class operations
+
+	// Get storage class info
+	adminRouter.Methods("GET").Queries("storageclass", "").Headers(minioAdminOpHeader, "info").HandlerFunc(adminAPI.GetStorageClassInfoHandler)
+
+	// Get bucket storage class
+	adminRouter.Methods("GET").Queries("storageclass", "").Headers(minioAdminOpHeader, "get").HandlerFunc(adminAPI.GetBucketStorageClassHandler)
+	// Set bucket storage class
+	adminRouter.Methods("PUT").Queries("storageclass", "").Headers(minioAdminOpHeader, "set").HandlerFunc(adminAPI.SetBucketStorageClassHandler)
+	// Get bucket storage class usage
+	adminRouter.Methods("GET").Queries("storageclass", "").Headers(minioAdminOpHeader, "usage").HandlerFunc(adminAPI.GetBucketStorageClassUsageHandler)
+	// Estimate bucket storage class migration
+	adminRouter.Methods("GET").Queries("storageclass", "").Headers(minioAdminOpHeader, "estimate").HandlerFunc(adminAPI.EstimateStorageClassMigrationHandler)
+	// Get durability report
+	adminRouter.Methods("GET").Queries("storageclass", "").Headers(minioAdminOpHeader, "durability").HandlerFunc(adminAPI.GetDurabilityReportHandler)
+	// Stamp objects without storage class with Standard storage class
+	adminRouter.Methods("POST").Queries("storageclass", "").Headers(minioAdminOpHeader, "normalize").HandlerFunc(adminAPI.NormalizeStorageClassesHandler)
 }
@@ -141,6 +141,10 @@ func (lc localAdminClient) ServerInfoData() (sid ServerInfoData, e error) {
 			SQSARN:   arns,
 			Region:   globalServerConfig.GetRegion(),
 		},
+		StorageClassStats: globalStorageClassStats.toServerStorageClassStats(),
+		QuorumMarginStats: globalQuorumMarginStats.toServerQuorumMarginStats(),
+
+		StorageClassConfig: getServerStorageClassConfig(),
 	}, nil
 }
 
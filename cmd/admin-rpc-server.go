@@ -158,6 +158,11 @@ func (s *adminCmd) ServerInfoData(args *AuthRPCArgs, reply *ServerInfoDataReply)
 		StorageInfo: storageInfo,
 		ConnStats:   globalConnStats.toServerConnStats(),
 		HTTPStats:   globalHTTPStats.toServerHTTPStats(),
+
+		StorageClassStats: globalStorageClassStats.toServerStorageClassStats(),
+		QuorumMarginStats: globalQuorumMarginStats.toServerQuorumMarginStats(),
+
+		StorageClassConfig: getServerStorageClassConfig(),
 	}
 
 	return nil
@@ -167,6 +167,14 @@ const (
 
 	// Minio storage class error codes
 	ErrInvalidStorageClass
+	ErrInvalidForceParity
+	ErrStorageClassMismatch
+	ErrStorageClassQuotaExceeded
+	ErrStorageClassDowngrade
+	ErrStorageClassNotSupported
+	ErrInvalidDryRunSize
+	ErrInvalidDurabilitySample
+	ErrInvalidDurabilityMaxObjects
 
 	// Add new extended error codes here.
 	// Please open a https://github.com/minio/minio/issues before adding
@@ -203,6 +211,46 @@ var errorCodeResponse = map[APIErrorCode]APIError{
 		Description:    "Invalid storage class.",
 		HTTPStatusCode: http.StatusBadRequest,
 	},
+	ErrInvalidForceParity: {
+		Code:           "InvalidForceParity",
+		Description:    "Force parity should be max or a parity between 2 and half of the total disks.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrStorageClassMismatch: {
+		Code:           "InvalidStorageClass",
+		Description:    "Storage class does not match the storage class the multipart upload was initiated with.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrStorageClassNotSupported: {
+		Code:           "InvalidStorageClass",
+		Description:    "Only STANDARD storage class is supported without erasure coding.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrStorageClassQuotaExceeded: {
+		Code:           "XMinioStorageClassQuotaExceeded",
+		Description:    "Bucket quota for the storage class has been exceeded.",
+		HTTPStatusCode: http.StatusForbidden,
+	},
+	ErrStorageClassDowngrade: {
+		Code:           "XMinioStorageClassDowngrade",
+		Description:    "Bucket does not allow overwriting the object with a lower durability storage class, set X-Minio-Force-Downgrade: true to overwrite it.",
+		HTTPStatusCode: http.StatusForbidden,
+	},
+	ErrInvalidDryRunSize: {
+		Code:           "InvalidArgument",
+		Description:    "Argument size must be an integer between 0 and the maximum object size.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidDurabilitySample: {
+		Code:           "InvalidArgument",
+		Description:    "Argument sample must be an integer between 1 and 2147483647",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidDurabilityMaxObjects: {
+		Code:           "InvalidArgument",
+		Description:    "Argument max-objects must be an integer between 0 and 2147483647",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
 	ErrInvalidRequestBody: {
 		Code:           "InvalidArgument",
 		Description:    "Body shouldn't be set for this request.",
@@ -780,6 +828,8 @@ func toAPIErrorCode(err error) (apiErr APIErrorCode) {
 		apiErr = ErrAdminInvalidAccessKey
 	case auth.ErrInvalidSecretKeyLength:
 		apiErr = ErrAdminInvalidSecretKey
+	case errInvalidForceParity:
+		apiErr = ErrInvalidForceParity
 	}
 
 	if apiErr != ErrNone {
@@ -841,7 +891,7 @@ func toAPIErrorCode(err error) (apiErr APIErrorCode) {
 		apiErr = ErrNoSuchUpload
 	case InvalidPart:
 		apiErr = ErrInvalidPart
-	case InsufficientWriteQuorum:
+	case InsufficientWriteQuorum, StorageClassWriteQuorum:
 		apiErr = ErrWriteQuorum
 	case InsufficientReadQuorum:
 		apiErr = ErrReadQuorum
@@ -875,6 +925,10 @@ func toAPIErrorCode(err error) (apiErr APIErrorCode) {
 		apiErr = ErrPartsSizeUnequal
 	case BucketPolicyNotFound:
 		apiErr = ErrNoSuchBucketPolicy
+	case StorageClassQuotaExceeded:
+		apiErr = ErrStorageClassQuotaExceeded
+	case StorageClassDowngrade:
+		apiErr = ErrStorageClassDowngrade
 	default:
 		apiErr = ErrInternalError
 	}
@@ -39,6 +39,8 @@ var toAPIErrorCodeTests = []struct {
 	{err: InvalidPart{}, errCode: ErrInvalidPart},
 	{err: InsufficientReadQuorum{}, errCode: ErrReadQuorum},
 	{err: InsufficientWriteQuorum{}, errCode: ErrWriteQuorum},
+	{err: StorageClassWriteQuorum{}, errCode: ErrWriteQuorum},
+	{err: StorageClassDowngrade{}, errCode: ErrStorageClassDowngrade},
 	{err: UnsupportedDelimiter{}, errCode: ErrNotImplemented},
 	{err: InvalidMarkerPrefixCombination{}, errCode: ErrNotImplemented},
 	{err: InvalidUploadIDKeyCombination{}, errCode: ErrNotImplemented},
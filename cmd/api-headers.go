@@ -83,6 +83,16 @@ func setObjectHeaders(w http.ResponseWriter, objInfo ObjectInfo, contentRange *h
 		w.Header().Set(k, v)
 	}
 
+	// Set the storage class the object is stored with, STANDARD is
+	// not returned same as AWS S3.
+	if objInfo.StorageClass != "" {
+		if sc := getStorageClassFromAlias(objInfo.StorageClass); sc != standardStorageClass {
+			w.Header().Set(amzStorageClass, sc)
+		} else {
+			w.Header().Del(amzStorageClass)
+		}
+	}
+
 	// for providing ranged content
 	if contentRange != nil && contentRange.offsetBegin > -1 {
 		// Override content-length
@@ -209,6 +209,17 @@ type CopyObjectResponse struct {
 	ETag         string   // md5sum of the copied object.
 }
 
+// PutObjectDryRunResponse container returns the erasure layout an object would be written with
+type PutObjectDryRunResponse struct {
+	XMLName      xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ PutObjectDryRunResult" json:"-"`
+	StorageClass string
+	Size         int64
+	DataBlocks   int
+	ParityBlocks int
+	ShardSize    int64 // Estimated bytes written to each disk.
+	WriteQuorum  int
+}
+
 // CopyObjectPartResponse container returns ETag and LastModified of the successfully copied object
 type CopyObjectPartResponse struct {
 	XMLName      xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ CopyPartResult" json:"-"`
@@ -337,7 +348,10 @@ func generateListObjectsV1Response(bucket, prefix, marker, delimiter string, max
 			content.ETag = "\"" + object.ETag + "\""
 		}
 		content.Size = object.Size
-		content.StorageClass = globalMinioDefaultStorageClass
+		content.StorageClass = object.StorageClass
+		if content.StorageClass == "" {
+			content.StorageClass = globalMinioDefaultStorageClass
+		}
 		content.Owner = owner
 		// object.HealObjectInfo is non-empty only when resp is constructed in ListObjectsHeal.
 		content.HealObjectInfo = object.HealObjectInfo
@@ -385,7 +399,10 @@ func generateListObjectsV2Response(bucket, prefix, token, nextToken, startAfter,
 			content.ETag = "\"" + object.ETag + "\""
 		}
 		content.Size = object.Size
-		content.StorageClass = globalMinioDefaultStorageClass
+		content.StorageClass = object.StorageClass
+		if content.StorageClass == "" {
+			content.StorageClass = globalMinioDefaultStorageClass
+		}
 		content.Owner = owner
 		contents = append(contents, content)
 	}
@@ -418,6 +435,18 @@ func generateCopyObjectResponse(etag string, lastModified time.Time) CopyObjectR
 	}
 }
 
+// generates PutObjectDryRunResponse from the planned object layout.
+func generatePutObjectDryRunResponse(layout objectLayout, size int64) PutObjectDryRunResponse {
+	return PutObjectDryRunResponse{
+		StorageClass: layout.StorageClass,
+		Size:         size,
+		DataBlocks:   layout.DataBlocks,
+		ParityBlocks: layout.ParityBlocks,
+		ShardSize:    layout.ShardSize,
+		WriteQuorum:  layout.WriteQuorum,
+	}
+}
+
 // generates CopyObjectPartResponse from etag and lastModified time.
 func generateCopyObjectPartResponse(etag string, lastModified time.Time) CopyObjectPartResponse {
 	return CopyObjectPartResponse{
@@ -452,7 +481,10 @@ func generateListPartsResponse(partsInfo ListPartsInfo) ListPartsResponse {
 	listPartsResponse.Bucket = partsInfo.Bucket
 	listPartsResponse.Key = partsInfo.Object
 	listPartsResponse.UploadID = partsInfo.UploadID
-	listPartsResponse.StorageClass = globalMinioDefaultStorageClass
+	listPartsResponse.StorageClass = partsInfo.StorageClass
+	if listPartsResponse.StorageClass == "" {
+		listPartsResponse.StorageClass = globalMinioDefaultStorageClass
+	}
 	listPartsResponse.Initiator.ID = globalMinioDefaultOwnerID
 	listPartsResponse.Owner.ID = globalMinioDefaultOwnerID
 
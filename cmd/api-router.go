@@ -59,6 +59,8 @@ func registerAPIRouter(mux *router.Router) {
 		bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(httpTraceHdrs(api.GetObjectHandler))
 		// CopyObject
 		bucket.Methods("PUT").Path("/{object:.+}").HeadersRegexp("X-Amz-Copy-Source", ".*?(\\/|%2F).*?").HandlerFunc(httpTraceAll(api.CopyObjectHandler))
+		// PutObjectDryRun
+		bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(httpTraceAll(api.PutObjectDryRunHandler)).Queries("dryRun", "true")
 		// PutObject
 		bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(httpTraceHdrs(api.PutObjectHandler))
 		// DeleteObject
@@ -719,6 +719,12 @@ func (api objectAPIHandlers) DeleteBucketHandler(w http.ResponseWriter, r *http.
 	// Notify all peers (including self) to update in-memory state
 	S3PeersUpdateBucketListener(bucket, []listenerConfig{})
 
+	// Delete storage class config, if present - ignore any errors.
+	_ = removeBucketStorageClassConfig(bucket, objectAPI)
+
+	// Notify all peers (including self) to update in-memory state
+	S3PeersUpdateBucketStorageClass(bucket, nil)
+
 	// Write success response.
 	writeSuccessNoContent(w)
 }
@@ -30,6 +30,9 @@ type BucketMetaState interface {
 	// Updates bucket policy
 	UpdateBucketPolicy(args *SetBucketPolicyPeerArgs) error
 
+	// Updates bucket storage class
+	UpdateBucketStorageClass(args *SetBucketStorageClassPeerArgs) error
+
 	// Sends event
 	SendEvent(args *EventArgs) error
 }
@@ -88,6 +91,23 @@ func (lc *localBucketMetaState) UpdateBucketPolicy(args *SetBucketPolicyPeerArgs
 	return globalBucketPolicies.SetBucketPolicy(args.Bucket, pCh)
 }
 
+// localBucketMetaState.UpdateBucketStorageClass - updates in-memory global
+// bucket storage class info.
+func (lc *localBucketMetaState) UpdateBucketStorageClass(args *SetBucketStorageClassPeerArgs) error {
+	// check if object layer is available.
+	objAPI := lc.ObjectAPI()
+	if objAPI == nil {
+		return errServerNotInitialized
+	}
+
+	if globalBucketStorageClass == nil {
+		return errServerNotInitialized
+	}
+
+	globalBucketStorageClass.SetBucketStorageClass(args.Bucket, args.SCCfg)
+	return nil
+}
+
 // localBucketMetaState.SendEvent - sends event to local event notifier via
 // `globalEventNotifier`
 func (lc *localBucketMetaState) SendEvent(args *EventArgs) error {
@@ -126,6 +146,13 @@ func (rc *remoteBucketMetaState) UpdateBucketPolicy(args *SetBucketPolicyPeerArg
 	return rc.Call("S3.SetBucketPolicyPeer", args, &reply)
 }
 
+// remoteBucketMetaState.UpdateBucketStorageClass - sends bucket storage
+// class change to remote peer via RPC call.
+func (rc *remoteBucketMetaState) UpdateBucketStorageClass(args *SetBucketStorageClassPeerArgs) error {
+	reply := AuthRPCReply{}
+	return rc.Call("S3.SetBucketStorageClassPeer", args, &reply)
+}
+
 // remoteBucketMetaState.SendEvent - sends event for bucket listener to remote
 // peer via RPC call.
 func (rc *remoteBucketMetaState) SendEvent(args *EventArgs) error {
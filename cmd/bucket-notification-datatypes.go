@@ -99,6 +99,8 @@ const (
 	ObjectAccessedGet
 	// ObjectAccessedHead is s3:ObjectAccessed:Head
 	ObjectAccessedHead
+	// ObjectTransitionStorageClass is s3:ObjectTransition:StorageClass
+	ObjectTransitionStorageClass
 )
 
 // Stringer interface for event name.
@@ -118,6 +120,8 @@ func (eventName EventName) String() string {
 		return "s3:ObjectAccessed:Get"
 	case ObjectAccessedHead:
 		return "s3:ObjectAccessed:Head"
+	case ObjectTransitionStorageClass:
+		return "s3:ObjectTransition:StorageClass"
 	default:
 		return "s3:Unknown"
 	}
@@ -143,7 +147,11 @@ type objectMeta struct {
 	ContentType  string            `json:"contentType,omitempty"`
 	UserMetadata map[string]string `json:"userMetadata,omitempty"`
 	VersionID    string            `json:"versionId,omitempty"`
-	Sequencer    string            `json:"sequencer"`
+	StorageClass string            `json:"storageClass,omitempty"`
+	// Storage class before the object was transitioned,
+	// only set for storage class transition events.
+	PreviousStorageClass string `json:"previousStorageClass,omitempty"`
+	Sequencer            string `json:"sequencer"`
 }
 
 const (
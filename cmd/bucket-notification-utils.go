@@ -37,6 +37,9 @@ var suppportedEventTypes = map[string]struct{}{
 	"s3:ObjectAccessed:Get":   {},
 	"s3:ObjectAccessed:Head":  {},
 	"s3:ObjectAccessed:*":     {},
+	// Object storage class transition event types.
+	"s3:ObjectTransition:StorageClass": {},
+	"s3:ObjectTransition:*":            {},
 }
 
 // checkEvent - checks if an event is supported.
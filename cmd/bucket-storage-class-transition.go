@@ -0,0 +1,181 @@
+/*
+ * Minio Cloud Storage, (C) 2017 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/minio/minio/pkg/errors"
+)
+
+const (
+	// Interval at which the transition rules of all the buckets are applied.
+	storageClassTransitionInterval = time.Hour * 24 // 24 hrs.
+)
+
+// storageClassTransition - bucket lifecycle rule transitioning objects
+// older than Days to the storage class.
+type storageClassTransition struct {
+	Days         int    `json:"days"`
+	StorageClass string `json:"storageClass"`
+}
+
+// Returns the storage class an object of the given age is transitioned to,
+// i.e. the storage class of the rule with the most days not exceeding the
+// age. Returns an empty string if no rule applies yet.
+func getStorageClassTransition(transitions []storageClassTransition, age time.Duration) string {
+	var sc string
+	days := 0
+	for _, t := range transitions {
+		if t.Days > days && time.Duration(t.Days)*24*time.Hour <= age {
+			sc, days = getStorageClassFromAlias(t.StorageClass), t.Days
+		}
+	}
+	return sc
+}
+
+// Logs and notifies the lifecycle event of an object transitioned from
+// one storage class to another by a transition rule of its bucket. The
+// event is sent to the bucket notification targets listening for
+// s3:ObjectTransition:StorageClass, with the storage class before the
+// transition in previousStorageClass.
+func notifyStorageClassTransition(bucket string, objInfo ObjectInfo, from, to string) {
+	log.logger.WithFields(logrus.Fields{
+		"lifecycle": "storageClassTransition",
+		"bucket":    bucket,
+		"object":    objInfo.Name,
+		"fromClass": from,
+		"toClass":   to,
+	}).Info("Object transitioned to storage class")
+
+	eventNotify(eventData{
+		Type:             ObjectTransitionStorageClass,
+		Bucket:           bucket,
+		ObjInfo:          objInfo,
+		PrevStorageClass: from,
+	})
+}
+
+// Transitions an object to the target storage class by copying the object
+// onto itself with the target storage class, the object is re-encoded only
+// if the parity of the target storage class differs. Returns false if the
+// object is already in the target storage class. Downgrade protection of the
+// bucket applies, a transition to fewer parity disks returns
+// StorageClassDowngrade.
+func transitionObjectStorageClass(objAPI ObjectLayer, bucket, object, targetClass string) (objInfo ObjectInfo, transitioned bool, err error) {
+	objectLock := globalNSMutex.NewNSLock(bucket, object)
+	if err = objectLock.GetLock(globalObjectTimeout); err != nil {
+		return objInfo, false, err
+	}
+	defer objectLock.Unlock()
+
+	objInfo, err = objAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		return objInfo, false, err
+	}
+	targetClass = getStorageClassFromAlias(targetClass)
+	fromClass := getStorageClassFromAlias(getObjectStorageClass(objInfo.UserDefined))
+	if fromClass == targetClass {
+		return objInfo, false, nil
+	}
+
+	metadata := make(map[string]string, len(objInfo.UserDefined)+1)
+	for k, v := range objInfo.UserDefined {
+		metadata[k] = v
+	}
+	setCopyStorageClass(nil, metadata, targetClass)
+	if objInfo, err = objAPI.CopyObject(bucket, object, bucket, object, metadata); err != nil {
+		return objInfo, false, err
+	}
+	notifyStorageClassTransition(bucket, objInfo, fromClass, targetClass)
+	return objInfo, true, nil
+}
+
+// Applies the transition rules of the bucket to all its objects, objects
+// are aged from their last modification. Objects which can't be
+// transitioned due to downgrade protection are skipped. Returns the number
+// of objects transitioned.
+func transitionBucketStorageClasses(bucket string, objAPI ObjectLayer, now time.Time) (transitioned int, err error) {
+	scCfg, ok := globalBucketStorageClass.GetBucketStorageClass(bucket)
+	if !ok || len(scCfg.Transitions) == 0 {
+		return 0, nil
+	}
+	marker := ""
+	for {
+		result, err := objAPI.ListObjects(bucket, "", marker, "", maxObjectList)
+		if err != nil {
+			return transitioned, errors.Cause(err)
+		}
+		for _, objInfo := range result.Objects {
+			sc := getStorageClassTransition(scCfg.Transitions, now.Sub(objInfo.ModTime))
+			if sc == "" {
+				continue
+			}
+			_, ok, err := transitionObjectStorageClass(objAPI, bucket, objInfo.Name, sc)
+			if err != nil {
+				if _, isDowngrade := errors.Cause(err).(StorageClassDowngrade); !isDowngrade {
+					errorIf(err, "Unable to transition %s/%s to %s storage class.", bucket, objInfo.Name, sc)
+				}
+				continue
+			}
+			if ok {
+				transitioned++
+			}
+		}
+		if !result.IsTruncated {
+			return transitioned, nil
+		}
+		marker = result.NextMarker
+	}
+}
+
+// Applies the transition rules of all the buckets for every `interval`,
+// this function is blocking and should be run in a go-routine. Every run
+// lists all the objects of the buckets with transition rules, so it is only
+// started on one server of a distributed setup, see isStorageClassTransitionServer.
+// Objects are locked while transitioned, objects already transitioned are skipped.
+func transitionStorageClasses(interval time.Duration, objAPI ObjectLayer, doneCh chan struct{}) {
+	ticker := time.NewTicker(interval)
+	for {
+		select {
+		case <-doneCh:
+			// Stop the timer.
+			ticker.Stop()
+			return
+		case <-ticker.C:
+			bucketInfos, err := objAPI.ListBuckets()
+			if err != nil {
+				errorIf(err, "Unable to list buckets")
+				continue
+			}
+			for _, bucketInfo := range bucketInfos {
+				if _, err = transitionBucketStorageClasses(bucketInfo.Name, objAPI, UTCNow()); err != nil {
+					errorIf(err, "Unable to transition storage class of objects in %s.", bucketInfo.Name)
+				}
+			}
+		}
+	}
+}
+
+// Returns true if this server applies the storage class transition rules,
+// i.e. the server of the first endpoint, or any server of a setup without
+// endpoints. Running the rules on a single server avoids listing all the
+// objects once per server.
+func isStorageClassTransitionServer(endpoints EndpointList) bool {
+	return len(endpoints) == 0 || endpoints[0].IsLocal
+}
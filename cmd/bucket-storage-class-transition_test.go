@@ -0,0 +1,178 @@
+/*
+ * Minio Cloud Storage, (C) 2017 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/pkg/errors"
+)
+
+func TestGetStorageClassTransition(t *testing.T) {
+	day := 24 * time.Hour
+	transitions := []storageClassTransition{
+		{Days: 90, StorageClass: scratchStorageClass},
+		{Days: 30, StorageClass: "reduced_redundancy"},
+	}
+	tests := []struct {
+		name          int
+		transitions   []storageClassTransition
+		age           time.Duration
+		expectedClass string
+	}{
+		{1, nil, 100 * day, ""},
+		{2, transitions, 29 * day, ""},
+		// Storage classes are matched case-insensitively.
+		{3, transitions, 30 * day, reducedRedundancyStorageClass},
+		{4, transitions, 89 * day, reducedRedundancyStorageClass},
+		// Rule with the most days applies, irrespective of the order.
+		{5, transitions, 90 * day, scratchStorageClass},
+		{6, transitions, 365 * day, scratchStorageClass},
+	}
+	for _, tt := range tests {
+		if sc := getStorageClassTransition(tt.transitions, tt.age); sc != tt.expectedClass {
+			t.Errorf("Test %d, Expected %s, got %s", tt.name, tt.expectedClass, sc)
+		}
+	}
+}
+
+func TestTransitionObjectStorageClass(t *testing.T) {
+	// initialize NSLock, objects are locked while transitioned.
+	initNSLock(false)
+	ExecObjectLayerTestWithDirs(t, testTransitionObjectStorageClass)
+}
+
+func testTransitionObjectStorageClass(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+	globalEndpoints = mustGetNewEndpointList(dirs...)
+	defer resetGlobalEndpoints()
+
+	bucket := getRandomBucketName()
+	if err := obj.MakeBucketWithLocation(bucket, globalMinioDefaultRegion); err != nil {
+		t.Fatalf("Failed to make a bucket %v", err)
+	}
+	if err := initBucketStorageClass(obj); err != nil {
+		t.Fatalf("Failed to load bucket storage class %v", err)
+	}
+
+	data := bytes.Repeat([]byte("a"), 1024)
+	for _, object := range []string{"old", "new"} {
+		if _, err := obj.PutObject(bucket, object, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), nil); err != nil {
+			t.Fatalf("Failed to put object %s %v", object, err)
+		}
+	}
+
+	// Transition rules must have days and a valid storage class.
+	if err := validateBucketStorageClassConfig(bucketStorageClassConfig{Transitions: []storageClassTransition{{Days: 0, StorageClass: reducedRedundancyStorageClass}}}); err == nil {
+		t.Errorf("Expected transition without days to be rejected")
+	}
+	if err := validateBucketStorageClassConfig(bucketStorageClassConfig{Transitions: []storageClassTransition{{Days: 30, StorageClass: "GLACIER"}}}); err == nil {
+		t.Errorf("Expected transition to an unsupported storage class to be rejected")
+	}
+	scCfg := bucketStorageClassConfig{Transitions: []storageClassTransition{{Days: 30, StorageClass: reducedRedundancyStorageClass}}}
+	if err := validateBucketStorageClassConfig(scCfg); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	globalBucketStorageClass.SetBucketStorageClass(bucket, &scCfg)
+	defer globalBucketStorageClass.SetBucketStorageClass(bucket, nil)
+
+	// Only objects older than 30 days are transitioned, "new" is aged 20 days.
+	oldInfo, err := obj.GetObjectInfo(bucket, "old")
+	if err != nil {
+		t.Fatalf("Failed to get object info %v", err)
+	}
+	transitioned, err := transitionBucketStorageClasses(bucket, obj, oldInfo.ModTime.Add(20*24*time.Hour))
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if transitioned != 0 {
+		t.Errorf("Expected no object to be transitioned, got %d", transitioned)
+	}
+	if _, _, err = transitionObjectStorageClass(obj, bucket, "old", reducedRedundancyStorageClass); err != nil {
+		t.Fatalf("Failed to transition object %v", err)
+	}
+	objInfo, err := obj.GetObjectInfo(bucket, "old")
+	if err != nil {
+		t.Fatalf("Failed to get object info %v", err)
+	}
+	if sc := getObjectStorageClass(objInfo.UserDefined); sc != reducedRedundancyStorageClass {
+		t.Errorf("Expected storage class %s, got %s", reducedRedundancyStorageClass, sc)
+	}
+	if objInfo.ETag != oldInfo.ETag {
+		t.Errorf("Expected ETag %s, got %s", oldInfo.ETag, objInfo.ETag)
+	}
+	var buffer bytes.Buffer
+	if err = obj.GetObject(bucket, "old", 0, int64(len(data)), &buffer); err != nil {
+		t.Fatalf("Failed to get object %v", err)
+	}
+	if !bytes.Equal(buffer.Bytes(), data) {
+		t.Errorf("Expected transitioned object data to be unchanged")
+	}
+
+	// Transition is idempotent.
+	if _, ok, err := transitionObjectStorageClass(obj, bucket, "old", "reduced_redundancy"); err != nil || ok {
+		t.Errorf("Expected object already in the storage class to be skipped, got %v, %v", ok, err)
+	}
+
+	// Objects older than the rule are transitioned.
+	transitioned, err = transitionBucketStorageClasses(bucket, obj, UTCNow().Add(31*24*time.Hour))
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if transitioned != 1 {
+		t.Errorf("Expected 1 object to be transitioned, got %d", transitioned)
+	}
+
+	// Downgrade protection applies to the objects written with more parity.
+	if instanceType == XLTestStr {
+		protected := scCfg
+		protected.DowngradeProtection = true
+		globalBucketStorageClass.SetBucketStorageClass(bucket, &protected)
+		if _, err = obj.PutObject(bucket, "protected", mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), nil); err != nil {
+			t.Fatalf("Failed to put object %v", err)
+		}
+		_, _, err = transitionObjectStorageClass(obj, bucket, "protected", reducedRedundancyStorageClass)
+		if _, ok := errors.Cause(err).(StorageClassDowngrade); !ok {
+			t.Errorf("Expected StorageClassDowngrade, got %v", err)
+		}
+		if transitioned, err = transitionBucketStorageClasses(bucket, obj, UTCNow().Add(31*24*time.Hour)); err != nil || transitioned != 0 {
+			t.Errorf("Expected protected object to be skipped, got %d, %v", transitioned, err)
+		}
+	}
+}
+
+// Tests that storage class transitions are applied by a single server.
+func TestIsStorageClassTransitionServer(t *testing.T) {
+	testCases := []struct {
+		name      int
+		endpoints EndpointList
+		expected  bool
+	}{
+		{1, nil, true},
+		{2, EndpointList{{IsLocal: true}, {IsLocal: false}}, true},
+		{3, EndpointList{{IsLocal: false}, {IsLocal: true}}, false},
+	}
+	for _, testCase := range testCases {
+		if ok := isStorageClassTransitionServer(testCase.endpoints); ok != testCase.expected {
+			t.Errorf("Test %d, Expected %v, got %v", testCase.name, testCase.expected, ok)
+		}
+	}
+}
@@ -0,0 +1,702 @@
+/*
+ * Minio Cloud Storage, (C) 2017 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/pkg/errors"
+	"github.com/minio/minio/pkg/hash"
+	"go.uber.org/atomic"
+)
+
+const (
+	// Bucket storage class config name.
+	bucketStorageClassConfigFile = "storageclass.json"
+)
+
+// Variable represents bucket storage class configs in memory.
+var globalBucketStorageClass *bucketStorageClasses
+
+// bucketStorageClassConfig - per bucket storage class configuration,
+// a class left unset falls back to the server wide storage class.
+type bucketStorageClassConfig struct {
+	Standard storageClass `json:"standard"`
+	RRS      storageClass `json:"rrs"`
+	// Storage class of objects written to the bucket without a storage class.
+	Default string `json:"default,omitempty"`
+	// Reject overwriting objects with fewer parity disks than they
+	// currently have, unless the downgrade is forced.
+	DowngradeProtection bool `json:"downgradeProtection,omitempty"`
+	// Maximum bytes written to the bucket per storage class,
+	// storage classes without quota are not limited.
+	Quota map[string]uint64 `json:"quota,omitempty"`
+	// Lifecycle rules transitioning objects to another storage class
+	// once they are older than the days of the rule.
+	Transitions []storageClassTransition `json:"transitions,omitempty"`
+}
+
+// Global bucket storage class configs, looked up on every write
+// to decide the erasure layout of the object.
+type bucketStorageClasses struct {
+	rwMutex *sync.RWMutex
+
+	// Collection of 'bucket' storage class configs.
+	bucketStorageClassConfigs map[string]bucketStorageClassConfig
+}
+
+// Fetch storage class config for a given bucket.
+func (bs *bucketStorageClasses) GetBucketStorageClass(bucket string) (bucketStorageClassConfig, bool) {
+	if bs == nil {
+		return bucketStorageClassConfig{}, false
+	}
+	bs.rwMutex.RLock()
+	defer bs.rwMutex.RUnlock()
+	scCfg, ok := bs.bucketStorageClassConfigs[bucket]
+	return scCfg, ok
+}
+
+// Set a new storage class config for a bucket, a nil config
+// removes any previous config of the bucket.
+func (bs *bucketStorageClasses) SetBucketStorageClass(bucket string, scCfg *bucketStorageClassConfig) {
+	bs.rwMutex.Lock()
+	if scCfg == nil {
+		delete(bs.bucketStorageClassConfigs, bucket)
+	} else {
+		bs.bucketStorageClassConfigs[bucket] = *scCfg
+	}
+	bs.rwMutex.Unlock()
+
+	// Bytes stored don't depend on the quota, they are only dropped
+	// once the bucket has no quota left.
+	if scCfg == nil || len(scCfg.Quota) == 0 {
+		globalBucketQuotaUsage.Remove(bucket)
+	}
+	// Data and parity drive counts resolved from the previous config are stale.
+	globalRedundancyCache.Invalidate()
+}
+
+// Returns the effective storage class for a given bucket and storage
+// class name. Bucket level storage class takes precedence over the
+// server wide storage class set via environment or config.json.
+func getBucketStorageClass(bucket, sc string) storageClass {
+	scCfg, ok := globalBucketStorageClass.GetBucketStorageClass(bucket)
+	ssc, rrsc, _ := getStorageClassGlobals()
+	switch sc {
+	case reducedRedundancyStorageClass:
+		if ok && scCfg.RRS.Scheme != "" {
+			// Relative parity is resolved against the Standard storage class of the bucket.
+			return resolveRelativeParity(scCfg.RRS, getBucketStorageClass(bucket, standardStorageClass), len(globalEndpoints))
+		}
+		return rrsc
+	case standardStorageClass:
+		if ok && scCfg.Standard.Scheme != "" {
+			return scCfg.Standard
+		}
+		return ssc
+	}
+	return storageClass{}
+}
+
+// Returns the storage class an object written to a bucket with the storage
+// class reqClass from the request is stored with, along with the source of
+// the storage class, one of storageClassSource*. Storage class in the request
+// takes precedence over the default storage class of the bucket, then over
+// the first bucket storage class rule matching the bucket and then over the
+// server default. The server default is an empty storage class, i.e. the
+// default parity is used and the object is Standard storage class. A storage
+// class which is not valid falls back to the server default.
+func resolveStorageClass(bucket, reqClass string) (sc, source string) {
+	if reqClass != "" {
+		sc, source = getStorageClassFromAlias(reqClass), storageClassSourceRequest
+	} else if scCfg, ok := globalBucketStorageClass.GetBucketStorageClass(bucket); ok && scCfg.Default != "" {
+		sc, source = getStorageClassFromAlias(scCfg.Default), storageClassSourceBucket
+	} else if ruleClass := matchBucketStorageClassRules(globalBucketStorageClassRules, bucket); ruleClass != "" {
+		sc, source = getStorageClassFromAlias(ruleClass), storageClassSourceRule
+	}
+	if sc == "" || !isValidStorageClassMeta(sc) {
+		return "", storageClassSourceDefault
+	}
+	// A bucket default saved before Reduced redundancy was denied as a default is ignored.
+	if source != storageClassSourceRequest && checkDefaultStorageClass(sc) != nil {
+		return "", storageClassSourceDefault
+	}
+	return sc, source
+}
+
+// Returns an error if sc, or the storage class it is an alias of, is Reduced
+// redundancy storage class and MINIO_STORAGE_CLASS_DENY_RRS_DEFAULT is on.
+// Reduced redundancy is then only accepted as a storage class requested
+// explicitly for an object, not as a bucket default or bucket storage class rule.
+func checkDefaultStorageClass(sc string) error {
+	if globalStorageClassDenyRRSDefault && getStorageClassFromAlias(sc) == reducedRedundancyStorageClass {
+		return fmt.Errorf("Reduced redundancy storage class can not be a default storage class, %s is on", storageClassDenyRRSDefaultEnv)
+	}
+	return nil
+}
+
+// Sets the default storage class of the bucket, from the bucket config or
+// a bucket storage class rule, in the metadata of an object to be written,
+// if the metadata has no storage class. Storage class set in the metadata
+// is left as is, as it is validated with the request.
+func setBucketDefaultStorageClass(bucket string, metadata map[string]string) {
+	if sc, source := resolveStorageClass(bucket, metadata[amzStorageClass]); source == storageClassSourceBucket || source == storageClassSourceRule {
+		metadata[amzStorageClass] = sc
+	}
+}
+
+// Validates the bucket storage class config, a class unset at the
+// bucket level is validated against the server wide storage class.
+func validateBucketStorageClassConfig(scCfg bucketStorageClassConfig) error {
+	ssScheme, ssParity := globalStandardStorageClass.Scheme, globalStandardStorageClass.Parity
+	if scCfg.Standard.Scheme != "" {
+		ssScheme, ssParity = scCfg.Standard.Scheme, scCfg.Standard.Parity
+	}
+	rrsScheme, rrsParity := globalRRStorageClass.Scheme, globalRRStorageClass.Parity
+	if scCfg.RRS.Scheme != "" {
+		rrsc := resolveRelativeParity(scCfg.RRS, storageClass{Scheme: ssScheme, Parity: ssParity}, len(globalEndpoints))
+		rrsScheme, rrsParity = rrsc.Scheme, rrsc.Parity
+	}
+	if err := checkRelativeStorageClasses(scCfg.Standard, storageClass{}); err != nil {
+		return err
+	}
+	for _, sc := range []storageClass{scCfg.Standard, scCfg.RRS} {
+		if err := checkStorageClassDisks(sc, len(globalEndpoints)); err != nil {
+			return err
+		}
+	}
+
+	if err := validateStorageClassSchemes(ssScheme, rrsScheme); err != nil {
+		return err
+	}
+
+	if scCfg.RRS.Scheme != "" {
+		if err := validateRRSParity(rrsParity, ssParity); err != nil {
+			return err
+		}
+	}
+	if scCfg.Standard.Scheme != "" {
+		if err := validateSSParity(ssParity, rrsParity); err != nil {
+			return err
+		}
+	}
+	if scCfg.Default != "" && !isValidStorageClassMeta(scCfg.Default) {
+		return fmt.Errorf("Unsupported default storage class %s", scCfg.Default)
+	}
+	if err := checkDefaultStorageClass(scCfg.Default); err != nil {
+		return err
+	}
+	for sc := range scCfg.Quota {
+		if !isSupportedStorageClass(sc) {
+			return fmt.Errorf("Unsupported storage class %s for quota", sc)
+		}
+	}
+	for _, t := range scCfg.Transitions {
+		if t.Days <= 0 {
+			return fmt.Errorf("Transition days should be greater than 0, found %d", t.Days)
+		}
+		if !isValidStorageClassMeta(t.StorageClass) {
+			return fmt.Errorf("Unsupported storage class %s for transition", t.StorageClass)
+		}
+	}
+	return nil
+}
+
+// Returns true if the bucket has a quota for any storage class.
+func hasBucketStorageClassQuota(bucket string) bool {
+	scCfg, ok := globalBucketStorageClass.GetBucketStorageClass(bucket)
+	return ok && len(scCfg.Quota) > 0
+}
+
+// Returns StorageClassQuotaExceeded if writing size bytes to the bucket
+// with the storage class exceeds the bucket quota of the storage class.
+// oldObj is the object overwritten by the write, if any, its bytes are
+// freed by the write. Bytes stored in the bucket are taken from the
+// objects of the bucket, see bucketQuotaUsage.
+func checkBucketStorageClassQuota(objAPI ObjectLayer, bucket, sc string, size int64, oldObj ObjectInfo) error {
+	scCfg, ok := globalBucketStorageClass.GetBucketStorageClass(bucket)
+	if !ok {
+		return nil
+	}
+	quota, ok := scCfg.Quota[sc]
+	if !ok || size <= 0 {
+		return nil
+	}
+	used := globalBucketQuotaUsage.Get(bucket, sc, objAPI)
+	if oldObj.Size > 0 && quotaStorageClass(oldObj.StorageClass) == sc {
+		if uint64(oldObj.Size) >= used {
+			used = 0
+		} else {
+			used -= uint64(oldObj.Size)
+		}
+	}
+	if used+uint64(size) > quota {
+		return StorageClassQuotaExceeded{Bucket: bucket, StorageClass: sc, Quota: quota}
+	}
+	return nil
+}
+
+// Interval after which the bytes stored in a bucket per storage class are
+// computed again from the objects of the bucket, so that objects written
+// through other servers are accounted against the quota.
+var bucketQuotaUsageRefreshInterval = 5 * time.Minute
+
+// Variable holds the bytes stored per bucket and storage class, looked
+// up on writes to buckets with a storage class quota.
+var globalBucketQuotaUsage = newBucketQuotaUsage()
+
+// Objects and bytes written and freed per storage class while a bucket
+// is listed, merged into the listed objects and bytes once it is done.
+type bucketQuotaUsageDelta struct {
+	objects int64
+	bytes   int64
+}
+
+// Objects and bytes stored in a bucket per storage class, along with
+// the time they were last computed from the objects of the bucket.
+type bucketQuotaUsageEntry struct {
+	// Accounted through the per storage class counters, as listed
+	// plus the writes and deletes of this server since.
+	usage    *StorageClassStats
+	computed time.Time
+	// Set while the bucket is listed in the background.
+	refreshing bool
+	deltas     map[string]bucketQuotaUsageDelta
+}
+
+// bucketQuotaUsage - bytes stored per bucket and storage class. The bytes of
+// a bucket are computed by listing its objects in the background on the first
+// quota check and again every bucketQuotaUsageRefreshInterval, only one
+// listing runs per bucket at a time. Quota checks are served the last bytes
+// computed meanwhile, the writes and deletes of this server are accounted
+// in between. Only buckets with a quota are listed.
+type bucketQuotaUsage struct {
+	mutex   *sync.Mutex
+	buckets map[string]*bucketQuotaUsageEntry
+}
+
+func newBucketQuotaUsage() *bucketQuotaUsage {
+	return &bucketQuotaUsage{
+		mutex:   &sync.Mutex{},
+		buckets: make(map[string]*bucketQuotaUsageEntry),
+	}
+}
+
+// Returns the storage class the bytes of an object with storage
+// class sc are accounted by, objects without storage class are
+// Standard storage class.
+func quotaStorageClass(sc string) string {
+	if sc == "" {
+		return standardStorageClass
+	}
+	return sc
+}
+
+// Returns the entry of a bucket, added if missing. If start is set and the
+// bucket is not being listed, the entry is marked as being listed and true
+// is returned, the caller lists the bucket. Must be called with the mutex held.
+func (u *bucketQuotaUsage) getEntry(bucket string, start bool) (*bucketQuotaUsageEntry, bool) {
+	entry, ok := u.buckets[bucket]
+	if !ok {
+		entry = &bucketQuotaUsageEntry{usage: newStorageClassStats()}
+		u.buckets[bucket] = entry
+	}
+	if !start || entry.refreshing {
+		return entry, false
+	}
+	entry.refreshing = true
+	entry.deltas = make(map[string]bucketQuotaUsageDelta)
+	return entry, true
+}
+
+// Returns the bytes stored in a bucket with the storage class sc. The
+// objects of the bucket are listed in the background if the bytes were
+// not computed yet or were computed more than bucketQuotaUsageRefreshInterval
+// ago, the bytes computed last are returned without waiting for it. Until
+// the bucket is listed once only the writes of this server are accounted.
+func (u *bucketQuotaUsage) Get(bucket, sc string, objAPI ObjectLayer) uint64 {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	entry := u.buckets[bucket]
+	stale := entry == nil || UTCNow().Sub(entry.computed) >= bucketQuotaUsageRefreshInterval
+	entry, start := u.getEntry(bucket, stale)
+	if start {
+		go u.list(bucket, entry, objAPI)
+	}
+	return entry.usage.getCounter(quotaStorageClass(sc)).Bytes.Load()
+}
+
+// Lists the objects of a bucket in the background and replaces the bytes of
+// the entry with the listed bytes plus the deltas accounted while listing.
+// Nothing is replaced if the listing fails, or if the entry was dropped
+// meanwhile, e.g. the bucket was deleted.
+func (u *bucketQuotaUsage) list(bucket string, entry *bucketQuotaUsageEntry, objAPI ObjectLayer) {
+	bucketUsage, err := getBucketStorageClassUsage(bucket, objAPI)
+
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	deltas := entry.deltas
+	entry.refreshing, entry.deltas = false, nil
+	if err != nil {
+		errorIf(err, "Unable to compute the bytes stored in bucket %s.", bucket)
+		return
+	}
+	if u.buckets[bucket] != entry {
+		return
+	}
+
+	usage := newStorageClassStats()
+	for class, counter := range bucketUsage.Usage {
+		usage.getCounter(class).Objects.Store(counter.Objects)
+		usage.getCounter(class).Bytes.Store(counter.Bytes)
+	}
+	for class, delta := range deltas {
+		addCounter(&usage.getCounter(class).Objects, delta.objects)
+		addCounter(&usage.getCounter(class).Bytes, delta.bytes)
+	}
+	entry.usage, entry.computed = usage, UTCNow()
+}
+
+// Adds n to the counter, a negative n is decremented
+// and the counter doesn't go below zero.
+func addCounter(counter *atomic.Uint64, n int64) {
+	if n >= 0 {
+		counter.Add(uint64(n))
+	} else {
+		decCounter(counter, uint64(-n))
+	}
+}
+
+// Accounts size bytes written to a bucket with the storage class sc, a
+// negative size accounts bytes freed, both by a single object. Nothing is
+// accounted for buckets whose bytes are not looked up, i.e. without quota.
+func (u *bucketQuotaUsage) Add(bucket, sc string, size int64) {
+	if size == 0 {
+		return
+	}
+	sc = quotaStorageClass(sc)
+
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	entry, ok := u.buckets[bucket]
+	if !ok {
+		return
+	}
+	objects := int64(1)
+	if size > 0 {
+		entry.usage.updateStats(sc, size)
+	} else {
+		entry.usage.overwriteStats(sc, -size)
+		objects = -1
+	}
+	if entry.refreshing {
+		delta := entry.deltas[sc]
+		delta.objects += objects
+		delta.bytes += size
+		entry.deltas[sc] = delta
+	}
+}
+
+// Drops the bytes stored in a bucket, e.g. once the bucket is deleted
+// or its quota removed, they are computed again on the next quota check.
+func (u *bucketQuotaUsage) Remove(bucket string) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	delete(u.buckets, bucket)
+}
+
+// Lists the objects of a bucket and replaces its bytes stored unless the
+// bucket is already being listed, e.g. when the bucket storage class
+// configs are loaded at startup.
+func (u *bucketQuotaUsage) refresh(bucket string, objAPI ObjectLayer) {
+	u.mutex.Lock()
+	entry, start := u.getEntry(bucket, true)
+	u.mutex.Unlock()
+	if start {
+		u.list(bucket, entry, objAPI)
+	}
+}
+
+// Returns true if objects in the bucket can't be overwritten with
+// fewer parity disks unless the downgrade is forced.
+func isBucketDowngradeProtected(bucket string) bool {
+	scCfg, ok := globalBucketStorageClass.GetBucketStorageClass(bucket)
+	return ok && scCfg.DowngradeProtection
+}
+
+// bucketStorageClassUsage - objects and bytes stored in a bucket per storage class.
+type bucketStorageClassUsage struct {
+	Bucket string                               `json:"bucket"`
+	Usage  map[string]ServerStorageClassCounter `json:"usage"`
+}
+
+// Returns the objects and bytes stored in a bucket per storage class by
+// listing all the objects of the bucket. Unlike the storage class stats,
+// which count writes since the server started, the usage accounts objects
+// currently in the bucket. Storage class is taken from the object info,
+// which is read from xl.json while listing, objects without storage class
+// are accounted as Standard storage class.
+func getBucketStorageClassUsage(bucket string, objAPI ObjectLayer) (bucketStorageClassUsage, error) {
+	usage := bucketStorageClassUsage{
+		Bucket: bucket,
+		Usage:  make(map[string]ServerStorageClassCounter),
+	}
+	marker := ""
+	for {
+		result, err := objAPI.ListObjects(bucket, "", marker, "", maxObjectList)
+		if err != nil {
+			return usage, errors.Cause(err)
+		}
+		for _, objInfo := range result.Objects {
+			sc := objInfo.StorageClass
+			if sc == "" {
+				sc = standardStorageClass
+			}
+			counter := usage.Usage[sc]
+			counter.Objects++
+			counter.Bytes += uint64(objInfo.Size)
+			usage.Usage[sc] = counter
+		}
+		if !result.IsTruncated {
+			return usage, nil
+		}
+		marker = result.NextMarker
+	}
+}
+
+// storageClassMigrationEstimate - space reclaimed by migrating the objects
+// of a bucket to a target storage class, negative if more space is needed.
+type storageClassMigrationEstimate struct {
+	Bucket       string `json:"bucket"`
+	TargetClass  string `json:"targetClass"`
+	Objects      uint64 `json:"objects"`
+	CurrentBytes int64  `json:"currentBytes"`
+	TargetBytes  int64  `json:"targetBytes"`
+	SavedBytes   int64  `json:"savedBytes"`
+}
+
+// Returns the data disks objects in a bucket with the storage class sc are
+// laid out with on totalDisks. Parity is capped at N/2 of totalDisks, as
+// parity configured for more disks than are left can't be laid out.
+func bucketErasureDataBlocks(bucket, sc string, totalDisks int) int {
+	info := getBucketRedundancyCount(bucket, sc, totalDisks)
+	if maxParity := maxParityForDisks(totalDisks); info.Parity > maxParity {
+		info.Data = totalDisks - maxParity
+	}
+	return info.Data
+}
+
+// Returns the bytes written across totalDisks for an object of the given size
+// in a bucket with the storage class sc, see bucketErasureDataBlocks.
+func bucketErasureObjectSize(bucket, sc string, size int64, totalDisks int) int64 {
+	data := bucketErasureDataBlocks(bucket, sc, totalDisks)
+	if size <= 0 || data <= 0 {
+		return 0
+	}
+	return erasureShardSize(size, data) * int64(totalDisks)
+}
+
+// Estimates the space reclaimed by migrating all the objects of a bucket to
+// the target storage class by listing the objects of the bucket, no object
+// is modified. Objects already in the target storage class, or already laid
+// out with its data disks and only relabelled by a migration, are excluded,
+// the rest would be re-encoded and are counted in Objects. The current bytes
+// of an object are taken from the layout in its xl.json, as objects without
+// storage class or written under an older config are not laid out with the
+// current parity of their storage class. Objects removed since they were
+// listed are skipped.
+func (xl xlObjects) estimateStorageClassMigration(bucket, targetClass string) (storageClassMigrationEstimate, error) {
+	totalDisks := xl.setDriveCount()
+	targetClass = getStorageClassFromAlias(targetClass)
+	targetData := bucketErasureDataBlocks(bucket, targetClass, totalDisks)
+	estimate := storageClassMigrationEstimate{Bucket: bucket, TargetClass: targetClass}
+	marker := ""
+	for {
+		result, err := xl.ListObjects(bucket, "", marker, "", maxObjectList)
+		if err != nil {
+			return estimate, errors.Cause(err)
+		}
+		for _, objInfo := range result.Objects {
+			metaArr, errs := readAllXLMetadata(xl.storageDisks, bucket, objInfo.Name)
+			index, _ := getLatestXLMetaIndex(metaArr, errs)
+			if index == -1 {
+				continue
+			}
+			xlMeta := metaArr[index]
+			if getStorageClassFromAlias(xlMeta.Meta[amzStorageClass]) == targetClass || xlMeta.Erasure.DataBlocks == targetData {
+				continue
+			}
+			estimate.Objects++
+			estimate.CurrentBytes += getRawObjectSize(xlMeta, totalDisks)
+			estimate.TargetBytes += bucketErasureObjectSize(bucket, targetClass, xlMeta.Stat.Size, totalDisks)
+		}
+		if !result.IsTruncated {
+			break
+		}
+		marker = result.NextMarker
+	}
+	estimate.SavedBytes = estimate.CurrentBytes - estimate.TargetBytes
+	return estimate, nil
+}
+
+// Loads all bucket storage class configs from persistent layer.
+func loadAllBucketStorageClass(objAPI ObjectLayer) (map[string]bucketStorageClassConfig, error) {
+	buckets, err := objAPI.ListBuckets()
+	if err != nil {
+		errorIf(err, "Unable to list buckets.")
+		return nil, errors.Cause(err)
+	}
+
+	scCfgs := make(map[string]bucketStorageClassConfig)
+	for _, bucket := range buckets {
+		scCfg, err := readBucketStorageClassConfig(bucket.Name, objAPI)
+		if err != nil {
+			// net.Dial fails for rpc client or any
+			// other unexpected errors during net.Dial.
+			if errors.IsErrIgnored(err, errDiskNotFound) || isErrObjectNotFound(err) {
+				// Continue to load other bucket configs if possible.
+				continue
+			}
+			return scCfgs, err
+		}
+		// Bucket default is ignored while Reduced redundancy is denied as a default, see resolveStorageClass.
+		if scCfg.Default != "" {
+			errorIf(checkDefaultStorageClass(scCfg.Default), "Ignoring default storage class %s of bucket %s.", scCfg.Default, bucket.Name)
+		}
+		scCfgs[bucket.Name] = scCfg
+	}
+
+	// Success.
+	return scCfgs, nil
+}
+
+// Intialize all bucket storage class configs.
+func initBucketStorageClass(objAPI ObjectLayer) error {
+	if objAPI == nil {
+		return errInvalidArgument
+	}
+
+	scCfgs, err := loadAllBucketStorageClass(objAPI)
+	if err != nil {
+		return err
+	}
+
+	// Populate global bucket storage class collection.
+	globalBucketStorageClass = &bucketStorageClasses{
+		rwMutex:                   &sync.RWMutex{},
+		bucketStorageClassConfigs: scCfgs,
+	}
+
+	// Compute the bytes stored in buckets with a quota ahead of the
+	// first quota check.
+	for bucket, scCfg := range scCfgs {
+		if len(scCfg.Quota) > 0 {
+			go globalBucketQuotaUsage.refresh(bucket, objAPI)
+		}
+	}
+
+	return nil
+}
+
+// readBucketStorageClassConfig - reads storage class config of a bucket,
+// returns ObjectNotFound if the bucket has no storage class config.
+func readBucketStorageClassConfig(bucket string, objAPI ObjectLayer) (scCfg bucketStorageClassConfig, err error) {
+	scPath := pathJoin(bucketConfigPrefix, bucket, bucketStorageClassConfigFile)
+
+	// Acquire a read lock on storage class config before reading.
+	objLock := globalNSMutex.NewNSLock(minioMetaBucket, scPath)
+	if err = objLock.GetRLock(globalOperationTimeout); err != nil {
+		return scCfg, err
+	}
+	defer objLock.RUnlock()
+
+	var buffer bytes.Buffer
+	if err = objAPI.GetObject(minioMetaBucket, scPath, 0, -1, &buffer); err != nil {
+		return scCfg, errors.Cause(err)
+	}
+
+	if err = json.Unmarshal(buffer.Bytes(), &scCfg); err != nil {
+		return scCfg, err
+	}
+
+	return scCfg, nil
+}
+
+// writeBucketStorageClassConfig - save a bucket storage class config
+// that is assumed to be validated.
+func writeBucketStorageClassConfig(bucket string, objAPI ObjectLayer, scCfg bucketStorageClassConfig) error {
+	buf, err := json.Marshal(&scCfg)
+	if err != nil {
+		return err
+	}
+
+	scPath := pathJoin(bucketConfigPrefix, bucket, bucketStorageClassConfigFile)
+	// Acquire a write lock on storage class config before modifying.
+	objLock := globalNSMutex.NewNSLock(minioMetaBucket, scPath)
+	if err = objLock.GetLock(globalOperationTimeout); err != nil {
+		return err
+	}
+	defer objLock.Unlock()
+
+	hashReader, err := hash.NewReader(bytes.NewReader(buf), int64(len(buf)), "", getSHA256Hash(buf))
+	if err != nil {
+		return errors.Cause(err)
+	}
+
+	if _, err = objAPI.PutObject(minioMetaBucket, scPath, hashReader, nil); err != nil {
+		errorIf(err, "Unable to set storage class for the bucket %s", bucket)
+		return errors.Cause(err)
+	}
+	return nil
+}
+
+// removeBucketStorageClassConfig - removes storage class config of a
+// bucket, returns ObjectNotFound if no config is present.
+func removeBucketStorageClassConfig(bucket string, objAPI ObjectLayer) error {
+	scPath := pathJoin(bucketConfigPrefix, bucket, bucketStorageClassConfigFile)
+	// Acquire a write lock on storage class config before modifying.
+	objLock := globalNSMutex.NewNSLock(minioMetaBucket, scPath)
+	if err := objLock.GetLock(globalOperationTimeout); err != nil {
+		return err
+	}
+	defer objLock.Unlock()
+
+	return errors.Cause(objAPI.DeleteObject(minioMetaBucket, scPath))
+}
+
+// persistAndNotifyBucketStorageClassChange - persists the storage class
+// config of a bucket and notifies all the nodes in the cluster about the
+// change, a nil config removes the storage class config of the bucket.
+func persistAndNotifyBucketStorageClassChange(bucket string, scCfg *bucketStorageClassConfig, objAPI ObjectLayer) error {
+	if scCfg == nil {
+		if err := removeBucketStorageClassConfig(bucket, objAPI); err != nil && !isErrObjectNotFound(err) {
+			return err
+		}
+	} else {
+		if err := writeBucketStorageClassConfig(bucket, objAPI, *scCfg); err != nil {
+			return err
+		}
+	}
+
+	// Notify all peers (including self) to update in-memory state
+	S3PeersUpdateBucketStorageClass(bucket, scCfg)
+	return nil
+}
@@ -0,0 +1,513 @@
+/*
+ * Minio Cloud Storage, (C) 2017 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBucketStorageClass(t *testing.T) {
+	// initialize NSLock, bucket storage class config is read and written under a namespace lock.
+	initNSLock(false)
+	ExecObjectLayerTestWithDirs(t, testBucketStorageClass)
+}
+
+func testBucketStorageClass(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	xl := obj.(*xlObjects)
+
+	bucket := getRandomBucketName()
+	if err := obj.MakeBucketWithLocation(bucket, globalMinioDefaultRegion); err != nil {
+		t.Fatalf("Failed to make a bucket %v", err)
+	}
+
+	// Bucket without a storage class config reads back ObjectNotFound.
+	if _, err := readBucketStorageClassConfig(bucket, obj); !isErrObjectNotFound(err) {
+		t.Fatalf("Expected ObjectNotFound, got %v", err)
+	}
+
+	scCfg := bucketStorageClassConfig{
+		Standard: storageClass{Scheme: "EC", Parity: 6},
+		RRS:      storageClass{Scheme: "EC", Parity: 3},
+	}
+	if err := writeBucketStorageClassConfig(bucket, obj, scCfg); err != nil {
+		t.Fatalf("Failed to write bucket storage class %v", err)
+	}
+
+	// Reload all bucket storage class configs as done during startup.
+	if err := initBucketStorageClass(obj); err != nil {
+		t.Fatalf("Failed to load bucket storage class %v", err)
+	}
+
+	gotCfg, ok := globalBucketStorageClass.GetBucketStorageClass(bucket)
+	if !ok || !reflect.DeepEqual(gotCfg, scCfg) {
+		t.Fatalf("Expected %v, got %v", scCfg, gotCfg)
+	}
+
+	globalStandardStorageClass = storageClass{Scheme: "EC", Parity: 5}
+
+	tests := []struct {
+		name           int
+		bucket         string
+		sc             string
+		expectedData   int
+		expectedParity int
+	}{
+		{1, bucket, standardStorageClass, 10, 6},
+		{2, bucket, reducedRedundancyStorageClass, 13, 3},
+		{3, bucket, "", 8, 8},
+		{4, "otherbucket", standardStorageClass, 11, 5},
+		{5, "otherbucket", reducedRedundancyStorageClass, 14, 2},
+	}
+	for _, tt := range tests {
+		info := getBucketRedundancyCount(tt.bucket, tt.sc, len(xl.storageDisks))
+		if info.Data != tt.expectedData {
+			t.Errorf("Test %d, Expected data disks %d, got %d", tt.name, tt.expectedData, info.Data)
+		}
+		if info.Parity != tt.expectedParity {
+			t.Errorf("Test %d, Expected parity disks %d, got %d", tt.name, tt.expectedParity, info.Parity)
+		}
+	}
+
+	// Removing the bucket config falls back to server wide storage class.
+	globalBucketStorageClass.SetBucketStorageClass(bucket, nil)
+	if parity := getBucketRedundancyCount(bucket, standardStorageClass, len(xl.storageDisks)).Parity; parity != 5 {
+		t.Errorf("Expected parity disks %d, got %d", 5, parity)
+	}
+
+	resetGlobalStorageEnvs()
+}
+
+func TestBucketStorageClassQuota(t *testing.T) {
+	// initialize NSLock, bucket storage class config is read and written under a namespace lock.
+	initNSLock(false)
+	ExecObjectLayerTestWithDirs(t, testBucketStorageClassQuota)
+}
+
+func testBucketStorageClassQuota(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+	globalEndpoints = mustGetNewEndpointList(dirs...)
+	defer resetGlobalEndpoints()
+	globalBucketQuotaUsage = newBucketQuotaUsage()
+	defer func() { globalBucketQuotaUsage = newBucketQuotaUsage() }()
+
+	bucket := getRandomBucketName()
+	if err := obj.MakeBucketWithLocation(bucket, globalMinioDefaultRegion); err != nil {
+		t.Fatalf("Failed to make a bucket %v", err)
+	}
+	if err := initBucketStorageClass(obj); err != nil {
+		t.Fatalf("Failed to load bucket storage class %v", err)
+	}
+
+	// Quota of an unsupported storage class is rejected.
+	if err := validateBucketStorageClassConfig(bucketStorageClassConfig{Quota: map[string]uint64{"GLACIER": 10}}); err == nil {
+		t.Errorf("Expected quota of an unsupported storage class to be rejected")
+	}
+
+	scCfg := bucketStorageClassConfig{Quota: map[string]uint64{reducedRedundancyStorageClass: 1024}}
+	if err := validateBucketStorageClassConfig(scCfg); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	globalBucketStorageClass.SetBucketStorageClass(bucket, &scCfg)
+	globalBucketQuotaUsage.refresh(bucket, obj)
+
+	rrsMeta := func() map[string]string {
+		return map[string]string{amzStorageClass: reducedRedundancyStorageClass}
+	}
+	data := bytes.Repeat([]byte("a"), 600)
+	tests := []struct {
+		name        int
+		object      string
+		metadata    map[string]string
+		restart     bool
+		expectedErr APIErrorCode
+	}{
+		{1, "rrs-1", rrsMeta(), false, ErrNone},
+		// Second object exceeds the RRS quota of 1024 bytes.
+		{2, "rrs-2", rrsMeta(), false, ErrStorageClassQuotaExceeded},
+		// Objects written before the server started are accounted.
+		{3, "rrs-2", rrsMeta(), true, ErrStorageClassQuotaExceeded},
+		// Overwritten object frees its bytes.
+		{4, "rrs-1", rrsMeta(), false, ErrNone},
+		// Standard storage class has no quota.
+		{5, "standard", nil, false, ErrNone},
+		{6, "standard", nil, false, ErrNone},
+	}
+	for _, tt := range tests {
+		if tt.restart {
+			globalBucketQuotaUsage = newBucketQuotaUsage()
+			globalBucketQuotaUsage.refresh(bucket, obj)
+		}
+		_, err := obj.PutObject(bucket, tt.object, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), tt.metadata)
+		if apiErr := toAPIErrorCode(err); apiErr != tt.expectedErr {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedErr, apiErr)
+		}
+	}
+	if used := globalBucketQuotaUsage.Get(bucket, reducedRedundancyStorageClass, obj); used != 600 {
+		t.Errorf("Expected %d bytes used, got %d", 600, used)
+	}
+
+	// Deleted object frees its bytes.
+	if err := obj.DeleteObject(bucket, "rrs-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := obj.PutObject(bucket, "rrs-2", mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), rrsMeta()); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	// Bytes of a deleted bucket are dropped.
+	for _, object := range []string{"rrs-2", "standard"} {
+		if err := obj.DeleteObject(bucket, object); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := obj.DeleteBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := globalBucketQuotaUsage.buckets[bucket]; ok {
+		t.Errorf("Expected bytes of deleted bucket %s to be dropped", bucket)
+	}
+}
+
+// Tests the bytes stored in a bucket are listed in the background once at
+// a time, the last bytes computed are served meanwhile and the bytes
+// written while listing are merged into the listed bytes.
+func TestBucketQuotaUsageRefresh(t *testing.T) {
+	obj, fsDirs, err := prepareXL16()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	bucket := "bucket"
+	if err = obj.MakeBucketWithLocation(bucket, ""); err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Repeat([]byte("a"), 100)
+	for _, object := range []string{"object-1", "object-2"} {
+		if _, err = obj.PutObject(bucket, object, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	u := newBucketQuotaUsage()
+	// Bucket not listed yet, nothing is accounted and a single
+	// listing is started whatever the number of quota checks.
+	for i := 0; i < 10; i++ {
+		if used := u.Get(bucket, standardStorageClass, obj); used != 0 {
+			t.Errorf("Expected %d bytes used, got %d", 0, used)
+		}
+	}
+	u.mutex.Lock()
+	entry := u.buckets[bucket]
+	if !entry.refreshing {
+		t.Errorf("Expected bucket %s to be listed", bucket)
+	}
+	u.mutex.Unlock()
+	// Object written while listing.
+	u.Add(bucket, "", 50)
+
+	// Wait for the listing to be done.
+	for i := 0; i < 100; i++ {
+		u.mutex.Lock()
+		refreshing := entry.refreshing
+		u.mutex.Unlock()
+		if !refreshing {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if used := u.Get(bucket, "", obj); used != 250 {
+		t.Errorf("Expected %d bytes used, got %d", 250, used)
+	}
+
+	// Listing of a bucket dropped meanwhile is not kept.
+	u.mutex.Lock()
+	entry, _ = u.getEntry(bucket, false)
+	entry.refreshing = true
+	u.mutex.Unlock()
+	u.Remove(bucket)
+	u.list(bucket, entry, obj)
+	if _, ok := u.buckets[bucket]; ok {
+		t.Errorf("Expected bytes of dropped bucket %s to not be kept", bucket)
+	}
+}
+
+func TestBucketStorageClassUsage(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testBucketStorageClassUsage)
+}
+
+func testBucketStorageClassUsage(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+
+	bucket := getRandomBucketName()
+	if err := obj.MakeBucketWithLocation(bucket, globalMinioDefaultRegion); err != nil {
+		t.Fatalf("Failed to make a bucket %v", err)
+	}
+
+	objects := []struct {
+		name     string
+		size     int
+		metadata map[string]string
+	}{
+		{"standard/1", 100, map[string]string{amzStorageClass: standardStorageClass}},
+		// Objects without storage class are accounted as Standard storage class.
+		{"standard/2", 200, nil},
+		{"rrs/1", 300, map[string]string{amzStorageClass: reducedRedundancyStorageClass}},
+		{"rrs/2", 400, map[string]string{amzStorageClass: reducedRedundancyStorageClass}},
+	}
+	for _, object := range objects {
+		data := bytes.Repeat([]byte("a"), object.size)
+		if _, err := obj.PutObject(bucket, object.name, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), object.metadata); err != nil {
+			t.Fatalf("Failed to put object %s %v", object.name, err)
+		}
+	}
+
+	usage, err := getBucketStorageClassUsage(bucket, obj)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	expected := bucketStorageClassUsage{
+		Bucket: bucket,
+		Usage: map[string]ServerStorageClassCounter{
+			standardStorageClass:          {Objects: 2, Bytes: 300},
+			reducedRedundancyStorageClass: {Objects: 2, Bytes: 700},
+		},
+	}
+	if !reflect.DeepEqual(usage, expected) {
+		t.Errorf("Expected %v, got %v", expected, usage)
+	}
+
+	// Deleted objects are no longer accounted.
+	if err = obj.DeleteObject(bucket, "rrs/1"); err != nil {
+		t.Fatalf("Failed to delete object %v", err)
+	}
+	if usage, err = getBucketStorageClassUsage(bucket, obj); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if counter := usage.Usage[reducedRedundancyStorageClass]; counter.Objects != 1 || counter.Bytes != 400 {
+		t.Errorf("Expected %d objects and %d bytes, got %v", 1, 400, counter)
+	}
+
+	if _, err = getBucketStorageClassUsage("nonexistent-bucket", obj); toAPIErrorCode(err) != ErrNoSuchBucket {
+		t.Errorf("Expected BucketNotFound, got %v", err)
+	}
+}
+
+func TestResolveStorageClass(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testResolveStorageClass)
+}
+
+func testResolveStorageClass(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+	globalEndpoints = mustGetNewEndpointList(dirs...)
+	defer resetGlobalEndpoints()
+	if err := initBucketStorageClass(obj); err != nil {
+		t.Fatalf("Failed to load bucket storage class %v", err)
+	}
+	globalStorageClassAliases = map[string]string{"GLACIER": reducedRedundancyStorageClass}
+
+	bucket := getRandomBucketName()
+	if err := obj.MakeBucketWithLocation(bucket, globalMinioDefaultRegion); err != nil {
+		t.Fatalf("Failed to make a bucket %v", err)
+	}
+	scCfg := bucketStorageClassConfig{Default: reducedRedundancyStorageClass}
+	if err := validateBucketStorageClassConfig(scCfg); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	globalBucketStorageClass.SetBucketStorageClass(bucket, &scCfg)
+	globalBucketStorageClassRules = []bucketStorageClassRule{
+		{"logs-*", "GLACIER"},
+		{"logs-audit-*", maxDurabilityStorageClass},
+		{"*-tmp", scratchStorageClass},
+	}
+
+	tests := []struct {
+		name           int
+		bucket         string
+		reqClass       string
+		expectedClass  string
+		expectedSource string
+	}{
+		// Storage class in the request takes precedence over bucket default.
+		{1, bucket, standardStorageClass, standardStorageClass, storageClassSourceRequest},
+		{2, bucket, "GLACIER", reducedRedundancyStorageClass, storageClassSourceRequest},
+		{3, bucket, "", reducedRedundancyStorageClass, storageClassSourceBucket},
+		// Bucket without default storage class falls back to server default.
+		{4, "otherbucket", "", "", storageClassSourceDefault},
+		{5, "otherbucket", maxDurabilityStorageClass, maxDurabilityStorageClass, storageClassSourceRequest},
+		// Invalid storage classes fall back to server default.
+		{6, "otherbucket", "UNKNOWN", "", storageClassSourceDefault},
+		// Buckets without default storage class use the first matching rule.
+		{7, "logs-2018", "", reducedRedundancyStorageClass, storageClassSourceRule},
+		{8, "logs-audit-2018", "", reducedRedundancyStorageClass, storageClassSourceRule},
+		{9, "build-tmp", "", scratchStorageClass, storageClassSourceRule},
+		{10, "build-tmp", standardStorageClass, standardStorageClass, storageClassSourceRequest},
+	}
+	for _, tt := range tests {
+		sc, source := resolveStorageClass(tt.bucket, tt.reqClass)
+		if sc != tt.expectedClass || source != tt.expectedSource {
+			t.Errorf("Test %d, Expected %s from %s, got %s from %s", tt.name, tt.expectedClass, tt.expectedSource, sc, source)
+		}
+	}
+
+	// Objects written without storage class get the bucket default storage class.
+	data := []byte("abcd")
+	if _, err := obj.PutObject(bucket, "object", mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), nil); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	objInfo, err := obj.GetObjectInfo(bucket, "object")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if objInfo.StorageClass != reducedRedundancyStorageClass {
+		t.Errorf("Expected storage class %s, got %s", reducedRedundancyStorageClass, objInfo.StorageClass)
+	}
+	uploadID, err := obj.NewMultipartUpload(bucket, "multipart", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	result, err := obj.ListObjectParts(bucket, "multipart", uploadID, 0, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if result.StorageClass != reducedRedundancyStorageClass {
+		t.Errorf("Expected storage class %s, got %s", reducedRedundancyStorageClass, result.StorageClass)
+	}
+
+	if err = validateBucketStorageClassConfig(bucketStorageClassConfig{Default: "UNKNOWN"}); err == nil {
+		t.Errorf("Expected unsupported default storage class to be rejected")
+	}
+
+	// Reduced redundancy storage class is only accepted in the request if denied as a default.
+	globalStorageClassDenyRRSDefault = true
+	for _, sc := range []string{reducedRedundancyStorageClass, "GLACIER"} {
+		if err = validateBucketStorageClassConfig(bucketStorageClassConfig{Default: sc}); err == nil {
+			t.Errorf("Expected default storage class %s to be rejected", sc)
+		}
+	}
+	if err = validateBucketStorageClassConfig(bucketStorageClassConfig{Default: standardStorageClass}); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	denyTests := []struct {
+		name           int
+		bucket         string
+		reqClass       string
+		expectedClass  string
+		expectedSource string
+	}{
+		// Bucket default and rules saved before the guard was enabled are ignored.
+		{1, bucket, "", "", storageClassSourceDefault},
+		{2, "logs-2018", "", "", storageClassSourceDefault},
+		{3, bucket, "GLACIER", reducedRedundancyStorageClass, storageClassSourceRequest},
+		{4, "build-tmp", "", scratchStorageClass, storageClassSourceRule},
+	}
+	for _, tt := range denyTests {
+		sc, source := resolveStorageClass(tt.bucket, tt.reqClass)
+		if sc != tt.expectedClass || source != tt.expectedSource {
+			t.Errorf("Test %d, Expected %s from %s, got %s from %s", tt.name, tt.expectedClass, tt.expectedSource, sc, source)
+		}
+	}
+}
+
+func TestEstimateStorageClassMigration(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testEstimateStorageClassMigration)
+}
+
+func testEstimateStorageClassMigration(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+	xl := obj.(*xlObjects)
+	disks := len(xl.storageDisks)
+
+	bucket := getRandomBucketName()
+	if err := obj.MakeBucketWithLocation(bucket, globalMinioDefaultRegion); err != nil {
+		t.Fatalf("Failed to make a bucket %v", err)
+	}
+	objects := []struct {
+		name     string
+		size     int
+		metadata map[string]string
+	}{
+		{"standard/1", 1000, map[string]string{amzStorageClass: standardStorageClass}},
+		{"standard/2", 2000, nil},
+		// Objects already in the target storage class are excluded.
+		{"rrs/1", 3000, map[string]string{amzStorageClass: reducedRedundancyStorageClass}},
+	}
+	for _, object := range objects {
+		data := bytes.Repeat([]byte("a"), object.size)
+		if _, err := obj.PutObject(bucket, object.name, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), object.metadata); err != nil {
+			t.Fatalf("Failed to put object %s %v", object.name, err)
+		}
+	}
+
+	estimate, err := xl.estimateStorageClassMigration(bucket, reducedRedundancyStorageClass)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	// STANDARD objects have 8 data disks, REDUCED_REDUNDANCY objects 14 data disks.
+	currentBytes := (erasureShardSize(1000, 8) + erasureShardSize(2000, 8)) * int64(disks)
+	targetBytes := (erasureShardSize(1000, 14) + erasureShardSize(2000, 14)) * int64(disks)
+	expected := storageClassMigrationEstimate{
+		Bucket:       bucket,
+		TargetClass:  reducedRedundancyStorageClass,
+		Objects:      2,
+		CurrentBytes: currentBytes,
+		TargetBytes:  targetBytes,
+		SavedBytes:   currentBytes - targetBytes,
+	}
+	if estimate != expected {
+		t.Errorf("Expected %v, got %v", expected, estimate)
+	}
+
+	// Migrating to a more durable storage class needs more space.
+	if estimate, err = xl.estimateStorageClassMigration(bucket, standardStorageClass); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if estimate.Objects != 1 || estimate.SavedBytes >= 0 {
+		t.Errorf("Expected 1 object and negative savings, got %v", estimate)
+	}
+
+	// Current bytes are taken from the layout objects are written with, not from
+	// the current parity of their storage class or from the Standard parity.
+	globalStandardStorageClass = storageClass{Scheme: supportedStorageClassScheme, Parity: 4}
+	globalRedundancyCache.Invalidate()
+	if estimate, err = xl.estimateStorageClassMigration(bucket, reducedRedundancyStorageClass); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if estimate.CurrentBytes != currentBytes {
+		t.Errorf("Expected current bytes %d, got %d", currentBytes, estimate.CurrentBytes)
+	}
+
+	// Parity is capped at N/2 of the current disks, STANDARD parity
+	// 8 on 12 disks is estimated with 6 data disks.
+	globalStandardStorageClass = storageClass{Scheme: supportedStorageClassScheme, Parity: 8}
+	if size := bucketErasureObjectSize(bucket, standardStorageClass, 1000, 12); size != erasureShardSize(1000, 6)*12 {
+		t.Errorf("Expected %d, got %d", erasureShardSize(1000, 6)*12, size)
+	}
+}
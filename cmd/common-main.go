@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -119,33 +120,88 @@ func handleCommonEnvVars() {
 	// in-place update is off.
 	globalInplaceUpdateDisabled = strings.EqualFold(os.Getenv("MINIO_UPDATE"), "off")
 
+	// Storage class aliases are accepted by all setups, so that S3 clients
+	// sending foreign storage classes work unmodified.
+	if aliases := os.Getenv(storageClassAliasesEnv); aliases != "" {
+		var err error
+		globalStorageClassAliases, err = parseStorageClassAliases(aliases)
+		fatalIf(err, "Invalid value set in environment variable %s.", storageClassAliasesEnv)
+	}
+
 	// Validate and store the storage class env variables only for XL/Dist XL setups
 	if globalIsXL {
 		var err error
 
-		// Check for environment variables and parse into storageClass struct
-		if ssc := os.Getenv(standardStorageClassEnv); ssc != "" {
-			globalStandardStorageClass, err = parseStorageClass(ssc)
-			fatalIf(err, "Invalid value set in environment variable %s.", standardStorageClassEnv)
+		// Reduced redundancy storage class on 4 disks setup is only allowed with an explicit opt-in.
+		globalStorageClassAllowSmall = strings.EqualFold(os.Getenv(storageClassAllowSmallEnv), "on")
+
+		// Storage class startup messages may be turned off for quiet deployments.
+		globalStorageClassQuiet = strings.EqualFold(os.Getenv(storageClassQuietEnv), "on")
+
+		// Even parity disks may be required for storage classes, e.g. for certain hardware layouts.
+		globalStorageClassRequireEvenParity = strings.EqualFold(os.Getenv(storageClassRequireEvenParityEnv), "on")
+
+		// Storage class validation errors may be printed as JSON for deployment tooling.
+		globalStorageClassJSONErrors = strings.EqualFold(os.Getenv(storageClassJSONErrorsEnv), "on")
+
+		// Storage class applied to writes may be audit logged for data governance reporting.
+		globalStorageClassAudit = strings.EqualFold(os.Getenv(storageClassAuditEnv), "on")
+
+		// Storage classes set differently in environment variables and config file may be rejected.
+		globalStorageClassStrict = strings.EqualFold(os.Getenv(storageClassStrictEnv), "on")
+
+		// Storage class parity exceeding N/2 of the disks may be clamped to keep a scaled down setup writable.
+		globalStorageClassClampParity = strings.EqualFold(os.Getenv(storageClassClampParityEnv), "on")
+
+		// Healed objects may be re-encoded with the current parity of their storage class, e.g. to roll a cluster onto new parity.
+		globalStorageClassHealReparity = strings.EqualFold(os.Getenv(storageClassHealReparityEnv), "on")
+
+		// Quorum margin of objects read may be accounted to monitor clusters trending toward quorum loss.
+		if strings.EqualFold(os.Getenv(storageClassQuorumStatsEnv), "on") {
+			globalQuorumMarginStats = newQuorumMarginStats()
+		}
+
+		// Storage classes are loaded from the storage class config file and environment
+		// variables, all of them are validated together with the quorum policy.
+		globalStandardStorageClass, globalRRStorageClass, globalMaxStorageClass, err = loadStorageClassEnv()
+		if err != nil && globalStorageClassJSONErrors {
+			// Validation errors may be parsed by deployment tooling.
+			if jsonBytes, jerr := storageClassErrorJSON(err, globalStandardStorageClass,
+				globalRRStorageClass, globalMaxStorageClass, len(globalEndpoints)); jerr == nil {
+				fmt.Fprintln(os.Stderr, string(jsonBytes))
+			}
 		}
+		fatalIf(err, "Invalid storage class set in environment variables.")
+		globalIsStorageClass = globalRRStorageClass.Scheme != "" || globalStandardStorageClass.Scheme != ""
 
-		if rrsc := os.Getenv(reducedRedundancyStorageClassEnv); rrsc != "" {
-			globalRRStorageClass, err = parseStorageClass(rrsc)
-			fatalIf(err, "Invalid value set in environment variable %s.", reducedRedundancyStorageClassEnv)
+		// Reduced redundancy storage class may be restricted to objects requesting it explicitly.
+		globalStorageClassDenyRRSDefault = strings.EqualFold(os.Getenv(storageClassDenyRRSDefaultEnv), "on")
+
+		// Buckets matching a pattern may default to a storage class, e.g. for fleets with bucket naming conventions.
+		if rules := os.Getenv(storageClassBucketRulesEnv); rules != "" {
+			globalBucketStorageClassRules, err = parseBucketStorageClassRules(rules)
+			fatalIf(err, "Invalid value set in environment variable %s.", storageClassBucketRulesEnv)
+		}
+
+		// Small objects may be written with less parity, opt-in per storage class.
+		if thresholds := os.Getenv(storageClassSmallObjectParityEnv); thresholds != "" {
+			globalSmallObjectParityThresholds, err = parseSmallObjectParityThresholds(thresholds)
+			fatalIf(err, "Invalid value set in environment variable %s.", storageClassSmallObjectParityEnv)
 		}
 
-		// Validation is done after parsing both the storage classes. This is needed because we need one
-		// storage class value to deduce the correct value of the other storage class.
-		if globalRRStorageClass.Scheme != "" {
-			err := validateRRSParity(globalRRStorageClass.Parity, globalStandardStorageClass.Parity)
-			fatalIf(err, "Invalid value set in environment variable %s.", reducedRedundancyStorageClassEnv)
-			globalIsStorageClass = true
+		// Default parity of standard storage class may be capped on large clusters.
+		if value := os.Getenv(storageClassMaxParityEnv); value != "" {
+			globalStorageClassMaxParity, err = parseMaxParity(value)
+			fatalIf(err, "Invalid value set in environment variable %s.", storageClassMaxParityEnv)
 		}
 
-		if globalStandardStorageClass.Scheme != "" {
-			err := validateSSParity(globalStandardStorageClass.Parity, globalRRStorageClass.Parity)
-			fatalIf(err, "Invalid value set in environment variable %s.", standardStorageClassEnv)
-			globalIsStorageClass = true
+		// Reduced redundancy storage class parity used when it is not set may be tuned cluster wide.
+		if value := os.Getenv(storageClassRRSDefaultEnv); value != "" {
+			globalRRSDefaultParity, err = parseRRSDefaultParity(value, globalStandardStorageClass.Parity)
+			fatalIf(err, "Invalid value set in environment variable %s.", storageClassRRSDefaultEnv)
 		}
+
+		// Drop the parity resolved before the storage classes were loaded.
+		globalRedundancyCache.Invalidate()
 	}
 }
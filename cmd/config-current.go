@@ -123,19 +123,35 @@ func (s *serverConfig) GetStorageClass() (storageClass, storageClass) {
 	ssc := s.StorageClass.Standard
 	rrsc := s.StorageClass.RRS
 
+	err = validateStorageClassSchemes(ssc.Scheme, rrsc.Scheme)
+	fatalIf(err, "Invalid storage class set in config.json")
+
+	for _, sc := range []storageClass{ssc, rrsc} {
+		err = checkStorageClassDisks(sc, len(globalEndpoints))
+		fatalIf(err, "Invalid value %s set in config.json", sc)
+	}
+
+	// Reduced redundancy storage class may be relative to Standard storage class parity.
+	err = checkRelativeStorageClasses(ssc, storageClass{})
+	fatalIf(err, "Invalid storage class set in config.json")
+	rrsc = resolveRelativeParity(rrsc, ssc, len(globalEndpoints))
+
 	if rrsc.Scheme != "" {
 		err = validateRRSParity(rrsc.Parity, ssc.Parity)
-		fatalIf(err, "Invalid value %s:%d set in config.json", rrsc.Scheme, rrsc.Parity)
+		fatalIf(err, "Invalid value %s set in config.json", rrsc)
 		globalIsStorageClass = true
 	}
 
 	if ssc.Scheme != "" {
 		err = validateSSParity(ssc.Parity, rrsc.Parity)
-		fatalIf(err, "Invalid value %s:%d set in config.json", ssc.Scheme, ssc.Parity)
+		fatalIf(err, "Invalid value %s set in config.json", ssc)
 		globalIsStorageClass = true
 	}
 
-	return s.StorageClass.Standard, s.StorageClass.RRS
+	err = checkStorageClassWriteQuorum(ssc, rrsc, storageClass{}, len(globalEndpoints))
+	fatalIf(err, "Invalid storage class set in config.json")
+
+	return ssc, rrsc
 }
 
 // GetCredentials get current credentials.
@@ -363,7 +379,15 @@ func loadConfig() error {
 		globalDomainName = globalServerConfig.Domain
 	}
 	if !globalIsStorageClass {
+		// Quorum policy is only set via environment, retain it
+		// and validate it against the storage classes in config.
+		q := globalStandardStorageClass.Quorum
 		globalStandardStorageClass, globalRRStorageClass = globalServerConfig.GetStorageClass()
+		if q != (quorumPolicy{}) {
+			fatalIf(setStorageClassQuorumPolicy(q), "Invalid value set in environment variable %s.", storageClassQuorumEnv)
+		}
+		// Drop the parity resolved from the previous storage classes.
+		globalRedundancyCache.Invalidate()
 	}
 	globalServerConfigMu.Unlock()
 
@@ -91,6 +91,8 @@ type eventData struct {
 	Host      string
 	Port      string
 	UserAgent string
+	// Storage class the object was transitioned from.
+	PrevStorageClass string
 }
 
 // New notification event constructs a new notification event message from
@@ -166,15 +168,24 @@ func newNotificationEvent(event eventData) NotificationEvent {
 		return nEvent
 	}
 
-	// For all other events we should set ETag and Size.
+	// Storage class the object was written with, objects
+	// without storage class are STANDARD.
+	storageClass := event.ObjInfo.StorageClass
+	if storageClass == "" {
+		storageClass = getObjectStorageClass(event.ObjInfo.UserDefined)
+	}
+
+	// For all other events we should set ETag, Size and StorageClass.
 	nEvent.S3.Object = objectMeta{
-		Key:          escapedObj,
-		ETag:         event.ObjInfo.ETag,
-		Size:         event.ObjInfo.Size,
-		ContentType:  event.ObjInfo.ContentType,
-		UserMetadata: event.ObjInfo.UserDefined,
-		VersionID:    "1",
-		Sequencer:    uniqueID,
+		Key:                  escapedObj,
+		ETag:                 event.ObjInfo.ETag,
+		Size:                 event.ObjInfo.Size,
+		ContentType:          event.ObjInfo.ContentType,
+		UserMetadata:         event.ObjInfo.UserDefined,
+		VersionID:            "1",
+		StorageClass:         storageClass,
+		PreviousStorageClass: event.PrevStorageClass,
+		Sequencer:            uniqueID,
 	}
 
 	// Success.
@@ -342,6 +353,7 @@ func eventNotify(event eventData) {
 	//  - s3:ObjectCreated:Copy
 	//  - s3:ObjectCreated:CompleteMultipartUpload
 	//  - s3:ObjectRemoved:Delete
+	//  - s3:ObjectTransition:StorageClass
 
 	// Event type.
 	eventType := event.Type.String()
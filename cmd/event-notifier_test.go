@@ -583,3 +583,95 @@ func TestAddRemoveBucketListenerConfig(t *testing.T) {
 			lcSlice)
 	}
 }
+
+// Tests the storage class an object is written with is set in the
+// notification event, for both PutObject and CompleteMultipartUpload.
+func TestNewNotificationEventStorageClass(t *testing.T) {
+	root, err := newTestConfig(globalMinioDefaultRegion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	obj, fsDirs, err := prepareXL16()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	bucketName := "bucket"
+	if err = obj.MakeBucketWithLocation(bucketName, ""); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	data := []byte("hello")
+	rrsMeta := map[string]string{amzStorageClass: reducedRedundancyStorageClass}
+
+	putInfo, err := obj.PutObject(bucketName, "put", mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), rrsMeta)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	defaultInfo, err := obj.PutObject(bucketName, "default", mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	uploadID, err := obj.NewMultipartUpload(bucketName, "multipart", map[string]string{amzStorageClass: reducedRedundancyStorageClass})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	pInfo, err := obj.PutObjectPart(bucketName, "multipart", uploadID, 1, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	multipartInfo, err := obj.CompleteMultipartUpload(bucketName, "multipart", uploadID, []CompletePart{{PartNumber: pInfo.PartNumber, ETag: pInfo.ETag}})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	testCases := []struct {
+		eventType            EventName
+		objInfo              ObjectInfo
+		expectedStorageClass string
+	}{
+		{ObjectCreatedPut, putInfo, reducedRedundancyStorageClass},
+		{ObjectCreatedPut, defaultInfo, standardStorageClass},
+		{ObjectCreatedCompleteMultipartUpload, multipartInfo, reducedRedundancyStorageClass},
+		// Storage class is read from the object metadata if not set.
+		{ObjectCreatedCopy, ObjectInfo{Name: "copy", UserDefined: rrsMeta}, reducedRedundancyStorageClass},
+		// Objects without storage class are STANDARD.
+		{ObjectCreatedPost, ObjectInfo{Name: "post"}, standardStorageClass},
+		// Removed objects have no storage class.
+		{ObjectRemovedDelete, ObjectInfo{Name: "put"}, ""},
+	}
+	for i, testCase := range testCases {
+		nEvent := newNotificationEvent(eventData{
+			Type:    testCase.eventType,
+			Bucket:  bucketName,
+			ObjInfo: testCase.objInfo,
+		})
+		if nEvent.S3.Object.StorageClass != testCase.expectedStorageClass {
+			t.Errorf("Test %d: Expected storage class %s, got %s", i+1, testCase.expectedStorageClass, nEvent.S3.Object.StorageClass)
+		}
+	}
+
+	// Storage class transitions carry the storage class before and after the transition.
+	nEvent := newNotificationEvent(eventData{
+		Type:             ObjectTransitionStorageClass,
+		Bucket:           bucketName,
+		ObjInfo:          putInfo,
+		PrevStorageClass: standardStorageClass,
+	})
+	if nEvent.EventName != "s3:ObjectTransition:StorageClass" {
+		t.Errorf("Expected event s3:ObjectTransition:StorageClass, got %s", nEvent.EventName)
+	}
+	if nEvent.S3.Object.StorageClass != reducedRedundancyStorageClass || nEvent.S3.Object.PreviousStorageClass != standardStorageClass {
+		t.Errorf("Expected transition from %s to %s, got %s to %s", standardStorageClass, reducedRedundancyStorageClass,
+			nEvent.S3.Object.PreviousStorageClass, nEvent.S3.Object.StorageClass)
+	}
+	if !eventMatch(nEvent.EventName, []string{"s3:ObjectTransition:*"}) {
+		t.Errorf("Expected %s to match s3:ObjectTransition:*", nEvent.EventName)
+	}
+	if checkEvent(nEvent.EventName) != ErrNone {
+		t.Errorf("Expected %s to be a supported event", nEvent.EventName)
+	}
+}
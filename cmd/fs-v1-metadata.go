@@ -121,7 +121,9 @@ func (m fsMetaV1) ToObjectInfo(bucket, object string, fi os.FileInfo) ObjectInfo
 	// etag/md5Sum has already been extracted. We need to
 	// remove to avoid it from appearing as part of
 	// response headers. e.g, X-Minio-* or X-Amz-*.
-	objInfo.UserDefined = cleanMetadata(m.Meta)
+	// STANDARD storage class is the only storage class
+	// without erasure coding and is returned as saved.
+	objInfo.UserDefined = cleanMetadataKeys(m.Meta, "md5Sum", "etag")
 
 	// Success..
 	return objInfo
@@ -176,8 +176,9 @@ func (fs fsObjects) StorageInfo() StorageInfo {
 	info, err := getDiskInfo((fs.fsPath))
 	errorIf(err, "Unable to get disk info %#v", fs.fsPath)
 	storageInfo := StorageInfo{
-		Total: info.Total,
-		Free:  info.Free,
+		Total:      info.Total,
+		Free:       info.Free,
+		UsableFree: getUsableFreeSpaces(info.Free, 1),
 	}
 	storageInfo.Backend.Type = FS
 	return storageInfo
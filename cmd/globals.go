@@ -21,6 +21,7 @@ import (
 	"crypto/x509"
 	"os"
 	"runtime"
+	"sync"
 	"time"
 
 	humanize "github.com/dustin/go-humanize"
@@ -140,6 +141,12 @@ var (
 	// Global HTTP request statisitics
 	globalHTTPStats = newHTTPStats()
 
+	// Global statistics of objects written per storage class
+	globalStorageClassStats = newStorageClassStats()
+
+	// Global quorum margin of objects read, nil if the stats are disabled
+	globalQuorumMarginStats *QuorumMarginStats
+
 	// Time when object layer was initialized on start up.
 	globalBootTime time.Time
 
@@ -165,6 +172,40 @@ var (
 	globalRRStorageClass storageClass
 	// Set to store standard storage class
 	globalStandardStorageClass storageClass
+	// Set to store max durability storage class
+	globalMaxStorageClass storageClass
+	// Guards the storage classes above, as they are swapped when the storage classes are reloaded
+	globalStorageClassMu sync.RWMutex
+	// Channel to receive signals to reload the storage classes
+	globalStorageClassReloadCh = make(chan os.Signal, 1)
+	// Set to store storage class aliases, maps foreign S3 storage classes to a supported storage class
+	globalStorageClassAliases map[string]string
+	// Set to allow reduced redundancy storage class on 4 disks setup
+	globalStorageClassAllowSmall bool
+	// Set to suppress storage class startup messages
+	globalStorageClassQuiet bool
+	// Set to reject odd parity disks for storage classes
+	globalStorageClassRequireEvenParity bool
+	// Parity of reduced redundancy storage class when it is not set
+	globalRRSDefaultParity = defaultRRSParity
+	// Cap of the default N/2 parity of standard storage class, 0 if not capped
+	globalStorageClassMaxParity int
+	// Set to print storage class validation errors as JSON
+	globalStorageClassJSONErrors bool
+	// Set to audit log the storage class applied to writes
+	globalStorageClassAudit bool
+	// Set to reject storage classes set differently in environment and config file
+	globalStorageClassStrict bool
+	// Set to clamp storage class parity exceeding N/2 of the disks instead of failing writes
+	globalStorageClassClampParity bool
+	// Set to store default storage class of buckets matching a pattern, the first matching rule applies
+	globalBucketStorageClassRules []bucketStorageClassRule
+	// Set to store the size per storage class below which objects are written with minimum parity
+	globalSmallObjectParityThresholds map[string]int64
+	// Set to re-encode healed objects with the current parity of their storage class
+	globalStorageClassHealReparity bool
+	// Set to reject Reduced redundancy storage class as a bucket default or bucket storage class rule
+	globalStorageClassDenyRRSDefault bool
 
 	// Add new variable global values here.
 )
@@ -129,6 +129,19 @@ func extractMetadataFromHeader(header http.Header) (map[string]string, error) {
 		}
 	}
 
+	// Save the storage class an alias maps to, so that the object
+	// is always reported with a supported storage class.
+	if sc, ok := metadata[amzStorageClass]; ok {
+		metadata[amzStorageClass] = getStorageClassFromAlias(sc)
+	}
+
+	// Save the parity forced for the object.
+	setForceParity(header, metadata)
+
+	// Save whether a storage class downgrade is forced, it is
+	// removed by the object layer before the object is saved.
+	setForceDowngrade(header, metadata)
+
 	// Go through all other headers for any additional headers that needs to be saved.
 	for key := range header {
 		if key != http.CanonicalHeaderKey(key) {
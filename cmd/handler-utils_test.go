@@ -160,6 +160,28 @@ func TestExtractMetadataHeaders(t *testing.T) {
 			},
 			shouldFail: false,
 		},
+		// Storage class is saved in its canonical form.
+		{
+			header: http.Header{
+				"X-Amz-Storage-Class": []string{"reduced_redundancy"},
+			},
+			metadata: map[string]string{
+				"x-amz-storage-class": "REDUCED_REDUNDANCY",
+			},
+			shouldFail: false,
+		},
+		// Forced parity and downgrade are only saved for XL.
+		{
+			header: http.Header{
+				"X-Minio-Force-Parity":    []string{"max"},
+				"X-Minio-Force-Downgrade": []string{"true"},
+				"X-Amz-Meta-Minio-Parity": []string{"4"},
+			},
+			metadata: map[string]string{
+				"X-Amz-Meta-Minio-Parity": "4",
+			},
+			shouldFail: false,
+		},
 		// Fail if header key is not in canonicalized form
 		{
 			header: http.Header{
@@ -194,3 +194,164 @@ func (st *HTTPStats) updateStats(r *http.Request, w *httpResponseRecorder, durat
 func newHTTPStats() *HTTPStats {
 	return &HTTPStats{}
 }
+
+// StorageClassCounter holds the number of objects and
+// bytes written with a given storage class, and the bytes
+// freed on disks by deleting objects of the storage class
+type StorageClassCounter struct {
+	Objects    atomic.Uint64
+	Bytes      atomic.Uint64
+	FreedBytes atomic.Uint64
+}
+
+// Decrements the counter by n, the counter doesn't go below zero
+// as objects written before the server started are not accounted.
+func decCounter(counter *atomic.Uint64, n uint64) {
+	for {
+		old := counter.Load()
+		if n > old {
+			n = old
+		}
+		if counter.CAS(old, old-n) {
+			return
+		}
+	}
+}
+
+// StorageClassStats holds statistics information about
+// objects written per storage class
+type StorageClassStats struct {
+	standard      StorageClassCounter
+	rrs           StorageClassCounter
+	maxDurability StorageClassCounter
+	scratch       StorageClassCounter
+}
+
+// Update statistics for an object of given size written with the storage
+// class sc, sc is the storage class saved in the object metadata so any
+// unknown or empty storage class is accounted as Standard storage class.
+func (st *StorageClassStats) updateStats(sc string, size int64) {
+	counter := st.getCounter(sc)
+	counter.Objects.Inc()
+	if size > 0 {
+		counter.Bytes.Add(uint64(size))
+	}
+}
+
+// Update statistics for an object of given size overwritten, the object and
+// its bytes are no longer accounted against its storage class sc like
+// updateStats. The object overwriting it is accounted by updateStats.
+func (st *StorageClassStats) overwriteStats(sc string, size int64) {
+	counter := st.getCounter(sc)
+	decCounter(&counter.Objects, 1)
+	if size > 0 {
+		decCounter(&counter.Bytes, uint64(size))
+	}
+}
+
+// Update statistics for an object of given size deleted like overwriteStats.
+// rawSize is the bytes the object took across all the disks, parity included,
+// which are accounted as freed by the storage class.
+func (st *StorageClassStats) deleteStats(sc string, size, rawSize int64) {
+	st.overwriteStats(sc, size)
+	if rawSize > 0 {
+		st.getCounter(sc).FreedBytes.Add(uint64(rawSize))
+	}
+}
+
+// Returns the counter of the storage class sc, any unknown
+// or empty storage class is accounted as Standard storage class.
+func (st *StorageClassStats) getCounter(sc string) *StorageClassCounter {
+	switch sc {
+	case reducedRedundancyStorageClass:
+		return &st.rrs
+	case maxDurabilityStorageClass:
+		return &st.maxDurability
+	case scratchStorageClass:
+		return &st.scratch
+	}
+	return &st.standard
+}
+
+// Converts storage class stats into struct to be sent back to the client.
+func (st *StorageClassStats) toServerStorageClassStats() ServerStorageClassStats {
+	return ServerStorageClassStats{
+		Standard: ServerStorageClassCounter{
+			Objects:    st.standard.Objects.Load(),
+			Bytes:      st.standard.Bytes.Load(),
+			FreedBytes: st.standard.FreedBytes.Load(),
+		},
+		RRS: ServerStorageClassCounter{
+			Objects:    st.rrs.Objects.Load(),
+			Bytes:      st.rrs.Bytes.Load(),
+			FreedBytes: st.rrs.FreedBytes.Load(),
+		},
+		MaxDurability: ServerStorageClassCounter{
+			Objects:    st.maxDurability.Objects.Load(),
+			Bytes:      st.maxDurability.Bytes.Load(),
+			FreedBytes: st.maxDurability.FreedBytes.Load(),
+		},
+		Scratch: ServerStorageClassCounter{
+			Objects:    st.scratch.Objects.Load(),
+			Bytes:      st.scratch.Bytes.Load(),
+			FreedBytes: st.scratch.FreedBytes.Load(),
+		},
+	}
+}
+
+// Prepare new StorageClassStats structure
+func newStorageClassStats() *StorageClassStats {
+	return &StorageClassStats{}
+}
+
+// Number of quorum margins accounted individually, reads with a
+// larger margin are accounted in the last margin.
+const quorumMarginBuckets = 9
+
+// QuorumMarginStats holds a histogram of the quorum margin of objects
+// read, i.e. the number of valid xl.json(s) found above the data blocks
+// needed for read quorum. A margin shrinking over time is an early warning
+// of quorum loss.
+type QuorumMarginStats struct {
+	// Reads which lost read quorum, i.e. with a negative margin.
+	lost    atomic.Uint64
+	margins [quorumMarginBuckets]atomic.Uint64
+}
+
+// Accounts the quorum margin of an object read, objects not found
+// are not accounted. A nil QuorumMarginStats accounts nothing, so
+// that reads have no overhead when the stats are disabled.
+func (st *QuorumMarginStats) updateStats(qInfo objectQuorumInfo) {
+	if st == nil || qInfo.LatestIndex == -1 {
+		return
+	}
+	margin := qInfo.Margin()
+	switch {
+	case margin < 0:
+		st.lost.Inc()
+	case margin >= quorumMarginBuckets:
+		st.margins[quorumMarginBuckets-1].Inc()
+	default:
+		st.margins[margin].Inc()
+	}
+}
+
+// Converts quorum margin stats into struct to be sent back to the client.
+func (st *QuorumMarginStats) toServerQuorumMarginStats() ServerQuorumMarginStats {
+	if st == nil {
+		return ServerQuorumMarginStats{}
+	}
+	stats := ServerQuorumMarginStats{
+		Lost:    st.lost.Load(),
+		Margins: make([]uint64, quorumMarginBuckets),
+	}
+	for i := range st.margins {
+		stats.Margins[i] = st.margins[i].Load()
+	}
+	return stats
+}
+
+// Prepare new QuorumMarginStats structure
+func newQuorumMarginStats() *QuorumMarginStats {
+	return &QuorumMarginStats{}
+}
@@ -0,0 +1,119 @@
+/*
+ * Minio Cloud Storage, (C) 2017 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Tests storage class stats are accounted per storage class.
+func TestStorageClassStats(t *testing.T) {
+	st := newStorageClassStats()
+	st.updateStats("", 10)
+	st.updateStats(standardStorageClass, 20)
+	st.updateStats(reducedRedundancyStorageClass, 30)
+	st.updateStats(maxDurabilityStorageClass, 40)
+	st.updateStats(scratchStorageClass, 60)
+	// Unknown storage class is accounted as Standard storage class.
+	st.updateStats("GLACIER", 50)
+	// Size unknown, only the object is accounted.
+	st.updateStats(reducedRedundancyStorageClass, -1)
+
+	expected := ServerStorageClassStats{
+		Standard:      ServerStorageClassCounter{Objects: 3, Bytes: 80},
+		RRS:           ServerStorageClassCounter{Objects: 2, Bytes: 30},
+		MaxDurability: ServerStorageClassCounter{Objects: 1, Bytes: 40},
+		Scratch:       ServerStorageClassCounter{Objects: 1, Bytes: 60},
+	}
+	if got := st.toServerStorageClassStats(); got != expected {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+}
+
+// Tests deleted objects are no longer accounted and the raw bytes
+// freed are accounted per storage class.
+func TestStorageClassStatsDelete(t *testing.T) {
+	st := newStorageClassStats()
+	st.updateStats(standardStorageClass, 20)
+	st.updateStats(reducedRedundancyStorageClass, 30)
+	st.updateStats(reducedRedundancyStorageClass, 40)
+
+	st.deleteStats(reducedRedundancyStorageClass, 30, 35)
+	// Missing storage class is accounted as Standard storage class.
+	st.deleteStats("", 20, 40)
+	// Objects written before the server started are not accounted,
+	// counters don't go below zero.
+	st.deleteStats(maxDurabilityStorageClass, 10, 20)
+
+	expected := ServerStorageClassStats{
+		Standard:      ServerStorageClassCounter{Objects: 0, Bytes: 0, FreedBytes: 40},
+		RRS:           ServerStorageClassCounter{Objects: 1, Bytes: 40, FreedBytes: 35},
+		MaxDurability: ServerStorageClassCounter{Objects: 0, Bytes: 0, FreedBytes: 20},
+	}
+	if got := st.toServerStorageClassStats(); got != expected {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+// Tests overwritten objects are no longer accounted and no bytes
+// are accounted as freed.
+func TestStorageClassStatsOverwrite(t *testing.T) {
+	st := newStorageClassStats()
+	st.updateStats(standardStorageClass, 20)
+	st.updateStats(reducedRedundancyStorageClass, 30)
+
+	st.overwriteStats(standardStorageClass, 20)
+	st.updateStats(reducedRedundancyStorageClass, 40)
+
+	expected := ServerStorageClassStats{
+		RRS: ServerStorageClassCounter{Objects: 2, Bytes: 70},
+	}
+	if got := st.toServerStorageClassStats(); got != expected {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+// Tests quorum margin of objects read is accounted per margin.
+func TestQuorumMarginStats(t *testing.T) {
+	// Disabled stats account nothing.
+	var disabled *QuorumMarginStats
+	disabled.updateStats(objectQuorumInfo{Available: 16, Required: 8})
+	if got := disabled.toServerQuorumMarginStats(); got.Lost != 0 || got.Margins != nil {
+		t.Errorf("Expected empty stats, got %v", got)
+	}
+
+	st := newQuorumMarginStats()
+	for _, qInfo := range []objectQuorumInfo{
+		{Available: 8, Required: 8},
+		{Available: 9, Required: 8},
+		{Available: 9, Required: 8},
+		// Margins above the last margin are accounted in the last margin.
+		{Available: 16, Required: 4},
+		{Available: 12, Required: 2},
+		{Available: 7, Required: 8},
+		// Objects not found are not accounted.
+		{LatestIndex: -1},
+	} {
+		st.updateStats(qInfo)
+	}
+	expected := ServerQuorumMarginStats{Lost: 1, Margins: []uint64{1, 2, 0, 0, 0, 0, 0, 0, 2}}
+	if got := st.toServerQuorumMarginStats(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
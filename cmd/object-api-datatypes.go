@@ -37,6 +37,12 @@ type StorageInfo struct {
 	Total uint64
 	// Free available disk space.
 	Free uint64
+	// Free disk space usable for object data per storage class,
+	// i.e. free disk space without erasure coding parity.
+	UsableFree map[string]uint64
+	// Data and parity disks each supported storage class resolves
+	// to, only meaningful if BackendType is Erasure.
+	StorageClasses map[string]ServerStorageClassLayout
 	// Backend type.
 	Backend struct {
 		// Represents various backend types, currently on FS and Erasure.
@@ -82,6 +88,9 @@ type HealObjectInfo struct {
 	Status             healStatus
 	MissingDataCount   int
 	MissingParityCount int
+	// Higher is more urgent, objects with the highest priority
+	// are one disk failure away from being unreadable.
+	HealPriority int
 }
 
 // ObjectInfo - represents object metadata.
@@ -112,6 +121,10 @@ type ObjectInfo struct {
 	// by the Content-Type header field.
 	ContentEncoding string
 
+	// Specifies the storage class of the object, empty when the
+	// object layer does not support storage classes.
+	StorageClass string
+
 	// User-Defined metadata
 	UserDefined    map[string]string
 	HealObjectInfo *HealObjectInfo `xml:"HealObjectInfo,omitempty"`
@@ -32,6 +32,10 @@ func toObjectErr(err error, params ...string) error {
 		err = e.Cause
 	}
 
+	if isErrXLReadQuorum(err) {
+		err = errXLReadQuorum
+	}
+
 	switch err {
 	case errVolumeNotFound:
 		if len(params) >= 1 {
@@ -117,6 +121,46 @@ func (e StorageFull) Error() string {
 	return "Storage reached its minimum free disk threshold."
 }
 
+// StorageClassQuotaExceeded - bucket quota of the storage class would be
+// exceeded by the object.
+type StorageClassQuotaExceeded struct {
+	Bucket       string
+	StorageClass string
+	Quota        uint64
+}
+
+func (e StorageClassQuotaExceeded) Error() string {
+	return fmt.Sprintf("Bucket %s exceeds its quota of %d bytes for storage class %s", e.Bucket, e.Quota, e.StorageClass)
+}
+
+// StorageClassWriteQuorum - fewer disks are online than the write
+// quorum of the storage class the object is written with.
+type StorageClassWriteQuorum struct {
+	StorageClass string
+	WriteQuorum  int
+	OnlineDisks  int
+}
+
+func (e StorageClassWriteQuorum) Error() string {
+	return fmt.Sprintf("Storage class %s needs %d disks online to meet write quorum, only %d disks are online", e.StorageClass, e.WriteQuorum, e.OnlineDisks)
+}
+
+// StorageClassDowngrade - object would be overwritten with fewer parity
+// disks in a bucket with storage class downgrade protection.
+type StorageClassDowngrade struct {
+	Bucket     string
+	Object     string
+	From       string
+	FromParity int
+	To         string
+	ToParity   int
+}
+
+func (e StorageClassDowngrade) Error() string {
+	return fmt.Sprintf("Object %s/%s with storage class %s and %d parity disks can't be overwritten with storage class %s and %d parity disks",
+		e.Bucket, e.Object, e.From, e.FromParity, e.To, e.ToParity)
+}
+
 // InsufficientReadQuorum storage cannot satisfy quorum for read operation.
 type InsufficientReadQuorum struct{}
 
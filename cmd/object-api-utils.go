@@ -198,7 +198,7 @@ func cleanMetadata(metadata map[string]string) map[string]string {
 // Filter X-Amz-Storage-Class field only if it is set to STANDARD.
 // This is done since AWS S3 doesn't return STANDARD Storage class as response header.
 func removeStandardStorageClass(metadata map[string]string) map[string]string {
-	if metadata[amzStorageClass] == standardStorageClass {
+	if sc, ok := metadata[amzStorageClass]; ok && getStorageClassFromAlias(sc) == standardStorageClass {
 		delete(metadata, amzStorageClass)
 	}
 	return metadata
@@ -222,6 +222,11 @@ func TestRemoveStandardStorageClass(t *testing.T) {
 			metadata: map[string]string{"content-type": "application/octet-stream", "etag": "de75a98baf2c6aef435b57dd0fc33c86"},
 			want:     map[string]string{"content-type": "application/octet-stream", "etag": "de75a98baf2c6aef435b57dd0fc33c86"},
 		},
+		{
+			name:     "4",
+			metadata: map[string]string{"content-type": "application/octet-stream", "etag": "de75a98baf2c6aef435b57dd0fc33c86", "x-amz-storage-class": "standard"},
+			want:     map[string]string{"content-type": "application/octet-stream", "etag": "de75a98baf2c6aef435b57dd0fc33c86"},
+		},
 	}
 	for _, tt := range tests {
 		if got := removeStandardStorageClass(tt.metadata); !reflect.DeepEqual(got, tt.want) {
@@ -307,6 +307,17 @@ func getCpObjMetadataFromHeader(header http.Header, defaultMeta map[string]strin
 		return extractMetadataFromHeader(header)
 	}
 
+	// Storage class in the request overrides the storage class of the
+	// source object, otherwise the source storage class is retained.
+	if _, ok := header[amzStorageClassCanonical]; ok {
+		meta := make(map[string]string, len(defaultMeta)+1)
+		for k, v := range defaultMeta {
+			meta[k] = v
+		}
+		setCopyStorageClass(header, meta, getStorageClassFromAlias(header.Get(amzStorageClassCanonical)))
+		return meta, nil
+	}
+
 	// if x-amz-metadata-directive says COPY then we
 	// return the default metadata.
 	if isMetadataCopy(header) {
@@ -359,6 +370,14 @@ func (api objectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	// Validate storage class metadata if present
+	if _, ok := r.Header[amzStorageClassCanonical]; ok {
+		if s3Err := checkStorageClassHeader(objectAPI, r.Header.Get(amzStorageClassCanonical)); s3Err != ErrNone {
+			writeErrorResponse(w, s3Err, r.URL)
+			return
+		}
+	}
+
 	if IsSSECustomerRequest(r.Header) { // handle SSE-C requests
 		// SSE-C is not implemented for CopyObject operations yet
 		writeErrorResponse(w, ErrNotImplemented, r.URL)
@@ -415,12 +434,15 @@ func (api objectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 		writeErrorResponse(w, ErrInternalError, r.URL)
 		return
 	}
+	setForceDowngrade(r.Header, newMetadata)
 
 	// Check if x-amz-metadata-directive was not set to REPLACE and source,
 	// desination are same objects.
-	if !isMetadataReplace(r.Header) && cpSrcDstSame {
-		// If x-amz-metadata-directive is not set to REPLACE then we need
-		// to error out if source and destination are same.
+	_, scChange := r.Header[amzStorageClassCanonical]
+	if !isMetadataReplace(r.Header) && !scChange && cpSrcDstSame {
+		// If x-amz-metadata-directive is not set to REPLACE and storage
+		// class is not changed then we need to error out if source and
+		// destination are same.
 		writeErrorResponse(w, ErrInvalidCopyDest, r.URL)
 		return
 	}
@@ -479,8 +501,8 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 
 	// Validate storage class metadata if present
 	if _, ok := r.Header[amzStorageClassCanonical]; ok {
-		if !isValidStorageClassMeta(r.Header.Get(amzStorageClassCanonical)) {
-			writeErrorResponse(w, ErrInvalidStorageClass, r.URL)
+		if s3Err := checkStorageClassHeader(objectAPI, r.Header.Get(amzStorageClassCanonical)); s3Err != ErrNone {
+			writeErrorResponse(w, s3Err, r.URL)
 			return
 		}
 	}
@@ -543,6 +565,21 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 		}
 	}
 
+	// Reject large uploads early if the erasure expanded object doesn't
+	// fit in the free space left on the disks, as fetched last. The object
+	// is sized with the data disks it would be written with, invalid
+	// forced parity is left to the object layer to reject.
+	if globalIsXL && size > blockSizeV1 {
+		if info, ok := globalFreeSpace.Get(objectAPI); ok {
+			totalDisks := info.Backend.OnlineDisks + info.Backend.OfflineDisks
+			redundancy, rErr := getObjectRedundancyInfo(bucket, metadata, totalDisks, size)
+			if rErr == nil && !hasCapacityForObject(info, size, redundancy.Data) {
+				writeErrorResponse(w, ErrStorageFull, r.URL)
+				return
+			}
+		}
+	}
+
 	// Lock the object.
 	objectLock := globalNSMutex.NewNSLock(bucket, object)
 	if objectLock.GetLock(globalObjectTimeout) != nil {
@@ -648,6 +685,80 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 	})
 }
 
+// PutObjectDryRunHandler - PUT Object with dryRun=true
+// ----------
+// This implementation returns the erasure layout an object of the size given
+// in the size argument would be written with by PUT Object, nothing is written.
+// Storage class is taken from the storageClass argument or x-amz-storage-class.
+func (api objectAPIHandlers) PutObjectDryRunHandler(w http.ResponseWriter, r *http.Request) {
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, ErrServerNotInitialized, r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+
+	if s3Error := checkRequestAuthType(r, bucket, "s3:PutObject", globalServerConfig.GetRegion()); s3Error != ErrNone {
+		writeErrorResponse(w, s3Error, r.URL)
+		return
+	}
+
+	// Object size is passed as an argument, the object itself is not sent.
+	if r.ContentLength > 0 {
+		writeErrorResponse(w, ErrInvalidRequestBody, r.URL)
+		return
+	}
+	size, err := strconv.ParseInt(r.URL.Query().Get("size"), 10, 64)
+	if err != nil || size < 0 || isMaxObjectSize(size) {
+		writeErrorResponse(w, ErrInvalidDryRunSize, r.URL)
+		return
+	}
+
+	if err = checkPutObjectArgs(bucket, object, objectAPI); err != nil {
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	if sc := r.URL.Query().Get("storageClass"); sc != "" {
+		r.Header.Set(amzStorageClassCanonical, sc)
+	}
+	if _, ok := r.Header[amzStorageClassCanonical]; ok {
+		if s3Err := checkStorageClassHeader(objectAPI, r.Header.Get(amzStorageClassCanonical)); s3Err != ErrNone {
+			writeErrorResponse(w, s3Err, r.URL)
+			return
+		}
+	}
+
+	// Erasure layout is only available in erasure coding mode.
+	xl, ok := objectAPI.(*xlObjects)
+	if !ok {
+		writeErrorResponse(w, ErrNotImplemented, r.URL)
+		return
+	}
+
+	metadata, err := extractMetadataFromHeader(r.Header)
+	if err != nil {
+		errorIf(err, "found invalid http request header")
+		writeErrorResponse(w, ErrInternalError, r.URL)
+		return
+	}
+
+	layout, err := planObjectLayout(bucket, metadata, size, xl.setDriveCount())
+	if err != nil {
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	response := generatePutObjectDryRunResponse(layout, size)
+	encodedSuccessResponse := encodeResponse(response)
+
+	// Write success response.
+	writeSuccessResponseXML(w, encodedSuccessResponse)
+}
+
 /// Multipart objectAPIHandlers
 
 // NewMultipartUploadHandler - New multipart upload.
@@ -670,8 +781,8 @@ func (api objectAPIHandlers) NewMultipartUploadHandler(w http.ResponseWriter, r
 
 	// Validate storage class metadata if present
 	if _, ok := r.Header[amzStorageClassCanonical]; ok {
-		if !isValidStorageClassMeta(r.Header.Get(amzStorageClassCanonical)) {
-			writeErrorResponse(w, ErrInvalidStorageClass, r.URL)
+		if s3Err := checkStorageClassHeader(objectAPI, r.Header.Get(amzStorageClassCanonical)); s3Err != ErrNone {
+			writeErrorResponse(w, s3Err, r.URL)
 			return
 		}
 	}
@@ -1073,6 +1184,26 @@ func (api objectAPIHandlers) CompleteMultipartUploadHandler(w http.ResponseWrite
 		completeParts = append(completeParts, part)
 	}
 
+	// Storage class of the object is set when the multipart upload is initiated,
+	// a storage class sent on completion should be the same.
+	if _, ok := r.Header[amzStorageClassCanonical]; ok {
+		sc := r.Header.Get(amzStorageClassCanonical)
+		if s3Err := checkStorageClassHeader(objectAPI, sc); s3Err != ErrNone {
+			writeErrorResponse(w, s3Err, r.URL)
+			return
+		}
+		partsInfo, err := objectAPI.ListObjectParts(bucket, object, uploadID, 0, 0)
+		if err != nil {
+			writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+			return
+		}
+		// Backends without storage class support don't report the storage class of an upload.
+		if partsInfo.StorageClass != "" && partsInfo.StorageClass != getStorageClassFromAlias(sc) {
+			writeErrorResponse(w, ErrStorageClassMismatch, r.URL)
+			return
+		}
+	}
+
 	// Hold write lock on the object.
 	destLock := globalNSMutex.NewNSLock(bucket, object)
 	if destLock.GetLock(globalObjectTimeout) != nil {
@@ -1029,6 +1029,295 @@ func testAPIPutObjectHandler(obj ObjectLayer, instanceType, bucketName string, a
 
 }
 
+// Wrapper for calling PutObjectDryRun HTTP handler tests for both XL multiple disks and single node setup.
+func TestAPIPutObjectDryRunHandler(t *testing.T) {
+	defer DetectTestLeak(t)()
+	ExecObjectLayerAPITest(t, testAPIPutObjectDryRunHandler, []string{"PutObjectDryRun"})
+}
+
+func testAPIPutObjectDryRunHandler(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials auth.Credentials, t *testing.T) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	// Forced parity is only extracted from the headers for XL.
+	globalIsXL = instanceType == XLTestStr
+	defer func() { globalIsXL = false }()
+
+	objectName := "test-object-dry-run"
+	testCases := []struct {
+		size               string
+		storageClass       string
+		header             http.Header
+		body               []byte
+		expectedRespStatus int
+		expectedResponse   PutObjectDryRunResponse
+	}{
+		// Test case - 1.
+		// Object without storage class is laid out with N/2 parity.
+		{"1024", "", nil, nil, http.StatusOK,
+			PutObjectDryRunResponse{StorageClass: standardStorageClass, Size: 1024, DataBlocks: 8, ParityBlocks: 8, ShardSize: 128, WriteQuorum: 9}},
+		// Test case - 2.
+		{strconv.FormatInt(blockSizeV1+1, 10), reducedRedundancyStorageClass, nil, nil, http.StatusOK,
+			PutObjectDryRunResponse{StorageClass: reducedRedundancyStorageClass, Size: blockSizeV1 + 1, DataBlocks: 14, ParityBlocks: 2,
+				ShardSize: getChunkSize(blockSizeV1, 14) + 1, WriteQuorum: 15}},
+		// Test case - 3.
+		// Storage class header is used if storage class is not passed as an argument.
+		{"0", "", http.Header{amzStorageClassCanonical: []string{reducedRedundancyStorageClass}}, nil, http.StatusOK,
+			PutObjectDryRunResponse{StorageClass: reducedRedundancyStorageClass, DataBlocks: 14, ParityBlocks: 2, WriteQuorum: 15}},
+		// Test case - 4.
+		// Forced parity takes precedence over storage class.
+		{"1024", standardStorageClass, http.Header{amzForceParity: []string{"4"}}, nil, http.StatusOK,
+			PutObjectDryRunResponse{StorageClass: standardStorageClass, Size: 1024, DataBlocks: 12, ParityBlocks: 4, ShardSize: 86, WriteQuorum: 13}},
+		// Test case - 5.
+		// Object itself must not be sent.
+		{"6", "", nil, []byte("abcdef"), http.StatusBadRequest, PutObjectDryRunResponse{}},
+		// Test case - 6-8.
+		{"", "", nil, nil, http.StatusBadRequest, PutObjectDryRunResponse{}},
+		{"-1", "", nil, nil, http.StatusBadRequest, PutObjectDryRunResponse{}},
+		{"abc", "", nil, nil, http.StatusBadRequest, PutObjectDryRunResponse{}},
+		// Test case - 9.
+		{"1024", "INVALID", nil, nil, http.StatusBadRequest, PutObjectDryRunResponse{}},
+	}
+	for i, testCase := range testCases {
+		queryValues := url.Values{}
+		queryValues.Set("dryRun", "true")
+		queryValues.Set("size", testCase.size)
+		if testCase.storageClass != "" {
+			queryValues.Set("storageClass", testCase.storageClass)
+		}
+		req, err := newTestSignedRequestV4("PUT", makeTestTargetURL("", bucketName, objectName, queryValues),
+			int64(len(testCase.body)), bytes.NewReader(testCase.body), credentials.AccessKey, credentials.SecretKey)
+		if err != nil {
+			t.Fatalf("Test %d: Failed to create HTTP request for PutObjectDryRun: <ERROR> %v", i+1, err)
+		}
+		for key, values := range testCase.header {
+			req.Header[key] = values
+		}
+
+		rec := httptest.NewRecorder()
+		apiRouter.ServeHTTP(rec, req)
+
+		expectedRespStatus := testCase.expectedRespStatus
+		// Erasure layout is not available in FS mode, which only supports STANDARD storage class.
+		if instanceType == FSTestStr && expectedRespStatus == http.StatusOK {
+			expectedRespStatus = http.StatusNotImplemented
+			if testCase.expectedResponse.StorageClass == reducedRedundancyStorageClass {
+				expectedRespStatus = http.StatusBadRequest
+			}
+		}
+		if rec.Code != expectedRespStatus {
+			t.Errorf("Test %d: Minio %s: Expected the response status to be `%d`, but instead found `%d`", i+1, instanceType, expectedRespStatus, rec.Code)
+			continue
+		}
+
+		// Nothing is written by a dry run.
+		if _, err = obj.GetObjectInfo(bucketName, objectName); !isErrObjectNotFound(err) {
+			t.Errorf("Test %d: Minio %s: Expected object not to be written, got %v", i+1, instanceType, err)
+		}
+		if rec.Code != http.StatusOK {
+			continue
+		}
+
+		var response PutObjectDryRunResponse
+		if err = xml.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Test %d: Minio %s: Unable to parse response %v", i+1, instanceType, err)
+		}
+		response.XMLName = xml.Name{}
+		if response != testCase.expectedResponse {
+			t.Errorf("Test %d: Minio %s: Expected %v, got %v", i+1, instanceType, testCase.expectedResponse, response)
+		}
+	}
+
+	// Dry run layout matches the layout of the object written.
+	if instanceType != XLTestStr {
+		return
+	}
+	size := int64(blockSizeV1 + 1)
+	layout, err := planObjectLayout(bucketName, map[string]string{amzStorageClass: reducedRedundancyStorageClass}, size, 16)
+	if err != nil {
+		t.Fatalf("Unable to plan object layout %v", err)
+	}
+	data := bytes.Repeat([]byte("a"), int(size))
+	metadata := map[string]string{amzStorageClass: reducedRedundancyStorageClass}
+	if _, err = obj.PutObject(bucketName, objectName, mustGetHashReader(t, bytes.NewReader(data), size, "", ""), metadata); err != nil {
+		t.Fatalf("Unable to put object %v", err)
+	}
+	xl := obj.(*xlObjects)
+	xlMeta, err := readXLMeta(xl.storageDisks[0], bucketName, objectName)
+	if err != nil {
+		t.Fatalf("Unable to read xl.json %v", err)
+	}
+	if xlMeta.Erasure.DataBlocks != layout.DataBlocks || xlMeta.Erasure.ParityBlocks != layout.ParityBlocks {
+		t.Errorf("Expected %d data and %d parity blocks, got %d and %d", layout.DataBlocks, layout.ParityBlocks,
+			xlMeta.Erasure.DataBlocks, xlMeta.Erasure.ParityBlocks)
+	}
+	fi, err := xl.storageDisks[0].StatFile(bucketName, pathJoin(objectName, "part.1"))
+	if err != nil {
+		t.Fatalf("Unable to stat part %v", err)
+	}
+	if fi.Size != layout.ShardSize {
+		t.Errorf("Expected shard size %d, got %d", layout.ShardSize, fi.Size)
+	}
+}
+
+// Tests the storage class an object is written with is returned in the
+// x-amz-storage-class header of HEAD and GET.
+func TestAPIObjectStorageClassHeader(t *testing.T) {
+	defer DetectTestLeak(t)()
+	ExecObjectLayerAPITest(t, testAPIObjectStorageClassHeader, []string{"PutObject", "HeadObject", "GetObject"})
+}
+
+func testAPIObjectStorageClassHeader(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials auth.Credentials, t *testing.T) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+
+	// register event notifier.
+	if err := initEventNotifier(obj); err != nil {
+		t.Fatal("Notifier initialization failed.")
+	}
+
+	data := []byte("hello")
+	testCases := []struct {
+		storageClass  string
+		expectedClass string
+	}{
+		// Test case - 1.
+		// STANDARD storage class is not returned.
+		{"", ""},
+		// Test case - 2.
+		{standardStorageClass, ""},
+		// Test case - 3.
+		{reducedRedundancyStorageClass, reducedRedundancyStorageClass},
+		// Test case - 4.
+		// Storage class is returned as saved, irrespective of the case written.
+		{"reduced_redundancy", reducedRedundancyStorageClass},
+	}
+	for i, testCase := range testCases {
+		// Only STANDARD storage class is supported in FS mode.
+		if instanceType == FSTestStr && testCase.expectedClass != "" {
+			continue
+		}
+		expectedClass := testCase.expectedClass
+		// STANDARD storage class is saved and returned as is by FS.
+		if instanceType == FSTestStr {
+			expectedClass = testCase.storageClass
+		}
+		objectName := fmt.Sprintf("test-object-%d", i+1)
+		req, err := newTestSignedRequestV4("PUT", getPutObjectURL("", bucketName, objectName),
+			int64(len(data)), bytes.NewReader(data), credentials.AccessKey, credentials.SecretKey)
+		if err != nil {
+			t.Fatalf("Test %d: Failed to create HTTP request for PutObject: <ERROR> %v", i+1, err)
+		}
+		if testCase.storageClass != "" {
+			req.Header.Set(amzStorageClass, testCase.storageClass)
+		}
+		rec := httptest.NewRecorder()
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Test %d: Minio %s: Expected the response status to be `%d`, but instead found `%d`", i+1, instanceType, http.StatusOK, rec.Code)
+		}
+
+		for _, method := range []string{"HEAD", "GET"} {
+			targetURL := getHeadObjectURL("", bucketName, objectName)
+			if method == "GET" {
+				targetURL = getGetObjectURL("", bucketName, objectName)
+			}
+			req, err = newTestSignedRequestV4(method, targetURL, 0, nil, credentials.AccessKey, credentials.SecretKey)
+			if err != nil {
+				t.Fatalf("Test %d: Failed to create HTTP request for %s: <ERROR> %v", i+1, method, err)
+			}
+			rec = httptest.NewRecorder()
+			apiRouter.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Test %d: Minio %s: %s expected the response status to be `%d`, but instead found `%d`", i+1, instanceType, method, http.StatusOK, rec.Code)
+			}
+			if sc := rec.Header().Get(amzStorageClass); sc != expectedClass {
+				t.Errorf("Test %d: Minio %s: %s expected storage class `%s`, got `%s`", i+1, instanceType, method, expectedClass, sc)
+			}
+		}
+	}
+}
+
+// Tests the storage class and the parity of an object written with SSE-C
+// are the same as of an object written without encryption.
+func TestAPIPutObjectEncryptedStorageClass(t *testing.T) {
+	defer DetectTestLeak(t)()
+	ExecObjectLayerAPITest(t, testAPIPutObjectEncryptedStorageClass, []string{"PutObject", "HeadObject"})
+}
+
+func testAPIPutObjectEncryptedStorageClass(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials auth.Credentials, t *testing.T) {
+	// Only STANDARD storage class is supported in FS mode.
+	if instanceType != XLTestStr {
+		return
+	}
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+	// SSE-C requests are only accepted over TLS.
+	defer func(flag bool) { globalIsSSL = flag }(globalIsSSL)
+	globalIsSSL = true
+
+	// register event notifier.
+	if err := initEventNotifier(obj); err != nil {
+		t.Fatal("Notifier initialization failed.")
+	}
+
+	setSSECustomerHeaders := func(req *http.Request) {
+		req.Header.Set(SSECustomerAlgorithm, SSECustomerAlgorithmAES256)
+		req.Header.Set(SSECustomerKey, "MzJieXRlc2xvbmdzZWNyZXRrZXltdXN0cHJvdmlkZWQ=")
+		req.Header.Set(SSECustomerKeyMD5, "7PpPLAK26ONlVUGOWlusfg==")
+	}
+
+	objectName := "test-object-encrypted"
+	data := bytes.Repeat([]byte("a"), 1024)
+	req, err := newTestSignedRequestV4("PUT", getPutObjectURL("", bucketName, objectName),
+		int64(len(data)), bytes.NewReader(data), credentials.AccessKey, credentials.SecretKey)
+	if err != nil {
+		t.Fatalf("Failed to create HTTP request for PutObject: <ERROR> %v", err)
+	}
+	req.Header.Set(amzStorageClass, reducedRedundancyStorageClass)
+	setSSECustomerHeaders(req)
+	rec := httptest.NewRecorder()
+	apiRouter.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Minio %s: Expected the response status to be `%d`, but instead found `%d`", instanceType, http.StatusOK, rec.Code)
+	}
+
+	req, err = newTestSignedRequestV4("HEAD", getHeadObjectURL("", bucketName, objectName), 0, nil,
+		credentials.AccessKey, credentials.SecretKey)
+	if err != nil {
+		t.Fatalf("Failed to create HTTP request for HeadObject: <ERROR> %v", err)
+	}
+	setSSECustomerHeaders(req)
+	rec = httptest.NewRecorder()
+	apiRouter.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Minio %s: Expected the response status to be `%d`, but instead found `%d`", instanceType, http.StatusOK, rec.Code)
+	}
+	if sc := rec.Header().Get(amzStorageClass); sc != reducedRedundancyStorageClass {
+		t.Errorf("Minio %s: Expected storage class `%s`, got `%s`", instanceType, reducedRedundancyStorageClass, sc)
+	}
+	if size := rec.Header().Get("Content-Length"); size != strconv.Itoa(len(data)) {
+		t.Errorf("Minio %s: Expected content length %d, got %s", instanceType, len(data), size)
+	}
+
+	// Encrypted object is laid out with the parity of its storage class.
+	xlMeta, err := readXLMeta(obj.(*xlObjects).storageDisks[0], bucketName, objectName)
+	if err != nil {
+		t.Fatalf("Unable to read xl.json %v", err)
+	}
+	info := getRedundancyCount(reducedRedundancyStorageClass, 16)
+	if xlMeta.Erasure.DataBlocks != info.Data || xlMeta.Erasure.ParityBlocks != info.Parity {
+		t.Errorf("Expected %d data and %d parity blocks, got %d and %d", info.Data, info.Parity,
+			xlMeta.Erasure.DataBlocks, xlMeta.Erasure.ParityBlocks)
+	}
+	if sc := xlMeta.Meta[amzStorageClass]; sc != reducedRedundancyStorageClass {
+		t.Errorf("Expected storage class %s in xl.json, got %s", reducedRedundancyStorageClass, sc)
+	}
+}
+
 // Tests sanity of attempting to copying each parts at offsets from an existing
 // file and create a new object. Also validates if the written is same as what we
 // expected.
@@ -2456,6 +2745,86 @@ func testAPICompleteMultipartHandler(obj ObjectLayer, instanceType, bucketName s
 }
 
 // The UploadID from the response body is parsed and its existence is asserted with an attempt to ListParts using it.
+// Tests storage class sent on completion of a multipart upload.
+func TestAPICompleteMultipartStorageClass(t *testing.T) {
+	defer DetectTestLeak(t)()
+	ExecObjectLayerAPITest(t, testAPICompleteMultipartStorageClass, []string{"CompleteMultipart"})
+}
+
+func testAPICompleteMultipartStorageClass(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials auth.Credentials, t *testing.T) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+
+	objectName := "test-object-storage-class"
+	data := []byte("abcdef")
+
+	testCases := []struct {
+		storageClass       string
+		expectedRespStatus int
+	}{
+		// Storage class same as the one the upload was initiated with.
+		{reducedRedundancyStorageClass, http.StatusOK},
+		// Storage class different from the one the upload was initiated with.
+		{standardStorageClass, http.StatusBadRequest},
+		{"INVALID", http.StatusBadRequest},
+		// No storage class on completion.
+		{"", http.StatusOK},
+	}
+	for i, testCase := range testCases {
+		uploadID, err := obj.NewMultipartUpload(bucketName, objectName, map[string]string{amzStorageClass: reducedRedundancyStorageClass})
+		if err != nil {
+			t.Fatalf("Minio %s : <ERROR>  %s", instanceType, err)
+		}
+		pInfo, err := obj.PutObjectPart(bucketName, objectName, uploadID, 1, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""))
+		if err != nil {
+			t.Fatalf("Minio %s : <ERROR>  %s", instanceType, err)
+		}
+
+		completeBytes, err := xml.Marshal(&CompleteMultipartUpload{
+			Parts: []CompletePart{{PartNumber: 1, ETag: pInfo.ETag}},
+		})
+		if err != nil {
+			t.Fatalf("Error XML encoding of parts: <ERROR> %s.", err)
+		}
+		req, err := newTestSignedRequestV4("POST", getCompleteMultipartUploadURL("", bucketName, objectName, uploadID),
+			int64(len(completeBytes)), bytes.NewReader(completeBytes), credentials.AccessKey, credentials.SecretKey)
+		if err != nil {
+			t.Fatalf("Failed to create HTTP request for CompleteMultipartUpload: <ERROR> %v", err)
+		}
+		if testCase.storageClass != "" {
+			req.Header.Set(amzStorageClassCanonical, testCase.storageClass)
+		}
+
+		rec := httptest.NewRecorder()
+		apiRouter.ServeHTTP(rec, req)
+
+		expectedRespStatus := testCase.expectedRespStatus
+		// FS only supports STANDARD storage class, which is accepted as is.
+		if instanceType == FSTestStr && testCase.storageClass == standardStorageClass {
+			expectedRespStatus = http.StatusOK
+		}
+		if instanceType == FSTestStr && testCase.storageClass == reducedRedundancyStorageClass {
+			expectedRespStatus = http.StatusBadRequest
+		}
+		if rec.Code != expectedRespStatus {
+			t.Errorf("Test %d: Minio %s: Expected the response status to be `%d`, but instead found `%d`", i+1, instanceType, expectedRespStatus, rec.Code)
+		}
+		if rec.Code != http.StatusOK {
+			continue
+		}
+
+		// Storage class the upload was initiated with is saved with the object.
+		objInfo, err := obj.GetObjectInfo(bucketName, objectName)
+		if err != nil {
+			t.Fatalf("Test %d: Minio %s: Unable to get object info %v", i+1, instanceType, err)
+		}
+		if instanceType == XLTestStr && objInfo.UserDefined[amzStorageClass] != reducedRedundancyStorageClass {
+			t.Errorf("Test %d: Minio %s: Expected storage class %s, got %s", i+1, instanceType, reducedRedundancyStorageClass, objInfo.UserDefined[amzStorageClass])
+		}
+	}
+}
+
 func TestAPIAbortMultipartHandler(t *testing.T) {
 	defer DetectTestLeak(t)()
 	ExecObjectLayerAPITest(t, testAPIAbortMultipartHandler, []string{"AbortMultipart"})
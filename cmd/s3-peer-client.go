@@ -186,3 +186,17 @@ func S3PeersUpdateBucketPolicy(bucket string, pCh policyChange) {
 		)
 	}
 }
+
+// S3PeersUpdateBucketStorageClass - Sends update bucket storage class
+// request to all peers. Currently we log an error and continue.
+func S3PeersUpdateBucketStorageClass(bucket string, scCfg *bucketStorageClassConfig) {
+	setBSCPArgs := &SetBucketStorageClassPeerArgs{Bucket: bucket, SCCfg: scCfg}
+	errs := globalS3Peers.SendUpdate(nil, setBSCPArgs)
+	for idx, err := range errs {
+		errorIf(
+			err,
+			"Error sending update bucket storage class to %s - %v",
+			globalS3Peers[idx].addr, err,
+		)
+	}
+}
@@ -116,3 +116,32 @@ func (s3 *s3PeerAPIHandlers) SetBucketPolicyPeer(args *SetBucketPolicyPeerArgs,
 
 	return s3.bms.UpdateBucketPolicy(args)
 }
+
+// SetBucketStorageClassPeerArgs - Arguments collection for
+// SetBucketStorageClassPeer RPC call
+type SetBucketStorageClassPeerArgs struct {
+	// For Auth
+	AuthRPCArgs
+
+	Bucket string
+
+	// Storage class config for the given bucket, nil
+	// if the config is removed.
+	SCCfg *bucketStorageClassConfig
+}
+
+// BucketUpdate - implements bucket storage class updates,
+// the underlying operation is a network call updates all
+// the peers with the new storage class config.
+func (s *SetBucketStorageClassPeerArgs) BucketUpdate(client BucketMetaState) error {
+	return client.UpdateBucketStorageClass(s)
+}
+
+// tell receiving server to update a bucket storage class
+func (s3 *s3PeerAPIHandlers) SetBucketStorageClassPeer(args *SetBucketStorageClassPeerArgs, reply *AuthRPCReply) error {
+	if err := args.IsAuthenticated(); err != nil {
+		return err
+	}
+
+	return s3.bms.UpdateBucketStorageClass(args)
+}
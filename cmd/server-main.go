@@ -35,6 +35,14 @@ var serverFlags = []cli.Flag{
 		Value: ":" + globalMinioPort,
 		Usage: "Bind to a specific ADDRESS:PORT, ADDRESS can be an IP or hostname.",
 	},
+	cli.BoolFlag{
+		Name:  "validate-only",
+		Usage: "Validate storage class config for the number of disks set with --disks and exit.",
+	},
+	cli.IntFlag{
+		Name:  "disks",
+		Usage: "Number of disks to validate storage class config for, used with --validate-only.",
+	},
 }
 
 var serverCmd = cli.Command{
@@ -82,6 +90,10 @@ EXAMPLES:
       $ export MINIO_SECRET_KEY=miniostorage
       $ {{.HelpName}} http://192.168.1.11/mnt/export/ http://192.168.1.12/mnt/export/ \
           http://192.168.1.13/mnt/export/ http://192.168.1.14/mnt/export/
+
+  5. Validate storage class config for a 16 disks setup without starting the server.
+      $ export MINIO_STORAGE_CLASS_STANDARD=EC:6
+      $ {{.HelpName}} --validate-only --disks 16
 `,
 }
 
@@ -128,6 +140,12 @@ func serverHandleEnvVars() {
 
 // serverMain handler called for 'minio server' command.
 func serverMain(ctx *cli.Context) {
+	// Validate storage class config without starting the server, no PATH is needed.
+	if ctx.Bool("validate-only") {
+		fatalIf(validateStorageClassOnly(ctx.Int("disks"), os.Stdout), "Invalid storage class config.")
+		return
+	}
+
 	if !ctx.Args().Present() || ctx.Args().First() == "help" {
 		cli.ShowCommandHelpAndExit(ctx, "server", 1)
 	}
@@ -209,6 +227,11 @@ func serverMain(ctx *cli.Context) {
 
 	signal.Notify(globalOSSignalCh, os.Interrupt, syscall.SIGTERM)
 
+	// Storage classes can be reloaded with SIGHUP for XL/Dist XL setups.
+	if globalIsXL {
+		signal.Notify(globalStorageClassReloadCh, syscall.SIGHUP)
+	}
+
 	newObject, err := newObjectLayer(globalEndpoints)
 	if err != nil {
 		errorIf(err, "Initializing object layer failed")
@@ -221,6 +244,26 @@ func serverMain(ctx *cli.Context) {
 	globalObjectAPI = newObject
 	globalObjLayerMutex.Unlock()
 
+	// Print a warning message if reduced redundancy is allowed on 4 disks before the startup banner.
+	if isSmallRRSAllowed(len(globalEndpoints)) {
+		log.Println(colorYellow("\n               *** Warning: Reduced redundancy storage class allowed on 4 disks (%s) ***",
+			storageClassAllowSmallEnv))
+	}
+
+	// Print the effective standard storage class parity, warn if N/2 parity is used on a large setup.
+	if globalIsXL && !globalStorageClassQuiet {
+		if msg, warn := getStandardParityStartupMsg(len(globalEndpoints)); warn {
+			log.Println(colorYellow("\n               *** Warning: %s, consider a lower parity with %s (%s=on to suppress) ***",
+				msg, standardStorageClassEnv, storageClassQuietEnv))
+		} else {
+			log.Println(msg)
+		}
+		if msg := getImplicitStandardParityMsg(len(globalEndpoints)); msg != "" {
+			log.Println(colorYellow("\n               *** Warning: %s, set %s=EC:0 to confirm the default parity (%s=on to suppress) ***",
+				msg, standardStorageClassEnv, storageClassQuietEnv))
+		}
+	}
+
 	// Prints the formatted startup message once object layer is initialized.
 	apiEndpoints := getAPIEndpoints(globalMinioAddr)
 	printStartupMessage(apiEndpoints)
@@ -60,6 +60,12 @@ func handleSignals() {
 			}
 
 			exit(err == nil && oerr == nil)
+		case <-globalStorageClassReloadCh:
+			if err := reloadStorageClassConfig(); err != nil {
+				errorIf(err, "Unable to reload storage class config, current storage classes are retained")
+			} else {
+				log.Println("Reloaded storage class config")
+			}
 		case osSignal := <-globalOSSignalCh:
 			stopHTTPTrace()
 			log.Printf("Exiting on signal %v\n", osSignal)
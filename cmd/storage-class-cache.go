@@ -0,0 +1,89 @@
+/*
+ * Minio Cloud Storage, (C) 2017 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import "sync"
+
+// Variable holds the data and parity drive counts resolved per bucket
+// and storage class, looked up on every write.
+var globalRedundancyCache = newRedundancyCache()
+
+// Key of a resolved data and parity drive count.
+type redundancyCacheKey struct {
+	bucket     string
+	sc         string
+	totalDisks int
+}
+
+// redundancyCache - memoizes getBucketRedundancyCount per bucket, storage
+// class and number of disks. Entries are served without checking the storage
+// classes they were resolved from, they are dropped by Invalidate whenever
+// the server or bucket storage classes change, i.e. on reload and on bucket
+// storage class updates.
+type redundancyCache struct {
+	rwMutex *sync.RWMutex
+	// Incremented whenever the entries are dropped, so that a drive
+	// count resolved before is not saved.
+	generation uint64
+	entries    map[redundancyCacheKey]redundancyInfo
+}
+
+func newRedundancyCache() *redundancyCache {
+	return &redundancyCache{
+		rwMutex: &sync.RWMutex{},
+		entries: make(map[redundancyCacheKey]redundancyInfo),
+	}
+}
+
+// Returns the data and parity drive count for a storage class of a bucket,
+// resolving it with resolve if it is not cached.
+func (c *redundancyCache) Get(bucket, sc string, totalDisks int, resolve func(bucket, sc string, totalDisks int) redundancyInfo) redundancyInfo {
+	if sc != "" && !isSupportedStorageClass(sc) {
+		sc = getStorageClassFromAlias(sc)
+		if !isSupportedStorageClass(sc) {
+			// Unsupported storage classes are not cached, they come from
+			// requests and would let clients grow the cache unbounded.
+			return resolve(bucket, sc, totalDisks)
+		}
+	}
+
+	key := redundancyCacheKey{bucket, sc, totalDisks}
+	c.rwMutex.RLock()
+	info, ok := c.entries[key]
+	generation := c.generation
+	c.rwMutex.RUnlock()
+	if ok {
+		return info
+	}
+
+	info = resolve(bucket, sc, totalDisks)
+	c.rwMutex.Lock()
+	// Storage classes changed while resolving, info may be stale.
+	if c.generation == generation {
+		c.entries[key] = info
+	}
+	c.rwMutex.Unlock()
+	return info
+}
+
+// Drops all the cached data and parity drive counts.
+func (c *redundancyCache) Invalidate() {
+	c.rwMutex.Lock()
+	defer c.rwMutex.Unlock()
+	c.generation++
+	c.entries = make(map[redundancyCacheKey]redundancyInfo)
+}
@@ -0,0 +1,176 @@
+/*
+ * Minio Cloud Storage, (C) 2017 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestRedundancyCache(t *testing.T) {
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+
+	resolves := 0
+	resolve := func(bucket, sc string, totalDisks int) redundancyInfo {
+		resolves++
+		return resolveBucketRedundancyCount(bucket, sc, totalDisks)
+	}
+	cache := newRedundancyCache()
+
+	globalStandardStorageClass = storageClass{Scheme: supportedStorageClassScheme, Parity: 6}
+	globalStorageClassAliases = map[string]string{"STANDARD_IA": standardStorageClass}
+	tests := []struct {
+		name             int
+		sc               string
+		expectedParity   int
+		expectedResolves int
+	}{
+		{1, standardStorageClass, 6, 1},
+		// Cached, not resolved again.
+		{2, standardStorageClass, 6, 1},
+		// Aliases share the entry of the storage class they map to.
+		{3, "STANDARD_IA", 6, 1},
+		{4, reducedRedundancyStorageClass, 2, 2},
+		{5, reducedRedundancyStorageClass, 2, 2},
+		// Unsupported storage classes are never cached.
+		{6, "UNKNOWN", 8, 3},
+		{7, "UNKNOWN", 8, 4},
+	}
+	for _, tt := range tests {
+		if parity := cache.Get("", tt.sc, 16, resolve).Parity; parity != tt.expectedParity {
+			t.Errorf("Test %d, Expected parity %d, got %d", tt.name, tt.expectedParity, parity)
+		}
+		if resolves != tt.expectedResolves {
+			t.Errorf("Test %d, Expected %d resolves, got %d", tt.name, tt.expectedResolves, resolves)
+		}
+	}
+
+	// Entries are served until they are dropped once the storage classes change.
+	globalStorageClassMu.Lock()
+	globalStandardStorageClass = storageClass{Scheme: supportedStorageClassScheme, Parity: 4}
+	globalStorageClassMu.Unlock()
+	globalRRSDefaultParity = 3
+	if parity := cache.Get("", standardStorageClass, 16, resolve).Parity; parity != 6 {
+		t.Errorf("Expected parity %d, got %d", 6, parity)
+	}
+	cache.Invalidate()
+	if parity := cache.Get("", standardStorageClass, 16, resolve).Parity; parity != 4 {
+		t.Errorf("Expected parity %d, got %d", 4, parity)
+	}
+	if parity := cache.Get("", reducedRedundancyStorageClass, 16, resolve).Parity; parity != 3 {
+		t.Errorf("Expected parity %d, got %d", 3, parity)
+	}
+
+	// Invalidate drops all the entries.
+	resolves = 0
+	cache.Invalidate()
+	cache.Get("", standardStorageClass, 16, resolve)
+	if resolves != 1 {
+		t.Errorf("Expected %d resolves, got %d", 1, resolves)
+	}
+	// Entries are cached per bucket, the storage class of the bucket
+	// takes precedence over the server wide storage class.
+	globalBucketStorageClass = &bucketStorageClasses{
+		rwMutex:                   &sync.RWMutex{},
+		bucketStorageClassConfigs: make(map[string]bucketStorageClassConfig),
+	}
+	defer func() { globalBucketStorageClass = nil }()
+	globalBucketStorageClass.SetBucketStorageClass("bucket", &bucketStorageClassConfig{
+		Standard: storageClass{Scheme: supportedStorageClassScheme, Parity: 2},
+	})
+	resolves = 0
+	for i := 0; i < 2; i++ {
+		if parity := cache.Get("bucket", standardStorageClass, 16, resolve).Parity; parity != 2 {
+			t.Errorf("Expected parity %d, got %d", 2, parity)
+		}
+	}
+	if resolves != 1 {
+		t.Errorf("Expected %d resolves, got %d", 1, resolves)
+	}
+	// Bucket storage class updates drop the entries.
+	generation := globalRedundancyCache.generation
+	globalBucketStorageClass.SetBucketStorageClass("bucket", nil)
+	if globalRedundancyCache.generation == generation {
+		t.Errorf("Expected bucket storage class update to drop the entries")
+	}
+	cache.Invalidate()
+	if parity := cache.Get("bucket", standardStorageClass, 16, resolve).Parity; parity != 4 {
+		t.Errorf("Expected parity %d, got %d", 4, parity)
+	}
+
+	// Drive counts resolved while the entries are dropped are not saved.
+	resolves = 0
+	cache.Get("", scratchStorageClass, 16, func(bucket, sc string, totalDisks int) redundancyInfo {
+		cache.Invalidate()
+		return resolve(bucket, sc, totalDisks)
+	})
+	cache.Get("", scratchStorageClass, 16, resolve)
+	if resolves != 2 {
+		t.Errorf("Expected %d resolves, got %d", 2, resolves)
+	}
+}
+
+// Tests that concurrent writes never see parity of the previous
+// storage classes once the storage classes are swapped.
+func TestRedundancyCacheConcurrent(t *testing.T) {
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				getRedundancyCount(standardStorageClass, 16)
+			}
+		}()
+	}
+	globalStorageClassMu.Lock()
+	globalStandardStorageClass = storageClass{Scheme: supportedStorageClassScheme, Parity: 4}
+	globalStorageClassMu.Unlock()
+	globalRedundancyCache.Invalidate()
+	wg.Wait()
+
+	if parity := getRedundancyCount(standardStorageClass, 16).Parity; parity != 4 {
+		t.Errorf("Expected parity %d, got %d", 4, parity)
+	}
+}
+
+// Compares cached getRedundancyCount against resolving
+// the storage class on every call.
+func BenchmarkRedundancyCache(b *testing.B) {
+	setRedundancyCountBenchmarkEnvs()
+	defer resetGlobalStorageEnvs()
+
+	for _, bb := range redundancyCountBenchmarks {
+		b.Run(fmt.Sprintf("cached-%s-%d", bb.sc, bb.disks), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				getRedundancyCount(bb.sc, bb.disks)
+			}
+		})
+		b.Run(fmt.Sprintf("resolved-%s-%d", bb.sc, bb.disks), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				resolveBucketRedundancyCount("", bb.sc, bb.disks)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+/*
+ * Minio Cloud Storage, (C) 2017 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/minio/minio/pkg/errors"
+)
+
+// durabilityReport - distribution of the parity objects are laid out
+// with, as read from their xl.json.
+type durabilityReport struct {
+	// Every Sample'th object listed is examined.
+	Sample int `json:"sample"`
+	// Set if the scan stopped at the maximum objects to examine.
+	Truncated bool `json:"truncated"`
+	// Objects examined.
+	Objects uint64 `json:"objects"`
+	// Objects examined without a readable xl.json.
+	Unreadable uint64 `json:"unreadable"`
+	// Objects examined per parity disks.
+	Parity map[string]uint64 `json:"parity"`
+	// Lowest parity disks of the objects examined, i.e. the number of
+	// disks every object examined can lose without losing data.
+	MinParity int `json:"minParity"`
+	// Objects examined with less parity than their storage class
+	// is currently laid out with, e.g. written under an old config.
+	BelowDefault uint64 `json:"belowDefault"`
+}
+
+// Accounts an object laid out as in its xl.json, parity is compared to
+// the parity the object would be written with now, so that small objects
+// are compared to the size tuned parity. Objects with forced parity are
+// never below the default.
+func (report *durabilityReport) addObject(xlMeta xlMetaV1, totalDisks int) {
+	parity := xlMeta.Erasure.ParityBlocks
+	if report.Objects == 0 || parity < report.MinParity {
+		report.MinParity = parity
+	}
+	report.Objects++
+	report.Parity[strconv.Itoa(parity)]++
+	if info, _, ok := getHealRedundancyInfo(xlMeta, totalDisks); ok && parity < info.Parity {
+		report.BelowDefault++
+	}
+}
+
+// Reports the parity objects are laid out with by reading the xl.json of
+// the objects of a bucket, or of all the buckets if bucket is empty, whose
+// name starts with prefix. Sampling is done at the listing level: the tree
+// is walked for object names only, only every sample'th object listed has
+// its xl.json read, and the walk stops after maxObjects objects are
+// examined if maxObjects is more than 0, so that large setups can be
+// sampled rather than fully scanned. The scan stops with ctx.Err() once
+// ctx is done, e.g. the admin request is cancelled.
+func (xl xlObjects) getDurabilityReport(ctx context.Context, bucket, prefix string, sample, maxObjects int) (durabilityReport, error) {
+	report := durabilityReport{
+		Sample: sample,
+		Parity: make(map[string]uint64),
+	}
+	buckets := []string{bucket}
+	if bucket == "" {
+		bucketInfos, err := xl.ListBuckets()
+		if err != nil {
+			return report, errors.Cause(err)
+		}
+		buckets = buckets[:0]
+		for _, bucketInfo := range bucketInfos {
+			buckets = append(buckets, bucketInfo.Name)
+		}
+	} else if _, err := xl.GetBucketInfo(bucket); err != nil {
+		return report, errors.Cause(err)
+	}
+
+	listed := 0
+	for _, bucket := range buckets {
+		done, err := xl.walkDurabilityReport(ctx, bucket, prefix, sample, maxObjects, &listed, &report)
+		if err != nil || done {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// Walks the objects of a bucket whose name starts with prefix for
+// getDurabilityReport, listed counts the objects listed across buckets.
+// Returns true once maxObjects objects are examined, the walk is ended
+// right away rather than listing the rest of the bucket.
+func (xl xlObjects) walkDurabilityReport(ctx context.Context, bucket, prefix string, sample, maxObjects int, listed *int, report *durabilityReport) (bool, error) {
+	endWalkCh := make(chan struct{})
+	defer close(endWalkCh)
+
+	isLeaf := xl.isObject
+	listDir := listDirFactory(isLeaf, xlTreeWalkIgnoredErrs, xl.getLoadBalancedDisks()...)
+	walkResultCh := startTreeWalk(bucket, prefix, "", true, listDir, isLeaf, endWalkCh)
+	for walkResult := range walkResultCh {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		if walkResult.err != nil {
+			return false, errors.Cause(toObjectErr(walkResult.err, bucket, prefix))
+		}
+		// Empty directories are listed with a trailing slash.
+		if hasSuffix(walkResult.entry, slashSeparator) {
+			continue
+		}
+		*listed++
+		if (*listed-1)%sample != 0 {
+			continue
+		}
+		if maxObjects > 0 && report.Objects+report.Unreadable >= uint64(maxObjects) {
+			report.Truncated = true
+			return true, nil
+		}
+		metaArr, errs := readAllXLMetadata(xl.storageDisks, bucket, walkResult.entry)
+		index, _ := getLatestXLMetaIndex(metaArr, errs)
+		if index == -1 {
+			report.Unreadable++
+			continue
+		}
+		report.addObject(metaArr[index], xl.setDriveCount())
+	}
+	return false, nil
+}
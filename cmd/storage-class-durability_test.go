@@ -0,0 +1,105 @@
+/*
+ * Minio Cloud Storage, (C) 2017 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestGetDurabilityReport(t *testing.T) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+
+	obj, fsDirs, err := prepareXL16()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+	xl := obj.(*xlObjects)
+
+	for _, bucket := range []string{"bucket", "other"} {
+		if err = obj.MakeBucketWithLocation(bucket, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	putObject := func(bucket, object string, metadata map[string]string) {
+		data := bytes.Repeat([]byte("a"), 1024)
+		if _, err = obj.PutObject(bucket, object, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), metadata); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Objects written under an old config with lower Standard parity.
+	globalStandardStorageClass = storageClass{Scheme: supportedStorageClassScheme, Parity: 4}
+	putObject("bucket", "legacy-1", map[string]string{amzStorageClass: standardStorageClass})
+	putObject("bucket", "legacy-2", map[string]string{amzStorageClass: standardStorageClass})
+	resetGlobalStorageEnvs()
+	putObject("bucket", "rrs", map[string]string{amzStorageClass: reducedRedundancyStorageClass})
+	putObject("bucket", "standard", nil)
+	putObject("other", "standard", nil)
+
+	tests := []struct {
+		name           int
+		bucket         string
+		prefix         string
+		sample         int
+		maxObjects     int
+		expectedReport durabilityReport
+	}{
+		{1, "", "", 1, 0, durabilityReport{Sample: 1, Objects: 5, MinParity: 2, BelowDefault: 2,
+			Parity: map[string]uint64{"2": 1, "4": 2, "8": 2}}},
+		{2, "other", "", 1, 0, durabilityReport{Sample: 1, Objects: 1, MinParity: 8,
+			Parity: map[string]uint64{"8": 1}}},
+		// Every other object is examined, legacy-1 and rrs of bucket
+		// and standard of other.
+		{3, "", "", 2, 0, durabilityReport{Sample: 2, Objects: 3, MinParity: 2, BelowDefault: 1,
+			Parity: map[string]uint64{"2": 1, "4": 1, "8": 1}}},
+		{4, "", "", 1, 2, durabilityReport{Sample: 1, Truncated: true, Objects: 2, MinParity: 4, BelowDefault: 2,
+			Parity: map[string]uint64{"4": 2}}},
+		{5, "", "", 1, 5, durabilityReport{Sample: 1, Objects: 5, MinParity: 2, BelowDefault: 2,
+			Parity: map[string]uint64{"2": 1, "4": 2, "8": 2}}},
+		// Only the objects under prefix are listed.
+		{6, "bucket", "legacy", 1, 0, durabilityReport{Sample: 1, Objects: 2, MinParity: 4, BelowDefault: 2,
+			Parity: map[string]uint64{"4": 2}}},
+		{7, "", "standard", 1, 0, durabilityReport{Sample: 1, Objects: 2, MinParity: 8,
+			Parity: map[string]uint64{"8": 2}}},
+		{8, "", "missing", 1, 0, durabilityReport{Sample: 1, Parity: map[string]uint64{}}},
+	}
+	for _, tt := range tests {
+		report, err := xl.getDurabilityReport(context.Background(), tt.bucket, tt.prefix, tt.sample, tt.maxObjects)
+		if err != nil {
+			t.Fatalf("Test %d, Unexpected error %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(report, tt.expectedReport) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedReport, report)
+		}
+	}
+
+	if _, err = xl.getDurabilityReport(context.Background(), "nonexistent-bucket", "", 1, 0); toAPIErrorCode(err) != ErrNoSuchBucket {
+		t.Errorf("Expected ErrNoSuchBucket, got %v", err)
+	}
+	// Scan stops once the context is cancelled.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = xl.getDurabilityReport(ctx, "", "", 1, 0); err != context.Canceled {
+		t.Errorf("Expected %v, got %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,162 @@
+/*
+ * Minio Cloud Storage, (C) 2017 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+
+	"github.com/minio/minio/pkg/errors"
+)
+
+// storageClassNormalizeResult - objects examined and stamped with
+// Standard storage class by a storage class normalization pass.
+type storageClassNormalizeResult struct {
+	DryRun bool `json:"dryRun"`
+	// Objects examined.
+	Objects uint64 `json:"objects"`
+	// Objects without storage class, stamped with Standard storage
+	// class unless this is a dry run.
+	Stamped uint64 `json:"stamped"`
+	// Objects which could not be examined or stamped.
+	Failed uint64 `json:"failed"`
+}
+
+// Stamps Standard storage class in the xl.json of an object written
+// without storage class, e.g. before storage classes existed. Only
+// xl.json is rewritten, the object is not re-encoded as objects without
+// storage class are already laid out as Standard storage class. Returns
+// false if the object already has a storage class, nothing is written
+// if dryRun is set.
+func (xl xlObjects) stampStandardStorageClass(ctx context.Context, bucket, object string, dryRun bool) (stamped bool, err error) {
+	// Read metadata associated with the object from all disks.
+	metaArr, errs := readAllXLMetadata(xl.storageDisks, bucket, object)
+
+	// get Quorum for this object
+	readQuorum, writeQuorum, err := objectQuorumFromMeta(ctx, xl, bucket, object, metaArr, errs)
+	if err != nil {
+		return false, toObjectErr(err, bucket, object)
+	}
+	if reducedErr := reduceReadQuorumErrs(errs, objectOpIgnoredErrs, readQuorum); reducedErr != nil {
+		return false, toObjectErr(reducedErr, bucket, object)
+	}
+
+	// List all online disks.
+	onlineDisks, modTime := listOnlineDisks(xl.storageDisks, metaArr, errs)
+
+	// Pick latest valid metadata.
+	xlMeta, err := pickValidXLMeta(metaArr, modTime)
+	if err != nil {
+		return false, toObjectErr(err, bucket, object)
+	}
+	if xlMeta.Meta[amzStorageClass] != "" {
+		return false, nil
+	}
+	if dryRun {
+		return true, nil
+	}
+
+	// Keep the erasure index and the checksums of each disk, only
+	// the storage class is added to the metadata.
+	for index := range metaArr {
+		if onlineDisks[index] == nil {
+			continue
+		}
+		meta := make(map[string]string, len(metaArr[index].Meta)+1)
+		for k, v := range metaArr[index].Meta {
+			meta[k] = v
+		}
+		meta[amzStorageClass] = standardStorageClass
+		metaArr[index].Meta = meta
+	}
+	onlineDisks = shuffleDisks(onlineDisks, xlMeta.Erasure.Distribution)
+	metaArr = shufflePartsMetadata(metaArr, xlMeta.Erasure.Distribution)
+
+	tempObj := mustGetUUID()
+
+	// Write unique `xl.json` for each disk.
+	if onlineDisks, err = writeUniqueXLMetadata(onlineDisks, minioMetaTmpBucket, tempObj, metaArr, writeQuorum); err != nil {
+		return false, toObjectErr(err, bucket, object)
+	}
+	// Rename atomically `xl.json` from tmp location to destination for each disk.
+	if _, err = renameXLMetadata(onlineDisks, minioMetaTmpBucket, tempObj, bucket, object, writeQuorum); err != nil {
+		return false, toObjectErr(err, bucket, object)
+	}
+	return true, nil
+}
+
+// Stamps Standard storage class in the xl.json of all the objects of a
+// bucket, or of all the buckets if bucket is empty, which have no storage
+// class, so that all objects carry the storage class they are laid out
+// with. Objects with a storage class are skipped, so the pass can be run
+// repeatedly. Objects are locked while stamped, objects which fail are
+// logged and counted and the pass continues. The pass stops with
+// ctx.Err() once ctx is done, e.g. the admin request is cancelled.
+func (xl xlObjects) normalizeStorageClasses(ctx context.Context, bucket string, dryRun bool) (storageClassNormalizeResult, error) {
+	result := storageClassNormalizeResult{DryRun: dryRun}
+	buckets := []string{bucket}
+	if bucket == "" {
+		bucketInfos, err := xl.ListBuckets()
+		if err != nil {
+			return result, errors.Cause(err)
+		}
+		buckets = buckets[:0]
+		for _, bucketInfo := range bucketInfos {
+			buckets = append(buckets, bucketInfo.Name)
+		}
+	}
+
+	for _, bucket := range buckets {
+		marker := ""
+		for {
+			listResult, err := xl.ListObjects(bucket, "", marker, "", maxObjectList)
+			if err != nil {
+				return result, errors.Cause(err)
+			}
+			for _, objInfo := range listResult.Objects {
+				if err = ctx.Err(); err != nil {
+					return result, err
+				}
+				result.Objects++
+				stamped, err := xl.stampStandardStorageClassLocked(ctx, bucket, objInfo.Name, dryRun)
+				if err != nil {
+					errorIf(err, "Unable to stamp storage class of %s/%s.", bucket, objInfo.Name)
+					result.Failed++
+					continue
+				}
+				if stamped {
+					result.Stamped++
+				}
+			}
+			if !listResult.IsTruncated {
+				break
+			}
+			marker = listResult.NextMarker
+		}
+	}
+	return result, nil
+}
+
+// Stamps Standard storage class like stampStandardStorageClass, while
+// holding the write lock of the object.
+func (xl xlObjects) stampStandardStorageClassLocked(ctx context.Context, bucket, object string, dryRun bool) (bool, error) {
+	objectLock := globalNSMutex.NewNSLock(bucket, object)
+	if err := objectLock.GetLock(globalObjectTimeout); err != nil {
+		return false, err
+	}
+	defer objectLock.Unlock()
+	return xl.stampStandardStorageClass(ctx, bucket, object, dryRun)
+}
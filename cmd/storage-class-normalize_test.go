@@ -0,0 +1,139 @@
+/*
+ * Minio Cloud Storage, (C) 2017 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestNormalizeStorageClasses(t *testing.T) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+	// initialize NSLock, objects are locked while stamped.
+	initNSLock(false)
+
+	obj, fsDirs, err := prepareXL16()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+	xl := obj.(*xlObjects)
+
+	bucket := "bucket"
+	if err = obj.MakeBucketWithLocation(bucket, ""); err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Repeat([]byte("a"), 1024)
+	for _, object := range []string{"legacy-1", "legacy-2", "rrs"} {
+		metadata := map[string]string{}
+		if object == "rrs" {
+			metadata[amzStorageClass] = reducedRedundancyStorageClass
+		}
+		if _, err = obj.PutObject(bucket, object, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), metadata); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Objects written before storage classes existed have no storage class.
+	for _, object := range []string{"legacy-1", "legacy-2"} {
+		for _, disk := range xl.storageDisks {
+			xlMeta, err := readXLMeta(disk, bucket, object)
+			if err != nil {
+				t.Fatal(err)
+			}
+			delete(xlMeta.Meta, amzStorageClass)
+			if err = deleteXLMetdata(disk, bucket, object); err != nil {
+				t.Fatal(err)
+			}
+			if err = writeXLMetadata(disk, bucket, object, xlMeta); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	before := make([]xlMetaV1, len(xl.storageDisks))
+	for index, disk := range xl.storageDisks {
+		if before[index], err = readXLMeta(disk, bucket, "legacy-1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Dry run only counts the objects.
+	result, err := xl.normalizeStorageClasses(context.Background(), "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (storageClassNormalizeResult{DryRun: true, Objects: 3, Stamped: 2}); result != expected {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+	if xlMeta, _ := readXLMeta(xl.storageDisks[0], bucket, "legacy-1"); xlMeta.Meta[amzStorageClass] != "" {
+		t.Errorf("Expected dry run not to stamp the object, got %s", xlMeta.Meta[amzStorageClass])
+	}
+
+	result, err = xl.normalizeStorageClasses(context.Background(), bucket, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (storageClassNormalizeResult{Objects: 3, Stamped: 2}); result != expected {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+	// Only the storage class is added, the layout of each disk is kept.
+	for index, disk := range xl.storageDisks {
+		xlMeta, err := readXLMeta(disk, bucket, "legacy-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sc := xlMeta.Meta[amzStorageClass]; sc != standardStorageClass {
+			t.Errorf("Disk %d, Expected storage class %s, got %s", index, standardStorageClass, sc)
+		}
+		if xlMeta.Erasure.Index != before[index].Erasure.Index || xlMeta.Erasure.ParityBlocks != before[index].Erasure.ParityBlocks ||
+			!xlMeta.Stat.ModTime.Equal(before[index].Stat.ModTime) {
+			t.Errorf("Disk %d, Expected layout %v, got %v", index, before[index].Erasure, xlMeta.Erasure)
+		}
+	}
+	if xlMeta, _ := readXLMeta(xl.storageDisks[0], bucket, "rrs"); xlMeta.Meta[amzStorageClass] != reducedRedundancyStorageClass {
+		t.Errorf("Expected storage class %s to be kept, got %s", reducedRedundancyStorageClass, xlMeta.Meta[amzStorageClass])
+	}
+	var buffer bytes.Buffer
+	if err = obj.GetObject(bucket, "legacy-1", 0, int64(len(data)), &buffer); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buffer.Bytes(), data) {
+		t.Errorf("Expected stamped object data to be unchanged")
+	}
+
+	// Normalization is idempotent.
+	if result, err = xl.normalizeStorageClasses(context.Background(), "", false); err != nil {
+		t.Fatal(err)
+	}
+	if expected := (storageClassNormalizeResult{Objects: 3}); result != expected {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	if _, err = xl.normalizeStorageClasses(context.Background(), "nonexistent-bucket", false); toAPIErrorCode(err) != ErrNoSuchBucket {
+		t.Errorf("Expected ErrNoSuchBucket, got %v", err)
+	}
+
+	// Pass stops once the context is cancelled.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = xl.normalizeStorageClasses(ctx, "", false); err != context.Canceled {
+		t.Errorf("Expected %v, got %v", context.Canceled, err)
+	}
+}
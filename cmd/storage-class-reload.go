@@ -0,0 +1,264 @@
+/*
+ * Minio Cloud Storage, (C) 2017 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Returns the server wide storage classes, safe to be called
+// while the storage classes are being reloaded.
+func getStorageClassGlobals() (ssc, rrsc, maxsc storageClass) {
+	globalStorageClassMu.RLock()
+	defer globalStorageClassMu.RUnlock()
+	return globalStandardStorageClass, globalRRStorageClass, globalMaxStorageClass
+}
+
+// loadStorageClassEnv - loads the storage classes from the storage class
+// config file and the storage class environment variables, the environment
+// variables override the config file per storage class. Storage classes and
+// the quorum policy are validated together, storage classes which are not
+// set are returned as is.
+func loadStorageClassEnv() (ssc, rrsc, maxsc storageClass, err error) {
+	return loadStorageClassEnvForDisks(len(globalEndpoints))
+}
+
+// Same as loadStorageClassEnv for the given number of disks.
+func loadStorageClassEnvForDisks(disks int) (ssc, rrsc, maxsc storageClass, err error) {
+	configFile := os.Getenv(storageClassConfigFileEnv)
+	if configFile != "" {
+		sCfg, err := loadStorageClassConfigFile(configFile)
+		if err != nil {
+			return ssc, rrsc, maxsc, fmt.Errorf("Invalid value set in environment variable %s: %v", storageClassConfigFileEnv, err)
+		}
+		ssc, rrsc = sCfg.Standard, sCfg.RRS
+	}
+
+	// Check for environment variables and parse into storageClass struct
+	for _, scEnv := range []struct {
+		name string
+		sc   *storageClass
+	}{
+		{standardStorageClassEnv, &ssc},
+		{reducedRedundancyStorageClassEnv, &rrsc},
+		{maxDurabilityStorageClassEnv, &maxsc},
+	} {
+		if value := os.Getenv(scEnv.name); value != "" {
+			fileSc := *scEnv.sc
+			if *scEnv.sc, err = parseStorageClass(value); err != nil {
+				return ssc, rrsc, maxsc, fmt.Errorf("Invalid value set in environment variable %s: %v", scEnv.name, err)
+			}
+			if err = checkStorageClassConflict(scEnv.name, *scEnv.sc, configFile, fileSc); err != nil {
+				return ssc, rrsc, maxsc, err
+			}
+		}
+	}
+
+	// Parity set as a percentage and data disks are resolved and checked
+	// against the given disks, rather than the disks of this server.
+	for _, class := range []struct {
+		name string
+		sc   *storageClass
+	}{
+		{standardStorageClass, &ssc},
+		{reducedRedundancyStorageClass, &rrsc},
+		{maxDurabilityStorageClass, &maxsc},
+	} {
+		*class.sc = resolvePercentParity(*class.sc, disks)
+		if err = checkStorageClassDisks(*class.sc, disks); err != nil {
+			return ssc, rrsc, maxsc, fmt.Errorf("%s: %v", class.name, err)
+		}
+	}
+
+	// Reduced redundancy storage class may be relative to Standard storage class parity,
+	// which is only known once both the storage classes are parsed.
+	if err = checkRelativeStorageClasses(ssc, maxsc); err != nil {
+		return ssc, rrsc, maxsc, err
+	}
+	rrsc = resolveRelativeParity(rrsc, ssc, disks)
+
+	// Validation is done after parsing both the storage classes. This is needed because we need one
+	// storage class value to deduce the correct value of the other storage class.
+	if rrsc.Scheme != "" || ssc.Scheme != "" {
+		if err = validateStorageClassSchemes(ssc.Scheme, rrsc.Scheme); err != nil {
+			return ssc, rrsc, maxsc, err
+		}
+		if err = checkStorageClassConfig(ssc.Parity, rrsc.Parity, disks); err != nil {
+			return ssc, rrsc, maxsc, err
+		}
+	}
+
+	// Max durability storage class is validated last, to enforce RRS < STANDARD < MAX.
+	// Parity 0 is the default parity and is not validated.
+	if maxsc.Scheme != "" && maxsc.Parity != 0 {
+		if errs := checkMaxParity(maxsc.Parity, ssc.Parity, disks); len(errs) > 0 {
+			return ssc, rrsc, maxsc, fmt.Errorf("Invalid value set in environment variable %s: %v", maxDurabilityStorageClassEnv, errs[0])
+		}
+	}
+
+	// Write quorum of every storage class should be met with all the disks online.
+	if err = checkStorageClassWriteQuorum(ssc, rrsc, maxsc, disks); err != nil {
+		return ssc, rrsc, maxsc, err
+	}
+
+	// Quorum policy applies to all the storage classes and is validated against the parity of each.
+	if quorum := os.Getenv(storageClassQuorumEnv); quorum != "" {
+		q, err := parseQuorumPolicy(quorum)
+		if err == nil {
+			err = checkStorageClassQuorumPolicy(q, ssc, rrsc, maxsc, disks)
+		}
+		if err != nil {
+			return ssc, rrsc, maxsc, fmt.Errorf("Invalid value set in environment variable %s: %v", storageClassQuorumEnv, err)
+		}
+		ssc.Quorum, rrsc.Quorum, maxsc.Quorum = q, q, q
+	}
+
+	return ssc, rrsc, maxsc, nil
+}
+
+// checkStorageClassConflict - checks a storage class set in the environment
+// variable env against the same storage class set in the storage class config
+// file, the environment variable takes precedence. Conflicting values are
+// rejected if MINIO_STORAGE_CLASS_STRICT is on, otherwise a warning naming
+// both the values is logged.
+func checkStorageClassConflict(env string, envSc storageClass, configFile string, fileSc storageClass) error {
+	if fileSc.Scheme == "" || fileSc.equalParity(envSc) {
+		return nil
+	}
+	if globalStorageClassStrict {
+		return fmt.Errorf("Invalid value set in environment variable %s: %s conflicts with %s set in storage class config file %s (%s=on)",
+			env, envSc, fileSc, configFile, storageClassStrictEnv)
+	}
+	log.Println(colorYellow("\n               *** Warning: %s=%s overrides %s set in storage class config file %s ***",
+		env, envSc, fileSc, configFile))
+	return nil
+}
+
+// reloadStorageClassConfig - reloads the storage classes of a running
+// server, typically on SIGHUP. As the environment of a running process
+// doesn't change, this picks up changes to the storage class config file.
+// Storage classes not set in the environment fall back to config.json.
+// The current storage classes are retained if the new storage classes are
+// invalid. Only new objects are written with the new parity, existing
+// objects are not affected as their parity is saved in xl.json.
+func reloadStorageClassConfig() error {
+	ssc, rrsc, maxsc, err := loadStorageClassEnv()
+	if err != nil {
+		return err
+	}
+
+	isStorageClass := rrsc.Scheme != "" || ssc.Scheme != ""
+	if !isStorageClass {
+		globalServerConfigMu.RLock()
+		if globalServerConfig != nil {
+			q := ssc.Quorum
+			ssc, rrsc = globalServerConfig.GetStorageClass()
+			ssc.Quorum, rrsc.Quorum = q, q
+		}
+		globalServerConfigMu.RUnlock()
+
+		// Quorum policy is validated again against the storage classes in config.json.
+		if q := ssc.Quorum; q != (quorumPolicy{}) {
+			if err = checkStorageClassQuorumPolicy(q, ssc, rrsc, maxsc, len(globalEndpoints)); err != nil {
+				return fmt.Errorf("Invalid value set in environment variable %s: %v", storageClassQuorumEnv, err)
+			}
+		}
+	}
+
+	globalStorageClassMu.Lock()
+	globalStandardStorageClass, globalRRStorageClass, globalMaxStorageClass = ssc, rrsc, maxsc
+	globalIsStorageClass = isStorageClass
+	globalStorageClassMu.Unlock()
+
+	// Drop the parity resolved from the previous storage classes.
+	globalRedundancyCache.Invalidate()
+	globalClampedParityWarnings.Reset()
+	return nil
+}
+
+// checkStorageClassHealth - re-validates the server wide storage classes
+// against the given number of disks, e.g. the disks currently online.
+// Storage classes are validated at startup and on reload against all the
+// disks of the setup, but the disks may no longer be there, e.g. STANDARD
+// parity of 8 is not valid anymore once fewer than 16 disks are online.
+// Returns the first violation found.
+func checkStorageClassHealth(disks int) error {
+	ssc, rrsc, maxsc := getStorageClassGlobals()
+	ssc, rrsc = resolvePercentParity(ssc, disks), resolvePercentParity(rrsc, disks)
+	maxsc = resolvePercentParity(maxsc, disks)
+	if err := ValidateParity(reducedRedundancyStorageClass, rrsc.Parity, disks, ssc.Parity); err != nil {
+		return fmt.Errorf("%s: %v", reducedRedundancyStorageClass, err)
+	}
+	if err := ValidateParity(standardStorageClass, ssc.Parity, disks, rrsc.Parity); err != nil {
+		return fmt.Errorf("%s: %v", standardStorageClass, err)
+	}
+	if err := ValidateParity(maxDurabilityStorageClass, maxsc.Parity, disks, ssc.Parity); err != nil {
+		return fmt.Errorf("%s: %v", maxDurabilityStorageClass, err)
+	}
+	return checkStorageClassWriteQuorum(ssc, rrsc, maxsc, disks)
+}
+
+// validateStorageClassOnly - loads and validates the storage classes like
+// server startup, but for the given number of disks instead of the server
+// endpoints, e.g. to validate a storage class config in a deployment
+// pipeline. The resolved data and parity disks of the storage classes are
+// printed to w, nothing is printed if the storage classes are invalid.
+func validateStorageClassOnly(disks int, w io.Writer) error {
+	// disks < 4 means this is not a erasure coded setup and so storage class is not supported
+	if disks < 4 {
+		return fmt.Errorf("Setting storage class only allowed for erasure coding mode, found %d disks", disks)
+	}
+	if err := checkMaximumErasureDisks(disks); err != nil {
+		return err
+	}
+
+	// Environment variables relaxing or tightening the validation of the storage classes.
+	globalStorageClassAllowSmall = strings.EqualFold(os.Getenv(storageClassAllowSmallEnv), "on")
+	globalStorageClassRequireEvenParity = strings.EqualFold(os.Getenv(storageClassRequireEvenParityEnv), "on")
+
+	ssc, rrsc, maxsc, err := loadStorageClassEnvForDisks(disks)
+	if err != nil {
+		return err
+	}
+	if value := os.Getenv(storageClassMaxParityEnv); value != "" {
+		if globalStorageClassMaxParity, err = parseMaxParity(value); err != nil {
+			return fmt.Errorf("Invalid value set in environment variable %s: %v", storageClassMaxParityEnv, err)
+		}
+	}
+	if value := os.Getenv(storageClassRRSDefaultEnv); value != "" {
+		if globalRRSDefaultParity, err = parseRRSDefaultParity(value, ssc.Parity); err != nil {
+			return fmt.Errorf("Invalid value set in environment variable %s: %v", storageClassRRSDefaultEnv, err)
+		}
+	}
+
+	fmt.Fprintf(w, "Storage class layout for %d disks:\n", disks)
+	for _, sc := range []string{standardStorageClass, reducedRedundancyStorageClass} {
+		info := GetRedundancyCount(sc, disks, ssc, rrsc)
+		source := storageClassSourceConfig
+		if info.UsedDefault {
+			source = storageClassSourceDefault
+		}
+		fmt.Fprintf(w, "%s: %d data, %d parity (%s)\n", sc, info.Data, info.Parity, source)
+	}
+	if maxsc.Parity != 0 {
+		fmt.Fprintf(w, "%s: %d data, %d parity (%s)\n", maxDurabilityStorageClass, disks-maxsc.Parity, maxsc.Parity, storageClassSourceConfig)
+	}
+	return nil
+}
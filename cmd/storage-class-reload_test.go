@@ -0,0 +1,329 @@
+/*
+ * Minio Cloud Storage, (C) 2017 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadStorageClassEnv(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testLoadStorageClassEnv)
+}
+
+func testLoadStorageClassEnv(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	// Set globalEndpoints for a single node XL setup.
+	globalEndpoints = mustGetNewEndpointList(dirs...)
+	defer resetGlobalEndpoints()
+	defer resetGlobalStorageEnvs()
+
+	envs := []string{standardStorageClassEnv, reducedRedundancyStorageClassEnv, maxDurabilityStorageClassEnv, storageClassQuorumEnv}
+	defer func() {
+		for _, env := range envs {
+			os.Unsetenv(env)
+		}
+	}()
+
+	tests := []struct {
+		name          int
+		envs          map[string]string
+		expectedSsc   storageClass
+		expectedRrsc  storageClass
+		expectedMaxsc storageClass
+		expectedError string
+	}{
+		{1, map[string]string{}, storageClass{}, storageClass{}, storageClass{}, ""},
+		{2, map[string]string{standardStorageClassEnv: "EC:6", reducedRedundancyStorageClassEnv: "EC:3", maxDurabilityStorageClassEnv: "EC:8"},
+			storageClass{Scheme: "EC", Parity: 6}, storageClass{Scheme: "EC", Parity: 3}, storageClass{Scheme: "EC", Parity: 8}, ""},
+		{3, map[string]string{standardStorageClassEnv: "EC:4", storageClassQuorumEnv: "0:2"},
+			storageClass{Scheme: "EC", Parity: 4, Quorum: quorumPolicy{0, 2}}, storageClass{Quorum: quorumPolicy{0, 2}}, storageClass{Quorum: quorumPolicy{0, 2}}, ""},
+		{4, map[string]string{standardStorageClassEnv: "EC"}, storageClass{}, storageClass{}, storageClass{},
+			"Invalid value set in environment variable MINIO_STORAGE_CLASS_STANDARD: Too few sections in EC"},
+		{5, map[string]string{standardStorageClassEnv: "EC:3", reducedRedundancyStorageClassEnv: "EC:4"}, storageClass{}, storageClass{}, storageClass{},
+			"REDUCED_REDUNDANCY (MINIO_STORAGE_CLASS_RRS): Reduced redundancy storage class parity disks should be less than 3; " +
+				"STANDARD (MINIO_STORAGE_CLASS_STANDARD): Standard storage class parity disks should be greater than 4"},
+		// Reduced redundancy relative to Standard storage class parity.
+		{6, map[string]string{standardStorageClassEnv: "EC:7", reducedRedundancyStorageClassEnv: "EC:x0.5"},
+			storageClass{Scheme: "EC", Parity: 7}, storageClass{Scheme: "EC", Parity: 3, Relative: 0.5}, storageClass{}, ""},
+		// Relative to the default N/2 parity if Standard storage class is not set.
+		{7, map[string]string{reducedRedundancyStorageClassEnv: "EC:x0.5"},
+			storageClass{}, storageClass{Scheme: "EC", Parity: 4, Relative: 0.5}, storageClass{}, ""},
+		{8, map[string]string{standardStorageClassEnv: "EC:x0.5"}, storageClass{}, storageClass{}, storageClass{},
+			"Relative parity EC:x0.5 is only supported for REDUCED_REDUNDANCY storage class, not for STANDARD"},
+		{9, map[string]string{standardStorageClassEnv: "EC:6", reducedRedundancyStorageClassEnv: "EC:x1"}, storageClass{}, storageClass{}, storageClass{},
+			"REDUCED_REDUNDANCY (MINIO_STORAGE_CLASS_RRS): Reduced redundancy storage class parity disks should be less than 6; " +
+				"STANDARD (MINIO_STORAGE_CLASS_STANDARD): Standard storage class parity disks should be greater than 6"},
+	}
+	for _, tt := range tests {
+		for _, env := range envs {
+			os.Unsetenv(env)
+		}
+		for env, value := range tt.envs {
+			os.Setenv(env, value)
+		}
+		ssc, rrsc, maxsc, err := loadStorageClassEnv()
+		if tt.expectedError != "" {
+			if err == nil || err.Error() != tt.expectedError {
+				t.Errorf("Test %d, Expected %s, got %v", tt.name, tt.expectedError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d, Unexpected error %v", tt.name, err)
+			continue
+		}
+		if ssc != tt.expectedSsc || rrsc != tt.expectedRrsc || maxsc != tt.expectedMaxsc {
+			t.Errorf("Test %d, Expected %v %v %v, got %v %v %v", tt.name, tt.expectedSsc, tt.expectedRrsc, tt.expectedMaxsc, ssc, rrsc, maxsc)
+		}
+	}
+}
+
+func TestLoadStorageClassEnvConflict(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testLoadStorageClassEnvConflict)
+}
+
+func testLoadStorageClassEnvConflict(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	// Set globalEndpoints for a single node XL setup.
+	globalEndpoints = mustGetNewEndpointList(dirs...)
+	defer resetGlobalEndpoints()
+	defer resetGlobalStorageEnvs()
+
+	dir, err := ioutil.TempDir("", "minio-storage-class")
+	if err != nil {
+		t.Fatalf("Unable to create temporary directory %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	configFile := filepath.Join(dir, "storageclass.json")
+	if err = ioutil.WriteFile(configFile, []byte(`{"standard":"EC:4","rrs":"EC:2"}`), 0644); err != nil {
+		t.Fatalf("Unable to write config file %v", err)
+	}
+	os.Setenv(storageClassConfigFileEnv, configFile)
+	defer os.Unsetenv(storageClassConfigFileEnv)
+	defer os.Unsetenv(standardStorageClassEnv)
+
+	tests := []struct {
+		name          int
+		standardEnv   string
+		strict        bool
+		expectedSsc   storageClass
+		expectedError string
+	}{
+		// Same value in environment and config file is not a conflict.
+		{1, "EC:4", true, storageClass{Scheme: "EC", Parity: 4}, ""},
+		// Environment variable takes precedence over the config file.
+		{2, "EC:6", false, storageClass{Scheme: "EC", Parity: 6}, ""},
+		{3, "EC:6", true, storageClass{}, "Invalid value set in environment variable MINIO_STORAGE_CLASS_STANDARD: EC:6 conflicts with EC:4 " +
+			"set in storage class config file " + configFile + " (MINIO_STORAGE_CLASS_STRICT=on)"},
+	}
+	for _, tt := range tests {
+		os.Setenv(standardStorageClassEnv, tt.standardEnv)
+		globalStorageClassStrict = tt.strict
+		ssc, _, _, err := loadStorageClassEnv()
+		if tt.expectedError != "" {
+			if err == nil || err.Error() != tt.expectedError {
+				t.Errorf("Test %d, Expected %s, got %v", tt.name, tt.expectedError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d, Unexpected error %v", tt.name, err)
+			continue
+		}
+		if ssc != tt.expectedSsc {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedSsc, ssc)
+		}
+	}
+}
+
+func TestValidateStorageClassOnly(t *testing.T) {
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+
+	envs := []string{standardStorageClassEnv, reducedRedundancyStorageClassEnv, maxDurabilityStorageClassEnv}
+	defer func() {
+		for _, env := range envs {
+			os.Unsetenv(env)
+		}
+	}()
+
+	tests := []struct {
+		name           int
+		disks          int
+		envs           map[string]string
+		expectedOutput string
+		expectedError  string
+	}{
+		{1, 16, map[string]string{},
+			"Storage class layout for 16 disks:\nSTANDARD: 8 data, 8 parity (default)\nREDUCED_REDUNDANCY: 14 data, 2 parity (default)\n", ""},
+		{2, 16, map[string]string{standardStorageClassEnv: "EC:6", reducedRedundancyStorageClassEnv: "EC:3", maxDurabilityStorageClassEnv: "EC:8"},
+			"Storage class layout for 16 disks:\nSTANDARD: 10 data, 6 parity (config)\nREDUCED_REDUNDANCY: 13 data, 3 parity (config)\n" +
+				"MAX_DURABILITY: 8 data, 8 parity (config)\n", ""},
+		// Parity valid on 16 disks is not valid on 8 disks.
+		{3, 8, map[string]string{standardStorageClassEnv: "EC:6"}, "",
+			"STANDARD (MINIO_STORAGE_CLASS_STANDARD): Standard storage class parity disks should be less than or equal to 4"},
+		{4, 2, map[string]string{}, "", "Setting storage class only allowed for erasure coding mode, found 2 disks"},
+		// Percentage and data disks are resolved for the given disks, not the disks of this server.
+		{5, 16, map[string]string{standardStorageClassEnv: "EC:25%"},
+			"Storage class layout for 16 disks:\nSTANDARD: 12 data, 4 parity (config)\nREDUCED_REDUNDANCY: 14 data, 2 parity (default)\n", ""},
+		{6, 16, map[string]string{standardStorageClassEnv: "EC:12:4"},
+			"Storage class layout for 16 disks:\nSTANDARD: 12 data, 4 parity (config)\nREDUCED_REDUNDANCY: 14 data, 2 parity (default)\n", ""},
+		{7, 16, map[string]string{standardStorageClassEnv: "EC:8:4"}, "",
+			"STANDARD: Data and parity disks should add up to 16 disks, found 12 in EC:8:4"},
+	}
+	for _, tt := range tests {
+		for _, env := range envs {
+			os.Unsetenv(env)
+		}
+		for env, value := range tt.envs {
+			os.Setenv(env, value)
+		}
+		var buf bytes.Buffer
+		err := validateStorageClassOnly(tt.disks, &buf)
+		if tt.expectedError != "" {
+			if err == nil || err.Error() != tt.expectedError {
+				t.Errorf("Test %d, Expected %s, got %v", tt.name, tt.expectedError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d, Unexpected error %v", tt.name, err)
+			continue
+		}
+		if buf.String() != tt.expectedOutput {
+			t.Errorf("Test %d, Expected %q, got %q", tt.name, tt.expectedOutput, buf.String())
+		}
+	}
+}
+
+func TestReloadStorageClassConfig(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testReloadStorageClassConfig)
+}
+
+func testReloadStorageClassConfig(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	// Set globalEndpoints for a single node XL setup.
+	globalEndpoints = mustGetNewEndpointList(dirs...)
+	defer resetGlobalEndpoints()
+	defer resetGlobalStorageEnvs()
+
+	dir, err := ioutil.TempDir("", "minio-storage-class")
+	if err != nil {
+		t.Fatalf("Unable to create temporary directory %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	configFile := filepath.Join(dir, "storageclass.json")
+	os.Setenv(storageClassConfigFileEnv, configFile)
+	defer os.Unsetenv(storageClassConfigFileEnv)
+
+	if err = ioutil.WriteFile(configFile, []byte(`{"standard":"EC:6","rrs":"EC:3"}`), 0644); err != nil {
+		t.Fatalf("Unable to write config file %v", err)
+	}
+	if err = reloadStorageClassConfig(); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if parity := getRedundancyCount(standardStorageClass, len(dirs)).Parity; parity != 6 {
+		t.Errorf("Expected standard parity %d, got %d", 6, parity)
+	}
+
+	// New objects are written with the reloaded parity.
+	if err = ioutil.WriteFile(configFile, []byte(`{"standard":"EC:4","rrs":"EC:2"}`), 0644); err != nil {
+		t.Fatalf("Unable to write config file %v", err)
+	}
+	if err = reloadStorageClassConfig(); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if parity := getRedundancyCount(standardStorageClass, len(dirs)).Parity; parity != 4 {
+		t.Errorf("Expected standard parity %d, got %d", 4, parity)
+	}
+	if !globalIsStorageClass {
+		t.Errorf("Expected storage class to be set")
+	}
+
+	// Invalid storage classes are rejected and the current storage classes retained.
+	if err = ioutil.WriteFile(configFile, []byte(`{"standard":"EC:2","rrs":"EC:3"}`), 0644); err != nil {
+		t.Fatalf("Unable to write config file %v", err)
+	}
+	if err = reloadStorageClassConfig(); err == nil {
+		t.Fatalf("Expected reload to fail")
+	}
+	ssc, rrsc, _ := getStorageClassGlobals()
+	if ssc.Parity != 4 || rrsc.Parity != 2 {
+		t.Errorf("Expected storage classes EC:4 and EC:2 to be retained, got %v and %v", ssc, rrsc)
+	}
+}
+
+func TestCheckStorageClassHealth(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testCheckStorageClassHealth)
+}
+
+func testCheckStorageClassHealth(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	// Set globalEndpoints for a single node XL setup.
+	globalEndpoints = mustGetNewEndpointList(dirs...)
+	defer resetGlobalEndpoints()
+	defer resetGlobalStorageEnvs()
+
+	// Default storage classes are always healthy.
+	if err := checkStorageClassHealth(len(dirs)); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	globalStandardStorageClass = storageClass{Scheme: "EC", Parity: 8}
+	globalRRStorageClass = storageClass{Scheme: "EC", Parity: 3}
+	if err := checkStorageClassHealth(len(dirs)); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	// Only 8 disks are online, STANDARD parity 8 is no longer valid
+	// although the setup still has all its disks.
+	err := checkStorageClassHealth(8)
+	if err == nil {
+		t.Fatalf("Expected storage class health check to fail")
+	}
+	if !strings.HasPrefix(err.Error(), standardStorageClass+": ") {
+		t.Errorf("Expected %s violation, got %v", standardStorageClass, err)
+	}
+
+	// Max durability parity is checked against the online disks as well.
+	globalStandardStorageClass = storageClass{Scheme: "EC", Parity: 3}
+	globalRRStorageClass = storageClass{Scheme: "EC", Parity: 2}
+	globalMaxStorageClass = storageClass{Scheme: "EC", Parity: 6}
+	err = checkStorageClassHealth(8)
+	if err == nil || !strings.HasPrefix(err.Error(), maxDurabilityStorageClass+": ") {
+		t.Errorf("Expected %s violation, got %v", maxDurabilityStorageClass, err)
+	}
+
+	// Parity set as a percentage is resolved for the online disks.
+	globalStandardStorageClass = storageClass{Scheme: "EC", Percent: 50}
+	globalRRStorageClass = storageClass{}
+	globalMaxStorageClass = storageClass{}
+	if err = checkStorageClassHealth(8); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * Minio Cloud Storage, (C) 2017 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+// ParityStrategy - resolves the data and parity disks an object of a
+// storage class in a bucket is laid out on. class is the storage class
+// the object is accounted by, aliases resolved, bucket is empty if the
+// object is not written to a bucket, e.g. for the server wide parity.
+// objSize is the size of the object, or -1 if it is not known, e.g. for
+// multipart uploads. Writes and getRedundancyCount delegate to the
+// installed strategy, see SetParityStrategy. Disks resolved which can't
+// be erasure coded, i.e. no data disk, parity out of minimumParityDisks
+// and N/2 or not adding up to totalDisks, are ignored.
+type ParityStrategy interface {
+	Resolve(bucket, class string, totalDisks int, objSize int64) (data, parity int)
+}
+
+// DefaultParityStrategy - lays out objects with the parity of their
+// storage class as configured, regardless of the object size. Custom
+// strategies may wrap it to only change the parity of some objects.
+type DefaultParityStrategy struct{}
+
+// Resolve - returns the data and parity disks of the storage class
+// in the bucket.
+func (DefaultParityStrategy) Resolve(bucket, class string, totalDisks int, objSize int64) (data, parity int) {
+	info := getBucketRedundancyCount(bucket, class, totalDisks)
+	return info.Data, info.Parity
+}
+
+// Variable holds the parity strategy getRedundancyCount delegates to,
+// guarded by globalStorageClassMu.
+var globalParityStrategy ParityStrategy = DefaultParityStrategy{}
+
+// SetParityStrategy installs the parity strategy getRedundancyCount
+// delegates to, nil installs DefaultParityStrategy.
+func SetParityStrategy(strategy ParityStrategy) {
+	if strategy == nil {
+		strategy = DefaultParityStrategy{}
+	}
+	globalStorageClassMu.Lock()
+	globalParityStrategy = strategy
+	globalStorageClassMu.Unlock()
+}
+
+// Returns the installed parity strategy.
+func getParityStrategy() ParityStrategy {
+	globalStorageClassMu.RLock()
+	defer globalStorageClassMu.RUnlock()
+	return globalParityStrategy
+}
+
+// Returns true if strategy is DefaultParityStrategy.
+func isDefaultParityStrategy(strategy ParityStrategy) bool {
+	_, ok := strategy.(DefaultParityStrategy)
+	return ok
+}
@@ -0,0 +1,205 @@
+/*
+ * Minio Cloud Storage, (C) 2017 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+)
+
+// fixedParityStrategy - lays out all objects with the same parity.
+type fixedParityStrategy struct {
+	parity int
+}
+
+func (s fixedParityStrategy) Resolve(bucket, class string, totalDisks int, objSize int64) (data, parity int) {
+	return totalDisks - s.parity, s.parity
+}
+
+// sizeParityStrategy - lays out objects smaller than threshold with
+// minimumParityDisks and all the other objects with N/2 parity.
+type sizeParityStrategy struct {
+	threshold int64
+}
+
+func (s sizeParityStrategy) Resolve(bucket, class string, totalDisks int, objSize int64) (data, parity int) {
+	parity = maxParityForDisks(totalDisks)
+	if objSize >= 0 && objSize < s.threshold {
+		parity = minimumParityDisks
+	}
+	return totalDisks - parity, parity
+}
+
+// mismatchedParityStrategy - lays out objects on more disks than there are.
+type mismatchedParityStrategy struct{}
+
+func (mismatchedParityStrategy) Resolve(bucket, class string, totalDisks int, objSize int64) (data, parity int) {
+	return totalDisks, minimumParityDisks
+}
+
+// wrappingParityStrategy - lays out objects with DefaultParityStrategy,
+// recording the bucket and storage class it is asked for.
+type wrappingParityStrategy struct {
+	bucket, class *string
+	DefaultParityStrategy
+}
+
+func (s wrappingParityStrategy) Resolve(bucket, class string, totalDisks int, objSize int64) (data, parity int) {
+	*s.bucket, *s.class = bucket, class
+	return s.DefaultParityStrategy.Resolve(bucket, class, totalDisks, objSize)
+}
+
+// Tests the parity strategy is asked for the bucket and the storage class
+// aliases resolved, and drive counts which can't be erasure coded are ignored.
+func TestResolveParityStrategy(t *testing.T) {
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+	globalStorageClassAliases = map[string]string{"GLACIER": reducedRedundancyStorageClass}
+
+	bucket := "bucket"
+	globalBucketStorageClass.SetBucketStorageClass(bucket, &bucketStorageClassConfig{
+		RRS: storageClass{Scheme: supportedStorageClassScheme, Parity: 3},
+	})
+	defer globalBucketStorageClass.SetBucketStorageClass(bucket, nil)
+
+	// Strategy wrapping the default strategy keeps the bucket storage class.
+	var gotBucket, gotClass string
+	SetParityStrategy(wrappingParityStrategy{bucket: &gotBucket, class: &gotClass})
+	info := getObjectSizeRedundancyCount(bucket, "GLACIER", 16, 4)
+	if gotBucket != bucket || gotClass != reducedRedundancyStorageClass {
+		t.Errorf("Expected strategy to be asked for %s/%s, got %s/%s", bucket, reducedRedundancyStorageClass, gotBucket, gotClass)
+	}
+	if info.Data != 13 || info.Parity != 3 {
+		t.Errorf("Expected 13 data and 3 parity disks, got %d data and %d parity disks", info.Data, info.Parity)
+	}
+
+	tests := []struct {
+		name           int
+		strategy       ParityStrategy
+		expectedParity int
+	}{
+		{1, fixedParityStrategy{parity: minimumParityDisks}, minimumParityDisks},
+		{2, fixedParityStrategy{parity: 8}, 8},
+		// Parity out of minimumParityDisks and N/2, bucket parity is used.
+		{3, fixedParityStrategy{parity: 1}, 3},
+		{4, fixedParityStrategy{parity: 9}, 3},
+		// No data disk.
+		{5, fixedParityStrategy{parity: 16}, 3},
+	}
+	for _, tt := range tests {
+		SetParityStrategy(tt.strategy)
+		info := getObjectSizeRedundancyCount(bucket, reducedRedundancyStorageClass, 16, 4)
+		if info.Data != 16-tt.expectedParity || info.Parity != tt.expectedParity {
+			t.Errorf("Test %d, Expected %d data and %d parity disks, got %d data and %d parity disks", tt.name,
+				16-tt.expectedParity, tt.expectedParity, info.Data, info.Parity)
+		}
+	}
+	// Drive counts adding up to more than the total disks.
+	SetParityStrategy(mismatchedParityStrategy{})
+	if info := getObjectSizeRedundancyCount(bucket, reducedRedundancyStorageClass, 16, 4); info.Data != 13 || info.Parity != 3 {
+		t.Errorf("Expected 13 data and 3 parity disks, got %d data and %d parity disks", info.Data, info.Parity)
+	}
+}
+
+// Tests PutObject lays out objects with the parity of the installed
+// parity strategy, resolved for the size of the object.
+func TestPutObjectParityStrategy(t *testing.T) {
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+
+	obj, fsDirs, err := prepareXL16()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+	xl := obj.(*xlObjects)
+
+	bucket := "bucket"
+	if err = obj.MakeBucketWithLocation(bucket, ""); err != nil {
+		t.Fatal(err)
+	}
+	// Bucket storage class is overridden by the strategy.
+	globalBucketStorageClass.SetBucketStorageClass(bucket, &bucketStorageClassConfig{
+		Standard: storageClass{Scheme: supportedStorageClassScheme, Parity: 6},
+	})
+	defer globalBucketStorageClass.SetBucketStorageClass(bucket, nil)
+
+	tests := []struct {
+		name           int
+		strategy       ParityStrategy
+		sc             string
+		data           []byte
+		expectedParity int
+	}{
+		{1, fixedParityStrategy{parity: 3}, standardStorageClass, []byte("abcd"), 3},
+		{2, fixedParityStrategy{parity: 3}, reducedRedundancyStorageClass, []byte("abcd"), 3},
+		{3, fixedParityStrategy{parity: 5}, "", []byte("abcd"), 5},
+		// Size of the object is passed to the strategy.
+		{4, sizeParityStrategy{threshold: 10}, standardStorageClass, []byte("abcd"), minimumParityDisks},
+		{5, sizeParityStrategy{threshold: 10}, standardStorageClass, bytes.Repeat([]byte("a"), 10), 8},
+		// Default strategy lays out objects with the bucket storage class.
+		{6, DefaultParityStrategy{}, standardStorageClass, []byte("abcd"), 6},
+	}
+	for _, tt := range tests {
+		SetParityStrategy(tt.strategy)
+		metadata := map[string]string{}
+		if tt.sc != "" {
+			metadata[amzStorageClass] = tt.sc
+		}
+		if _, err = obj.PutObject(bucket, "object", mustGetHashReader(t, bytes.NewReader(tt.data), int64(len(tt.data)), "", ""), metadata); err != nil {
+			t.Fatalf("Test %d, Unexpected error %v", tt.name, err)
+		}
+		xlMeta, err := readXLMeta(xl.storageDisks[0], bucket, "object")
+		if err != nil {
+			t.Fatalf("Test %d, Unable to read xl.json %v", tt.name, err)
+		}
+		if xlMeta.Erasure.DataBlocks != 16-tt.expectedParity || xlMeta.Erasure.ParityBlocks != tt.expectedParity {
+			t.Errorf("Test %d, Expected %d data and %d parity blocks, got %d and %d", tt.name, 16-tt.expectedParity,
+				tt.expectedParity, xlMeta.Erasure.DataBlocks, xlMeta.Erasure.ParityBlocks)
+		}
+	}
+}
+
+// Tests getRedundancyCount delegates to the installed parity strategy.
+func TestSetParityStrategy(t *testing.T) {
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+
+	// Cached drive counts of the previous strategy are dropped.
+	if info := getRedundancyCount(standardStorageClass, 16); info.Data != 8 || info.Parity != 8 {
+		t.Fatalf("Expected 8 data and 8 parity disks, got %d data and %d parity disks", info.Data, info.Parity)
+	}
+	SetParityStrategy(fixedParityStrategy{parity: 3})
+	for _, sc := range []string{standardStorageClass, reducedRedundancyStorageClass} {
+		info := getRedundancyCount(sc, 16)
+		if info.Data != 13 || info.Parity != 3 {
+			t.Errorf("%s: Expected 13 data and 3 parity disks, got %d data and %d parity disks", sc, info.Data, info.Parity)
+		}
+		if info.Class != sc {
+			t.Errorf("Expected storage class %s, got %s", sc, info.Class)
+		}
+	}
+
+	// nil installs the default strategy again.
+	SetParityStrategy(nil)
+	if !isDefaultParityStrategy(getParityStrategy()) {
+		t.Errorf("Expected default parity strategy to be installed")
+	}
+	if info := getRedundancyCount(standardStorageClass, 16); info.Data != 8 || info.Parity != 8 {
+		t.Errorf("Expected 8 data and 8 parity disks, got %d data and %d parity disks", info.Data, info.Parity)
+	}
+}
@@ -17,10 +17,21 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	humanize "github.com/dustin/go-humanize"
 )
 
 const (
@@ -32,21 +43,190 @@ const (
 	reducedRedundancyStorageClass = "REDUCED_REDUNDANCY"
 	// Standard storage class
 	standardStorageClass = "STANDARD"
+	// Maximum durability storage class
+	maxDurabilityStorageClass = "MAX_DURABILITY"
+	// Scratch storage class, minimum parity for transient data
+	scratchStorageClass = "SCRATCH"
 	// Reduced redundancy storage class environment variable
 	reducedRedundancyStorageClassEnv = "MINIO_STORAGE_CLASS_RRS"
 	// Standard storage class environment variable
 	standardStorageClassEnv = "MINIO_STORAGE_CLASS_STANDARD"
-	// Supported storage class scheme is EC
+	// Maximum durability storage class environment variable
+	maxDurabilityStorageClassEnv = "MINIO_STORAGE_CLASS_MAX"
+	// Storage class aliases environment variable
+	storageClassAliasesEnv = "MINIO_STORAGE_CLASS_ALIASES"
+	// Allow reduced redundancy storage class on 4 disks setup environment variable
+	storageClassAllowSmallEnv = "MINIO_STORAGE_CLASS_ALLOW_SMALL"
+	// Require even parity disks for storage classes environment variable
+	storageClassRequireEvenParityEnv = "MINIO_STORAGE_CLASS_REQUIRE_EVEN_PARITY"
+	// Default reduced redundancy storage class parity environment variable
+	storageClassRRSDefaultEnv = "MINIO_STORAGE_CLASS_RRS_DEFAULT"
+	// Read and write quorum policy environment variable
+	storageClassQuorumEnv = "MINIO_STORAGE_CLASS_QUORUM"
+	// Suppress storage class startup messages environment variable
+	storageClassQuietEnv = "MINIO_STORAGE_CLASS_QUIET"
+	// Storage class config file environment variable
+	storageClassConfigFileEnv = "MINIO_STORAGE_CLASS_CONFIG_FILE"
+	// Print storage class validation errors as JSON environment variable
+	storageClassJSONErrorsEnv = "MINIO_STORAGE_CLASS_JSON_ERRORS"
+	// Audit log storage class of writes environment variable
+	storageClassAuditEnv = "MINIO_STORAGE_CLASS_AUDIT"
+	// Reject storage classes set differently in environment and config file environment variable
+	storageClassStrictEnv = "MINIO_STORAGE_CLASS_STRICT"
+	// Clamp parity exceeding N/2 of the disks, e.g. after scale down, environment variable
+	storageClassClampParityEnv = "MINIO_STORAGE_CLASS_CLAMP_PARITY"
+	// Account quorum margin of objects read environment variable
+	storageClassQuorumStatsEnv = "MINIO_STORAGE_CLASS_QUORUM_STATS"
+	// Default storage class of buckets matching a pattern environment variable
+	storageClassBucketRulesEnv = "MINIO_STORAGE_CLASS_BUCKET_RULES"
+	// Size below which objects of a storage class are written with minimum parity environment variable
+	storageClassSmallObjectParityEnv = "MINIO_STORAGE_CLASS_SMALL_OBJECT_PARITY"
+	// Re-encode healed objects with the current parity of their storage class environment variable
+	storageClassHealReparityEnv = "MINIO_STORAGE_CLASS_HEAL_REPARITY"
+	// Reject Reduced redundancy storage class as a default storage class environment variable
+	storageClassDenyRRSDefaultEnv = "MINIO_STORAGE_CLASS_DENY_RRS_DEFAULT"
+	// Cap of the default N/2 parity of standard storage class environment variable
+	storageClassMaxParityEnv = "MINIO_STORAGE_CLASS_MAX_PARITY"
+	// Default storage class scheme is EC
 	supportedStorageClassScheme = "EC"
 	// Minimum parity disks
 	minimumParityDisks = 2
 	defaultRRSParity   = 2
+	// Minimum data disks, parity disks should leave at least these many data disks
+	minimumDataBlocks = 2
+	// Maximum total disks supported by storage class parity, larger setups
+	// should be split into multiple erasure sets
+	maximumErasureDisks = 256
+	// Setups with more disks than this are warned about N/2 standard parity
+	largeSetupDisks = 16
+	// Maximum parity disks as a percentage of total disks
+	maximumParityPercent = 50
+	// Metadata entry for prior storage classes of an object
+	storageClassHistoryKey = ReservedMetadataPrefix + "Storage-Class-History"
+	// Maximum number of prior storage classes kept in metadata
+	maxStorageClassHistory = 5
+	// Request header to force the parity of an object irrespective of its storage class
+	amzForceParity = "X-Minio-Force-Parity"
+	// User metadata header to set the parity of an object, same as amzForceParity
+	// for clients which can only send user metadata. amzForceParity takes precedence.
+	amzMetaParity = "X-Amz-Meta-Minio-Parity"
+	// Force parity header value for N/2 parity
+	forceParityMax = "max"
+	// Metadata entry for the parity forced while writing an object
+	forceParityKey = ReservedMetadataPrefix + "Force-Parity"
+	// Request header to overwrite an object with a lower parity in a bucket with downgrade protection
+	amzForceDowngrade = "X-Minio-Force-Downgrade"
+	// Metadata entry for a forced downgrade, never saved with the object
+	forceDowngradeKey = ReservedMetadataPrefix + "Force-Downgrade"
+	// Metadata entry for the parity an object smaller than the small object threshold is written with
+	sizeTunedParityKey = ReservedMetadataPrefix + "Size-Tuned-Parity"
 )
 
 // Struct to hold storage class
 type storageClass struct {
 	Scheme string
 	Parity int
+	// Parity disks as a percentage of total disks, set only
+	// if storage class is specified as a percentage.
+	Percent int
+	// Parity disks as a fraction of Standard storage class parity, set
+	// only if storage class is specified relative to it e.g. "EC:x0.5".
+	// Parity is resolved by resolveRelativeParity.
+	Relative float64
+	// Data disks, set only if storage class is specified as data
+	// and parity disks e.g. "EC:8:4".
+	Data int
+	// Read and write quorum of objects in the storage class,
+	// unset means defaultQuorumPolicy.
+	Quorum quorumPolicy
+}
+
+// quorumPolicy - read and write quorum of an object as the number of
+// disks required in addition to the data disks of the object.
+type quorumPolicy struct {
+	ReadOffset  int
+	WriteOffset int
+}
+
+// Default quorum is data disks to read and data disks + 1 to write.
+var defaultQuorumPolicy = quorumPolicy{ReadOffset: 0, WriteOffset: 1}
+
+// Errors returned while parsing a storage class, these are always
+// wrapped in a storageClassError along with the offending input.
+var (
+	errStorageClassTooManySections   = errors.New("Too many sections in storage class")
+	errStorageClassTooFewSections    = errors.New("Too few sections in storage class")
+	errStorageClassUnsupportedScheme = errors.New("Unsupported storage class scheme")
+	errStorageClassInvalidParity     = errors.New("Invalid storage class parity")
+	errStorageClassErasureOnly       = errors.New("Setting storage class only allowed for erasure coding mode")
+)
+
+// errInvalidForceParity - force parity is neither "max" nor a parity between
+// minimumParityDisks and N/2.
+var errInvalidForceParity = errors.New("Invalid force parity")
+
+// storageClassError - error returned when a storage class can not be
+// parsed. Err is one of the errStorageClass* errors and Input is the
+// offending storage class value.
+type storageClassError struct {
+	Err   error
+	Input string
+	msg   string
+}
+
+func (e storageClassError) Error() string {
+	return e.msg
+}
+
+// storageClassScheme - validates and lays out a storage class of a
+// given scheme, schemes are registered in storageClassSchemes.
+type storageClassScheme struct {
+	// Validates a parsed storage class of this scheme.
+	validate func(sc storageClass) error
+	// Returns the data and parity drive count for the storage class.
+	redundancy func(sc storageClass, totalDisks int) (data, parity int)
+}
+
+// All the storage class schemes known to parseStorageClass, keyed by
+// the scheme name used in "Scheme:Parity".
+var storageClassSchemes = map[string]storageClassScheme{
+	supportedStorageClassScheme: {
+		validate:   validateECStorageClass,
+		redundancy: getECRedundancyCount,
+	},
+}
+
+// Returns the sorted list of registered storage class schemes.
+func getStorageClassSchemes() []string {
+	var schemes []string
+	for scheme := range storageClassSchemes {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// Validates an EC storage class, parity is validated against the other
+// storage classes separately by validateSSParity and validateRRSParity.
+func validateECStorageClass(sc storageClass) error {
+	return nil
+}
+
+// Returns the data and parity drive count of an EC storage class. Data
+// disks set explicitly are honored if they add up to totalDisks along
+// with parity, otherwise data disks are derived from parity.
+func getECRedundancyCount(sc storageClass, totalDisks int) (data, parity int) {
+	if sc.Data != 0 && sc.Data+sc.Parity == totalDisks {
+		return sc.Data, sc.Parity
+	}
+	return totalDisks - sc.Parity, sc.Parity
+}
+
+// Returns the maximum parity disks of the given number of disks, N/2.
+// Parity disks are never more than data disks, so that the data disks
+// are a read quorum.
+func maxParityForDisks(disks int) int {
+	return disks / 2
 }
 
 type storageClassConfig struct {
@@ -54,10 +234,374 @@ type storageClassConfig struct {
 	RRS      storageClass `json:"rrs"`
 }
 
+// newStorageClassConfig - returns the default storage class config for
+// the given number of disks, parity is globalRRSDefaultParity for Reduced
+// redundancy storage class and N/2 for Standard storage class, capped at
+// globalStorageClassMaxParity if set.
+func newStorageClassConfig(totalDisks int) storageClassConfig {
+	parity := maxParityForDisks(totalDisks)
+	if globalStorageClassMaxParity > 0 && parity > globalStorageClassMaxParity {
+		parity = globalStorageClassMaxParity
+	}
+	return storageClassConfig{
+		Standard: storageClass{
+			Scheme: supportedStorageClassScheme,
+			Parity: parity,
+		},
+		RRS: storageClass{
+			Scheme: supportedStorageClassScheme,
+			Parity: globalRRSDefaultParity,
+		},
+	}
+}
+
+// Parses the default Reduced redundancy storage class parity, set via
+// MINIO_STORAGE_CLASS_RRS_DEFAULT as the number of parity disks. It is
+// validated the same way as an explicit Reduced redundancy storage class,
+// except that 0 is rejected as the default parity can't itself be the default.
+func parseRRSDefaultParity(value string, ssParity int) (int, error) {
+	parity, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("Parity disks should be a number in %s", value)
+	}
+	if errs := checkRRSParity(parity, ssParity, len(globalEndpoints)); len(errs) > 0 {
+		return 0, errs[0]
+	}
+	return parity, nil
+}
+
+// Parses the cap of the default N/2 parity of Standard storage class, set via
+// MINIO_STORAGE_CLASS_MAX_PARITY as the number of parity disks. The cap can't
+// be lower than minimumParityDisks, so that objects written with the default
+// parity can always survive the loss of minimumParityDisks disks.
+func parseMaxParity(value string) (int, error) {
+	parity, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("Parity disks should be a number in %s", value)
+	}
+	if parity < minimumParityDisks {
+		return 0, fmt.Errorf("Maximum parity should be greater than or equal to %d, found %d", minimumParityDisks, parity)
+	}
+	return parity, nil
+}
+
+// Returns the quorum policy in effect, an unset quorum policy is defaultQuorumPolicy.
+func (q quorumPolicy) effective() quorumPolicy {
+	if q == (quorumPolicy{}) {
+		return defaultQuorumPolicy
+	}
+	return q
+}
+
+// String - returns the quorum policy in the same format as
+// MINIO_STORAGE_CLASS_QUORUM e.g. "0:1".
+func (q quorumPolicy) String() string {
+	return fmt.Sprintf("%d:%d", q.ReadOffset, q.WriteOffset)
+}
+
+// Returns true if both the storage classes have the same parity. A storage
+// class which is not set only equals another storage class which is not set,
+// irrespective of their other fields.
+func (sc storageClass) equalParity(other storageClass) bool {
+	if sc.Scheme == "" || other.Scheme == "" {
+		return sc.Scheme == other.Scheme
+	}
+	return sc.Scheme == other.Scheme && sc.Parity == other.Parity && sc.Percent == other.Percent &&
+		sc.Relative == other.Relative
+}
+
+// Returns true if both the storage classes have the same quorum policy
+// in effect, quorum policy of storage classes not set is not compared.
+func (sc storageClass) equalQuorum(other storageClass) bool {
+	if sc.Scheme == "" || other.Scheme == "" {
+		return true
+	}
+	return sc.Quorum.effective() == other.Quorum.effective()
+}
+
+// Equal - returns true if the Standard and Reduced redundancy storage
+// classes of both the configs are the same.
+func (sCfg storageClassConfig) Equal(other storageClassConfig) bool {
+	return sCfg.Diff(other) == ""
+}
+
+// Diff - returns the differences from sCfg to other in a human readable
+// form, e.g. "standard: EC:4 -> EC:6; rrs quorum: 0:1 -> 0:2", or an empty
+// string if both the configs are the same.
+func (sCfg storageClassConfig) Diff(other storageClassConfig) string {
+	var diffs []string
+	for _, cmp := range []struct {
+		name      string
+		sc, other storageClass
+	}{
+		{"standard", sCfg.Standard, other.Standard},
+		{"rrs", sCfg.RRS, other.RRS},
+	} {
+		if !cmp.sc.equalParity(cmp.other) {
+			diffs = append(diffs, fmt.Sprintf("%s: %s -> %s", cmp.name, cmp.sc, cmp.other))
+		}
+		if !cmp.sc.equalQuorum(cmp.other) {
+			diffs = append(diffs, fmt.Sprintf("%s quorum: %s -> %s", cmp.name, cmp.sc.Quorum.effective(), cmp.other.Quorum.effective()))
+		}
+	}
+	return strings.Join(diffs, "; ")
+}
+
+// MarshalJSON - converts storageClassConfig into JSON data, storage
+// classes are always written in their "Scheme:Parity" text form.
+func (sCfg storageClassConfig) MarshalJSON() ([]byte, error) {
+	standard, err := sCfg.Standard.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	rrs, err := sCfg.RRS.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		Standard string `json:"standard"`
+		RRS      string `json:"rrs"`
+	}{string(standard), string(rrs)})
+}
+
+// UnmarshalJSON - parses the storage classes and validates the parity of
+// both storage classes against each other, so that an invalid config.json
+// is rejected when it is loaded rather than on the first write.
+func (sCfg *storageClassConfig) UnmarshalJSON(data []byte) error {
+	type subStorageClassConfig storageClassConfig
+	var cfg subStorageClassConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return err
+	}
+
+	ssParity, rrsParity := cfg.Standard.Parity, cfg.RRS.Parity
+	var msgs []string
+	if err := validateStorageClassSchemes(cfg.Standard.Scheme, cfg.RRS.Scheme); err != nil {
+		msgs = append(msgs, err.Error())
+	}
+	if len(globalEndpoints) == 0 {
+		// Disks are not known yet (e.g. gateway mode), only
+		// the parity of the storage classes can be compared.
+		if rrsParity != 0 && ssParity != 0 && rrsParity >= ssParity {
+			msgs = append(msgs, fmt.Sprintf("%s: Reduced redundancy storage class parity disks should be less than %d",
+				reducedRedundancyStorageClass, ssParity))
+		}
+	} else {
+		if cfg.RRS.Scheme != "" {
+			for _, err := range checkRRSParity(rrsParity, ssParity, len(globalEndpoints)) {
+				msgs = append(msgs, fmt.Sprintf("%s: %s", reducedRedundancyStorageClass, err))
+			}
+		}
+		if cfg.Standard.Scheme != "" {
+			for _, err := range checkSSParity(ssParity, rrsParity, len(globalEndpoints)) {
+				msgs = append(msgs, fmt.Sprintf("%s: %s", standardStorageClass, err))
+			}
+		}
+	}
+	if len(msgs) > 0 {
+		return errors.New("Invalid storage class config: " + strings.Join(msgs, "; "))
+	}
+
+	*sCfg = storageClassConfig(cfg)
+	return nil
+}
+
+// List of all the storage classes supported by Minio,
+// new storage classes should be registered here.
+var validStorageClasses = []string{
+	standardStorageClass,
+	reducedRedundancyStorageClass,
+	maxDurabilityStorageClass,
+	scratchStorageClass,
+}
+
+// ValidStorageClasses returns the names of all the storage classes
+// supported by Minio, storage class aliases are not included.
+func ValidStorageClasses() []string {
+	return append([]string(nil), validStorageClasses...)
+}
+
 // Validate if storage class in metadata
-// Only Standard and RRS Storage classes are supported
+// Only Standard, RRS, Max durability and Scratch Storage classes and their aliases are supported
 func isValidStorageClassMeta(sc string) bool {
-	return sc == reducedRedundancyStorageClass || sc == standardStorageClass
+	sc = getStorageClassFromAlias(sc)
+	// Scratch storage class is only meaningful with erasure coding,
+	// disks < 4 means this is not a erasure coded setup
+	if sc == scratchStorageClass && len(globalEndpoints) < 4 {
+		return false
+	}
+	return isSupportedStorageClass(sc)
+}
+
+// Returns the API error for the storage class sent in x-amz-storage-class
+// header of a request to the object layer. Without erasure coding there is
+// no parity to lay out, so only STANDARD storage class is accepted and
+// saved as is, other supported storage classes are rejected.
+func checkStorageClassHeader(objAPI ObjectLayer, sc string) APIErrorCode {
+	if _, ok := objAPI.(*xlObjects); ok {
+		if !isValidStorageClassMeta(sc) {
+			return ErrInvalidStorageClass
+		}
+		return ErrNone
+	}
+	switch sc = getStorageClassFromAlias(sc); {
+	case sc == standardStorageClass:
+		return ErrNone
+	case isSupportedStorageClass(sc):
+		return ErrStorageClassNotSupported
+	}
+	return ErrInvalidStorageClass
+}
+
+// Returns true if sc is one of the storage classes supported by Minio.
+func isSupportedStorageClass(sc string) bool {
+	for _, validSc := range validStorageClasses {
+		if sc == validSc {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the storage class an alias maps to, storage classes
+// which are not an alias are returned as is. Some clients send the
+// storage class in lower or mixed case e.g. "standard", supported
+// storage classes and aliases are matched case-insensitively and
+// returned in their canonical upper case form.
+func getStorageClassFromAlias(sc string) string {
+	if alias, ok := globalStorageClassAliases[sc]; ok {
+		return alias
+	}
+	if upper := strings.ToUpper(sc); upper != sc {
+		if isSupportedStorageClass(upper) {
+			return upper
+		}
+		if alias, ok := globalStorageClassAliases[upper]; ok {
+			return alias
+		}
+	}
+	return sc
+}
+
+// Parses given storageClassAliasesEnv and returns a map of aliases to
+// storage classes. Supported format is a comma separated list of
+// "Alias=Storage class" e.g. "GLACIER=REDUCED_REDUNDANCY,ONEZONE_IA=STANDARD".
+func parseStorageClassAliases(storageClassAliasesEnv string) (map[string]string, error) {
+	aliases := make(map[string]string)
+	for _, entry := range strings.Split(storageClassAliasesEnv, ",") {
+		s := strings.Split(entry, "=")
+		if len(s) != 2 || s[0] == "" {
+			return nil, errors.New("Invalid storage class alias " + entry + ". Supported format is ALIAS=STORAGE_CLASS")
+		}
+		alias, sc := s[0], s[1]
+		if isSupportedStorageClass(alias) {
+			return nil, errors.New("Storage class alias " + alias + " can not be a supported storage class")
+		}
+		if !isSupportedStorageClass(sc) {
+			return nil, errors.New("Unsupported storage class " + sc + " for alias " + alias)
+		}
+		aliases[alias] = sc
+	}
+	return aliases, nil
+}
+
+// bucketStorageClassRule - default storage class of buckets whose
+// name matches Pattern, a pattern as supported by path.Match.
+type bucketStorageClassRule struct {
+	Pattern      string
+	StorageClass string
+}
+
+// Parses given storageClassBucketRulesEnv and returns the list of bucket
+// storage class rules in the same order. Supported format is a comma
+// separated list of "Pattern=Storage class" e.g. "logs-*=REDUCED_REDUNDANCY,
+// tmp-*=SCRATCH". Storage class aliases are accepted as storage class.
+func parseBucketStorageClassRules(storageClassBucketRulesEnv string) ([]bucketStorageClassRule, error) {
+	var rules []bucketStorageClassRule
+	for _, entry := range strings.Split(storageClassBucketRulesEnv, ",") {
+		s := strings.Split(entry, "=")
+		if len(s) != 2 || s[0] == "" {
+			return nil, errors.New("Invalid bucket storage class rule " + entry + ". Supported format is PATTERN=STORAGE_CLASS")
+		}
+		pattern, sc := s[0], s[1]
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.New("Invalid bucket pattern " + pattern + ": " + err.Error())
+		}
+		if !isValidStorageClassMeta(sc) {
+			return nil, errors.New("Unsupported storage class " + sc + " for bucket pattern " + pattern)
+		}
+		if err := checkDefaultStorageClass(sc); err != nil {
+			return nil, errors.New(err.Error() + ", found for bucket pattern " + pattern)
+		}
+		rules = append(rules, bucketStorageClassRule{Pattern: pattern, StorageClass: sc})
+	}
+	return rules, nil
+}
+
+// Returns the storage class of the first bucket storage class rule
+// matching the bucket, or an empty string if no rule matches.
+func matchBucketStorageClassRules(rules []bucketStorageClassRule, bucket string) string {
+	for _, rule := range rules {
+		if ok, _ := path.Match(rule.Pattern, bucket); ok {
+			return rule.StorageClass
+		}
+	}
+	return ""
+}
+
+// Parses given storageClassSmallObjectParityEnv and returns the size per
+// storage class below which objects are written with minimumParityDisks
+// parity. Supported format is a comma separated list of "Storage class=Size"
+// e.g. "STANDARD=1MiB,REDUCED_REDUNDANCY=64KiB".
+func parseSmallObjectParityThresholds(storageClassSmallObjectParityEnv string) (map[string]int64, error) {
+	thresholds := make(map[string]int64)
+	for _, entry := range strings.Split(storageClassSmallObjectParityEnv, ",") {
+		s := strings.Split(entry, "=")
+		if len(s) != 2 {
+			return nil, errors.New("Invalid small object parity threshold " + entry + ". Supported format is STORAGE_CLASS=SIZE")
+		}
+		sc, size := s[0], s[1]
+		if !isSupportedStorageClass(sc) {
+			return nil, errors.New("Unsupported storage class " + sc + " for small object parity threshold")
+		}
+		threshold, err := humanize.ParseBytes(size)
+		if err != nil || threshold == 0 || threshold > uint64(globalMaxObjectSize) {
+			return nil, errors.New("Invalid small object parity threshold " + size + " for storage class " + sc)
+		}
+		thresholds[sc] = int64(threshold)
+	}
+	return thresholds, nil
+}
+
+// Loads the storage classes from a JSON file in the same format as the
+// storage class section of config.json e.g. {"standard":"EC:4","rrs":"EC:2"}.
+// Parity of the storage classes is validated by the caller, once the storage
+// class environment variables are merged, errors name the file and field.
+func loadStorageClassConfigFile(configFile string) (sCfg storageClassConfig, err error) {
+	data, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return sCfg, err
+	}
+
+	var fileCfg struct {
+		Standard string `json:"standard"`
+		RRS      string `json:"rrs"`
+	}
+	if err = json.Unmarshal(data, &fileCfg); err != nil {
+		return sCfg, fmt.Errorf("Unable to parse storage class config file %s: %v", configFile, err)
+	}
+
+	if fileCfg.Standard != "" {
+		if sCfg.Standard, err = parseStorageClass(fileCfg.Standard); err != nil {
+			return sCfg, fmt.Errorf("Invalid field standard in storage class config file %s: %v", configFile, err)
+		}
+	}
+	if fileCfg.RRS != "" {
+		if sCfg.RRS, err = parseStorageClass(fileCfg.RRS); err != nil {
+			return sCfg, fmt.Errorf("Invalid field rrs in storage class config file %s: %v", configFile, err)
+		}
+	}
+	return sCfg, nil
 }
 
 func (sc *storageClass) UnmarshalText(b []byte) error {
@@ -67,95 +611,368 @@ func (sc *storageClass) UnmarshalText(b []byte) error {
 		if err != nil {
 			return err
 		}
+		// Storage classes in config.json and bucket configs are only
+		// read by a running server, resolve them for its disks.
+		s = resolvePercentParity(s, len(globalEndpoints))
 		sc.Parity = s.Parity
 		sc.Scheme = s.Scheme
+		sc.Percent = s.Percent
+		sc.Relative = s.Relative
+		sc.Data = s.Data
 	} else {
-		sc = &storageClass{}
+		// Empty value clears any previously set storage class.
+		sc.Parity = 0
+		sc.Scheme = ""
+		sc.Percent = 0
+		sc.Relative = 0
+		sc.Data = 0
 	}
 
 	return nil
 }
 
+// MarshalText - returns the storage class in the format accepted by
+// UnmarshalText. A storage class without a scheme is unset and marshals
+// to an empty string, a scheme is always kept even with zero parity so
+// that the storage class survives a round-trip through config.json.
 func (sc *storageClass) MarshalText() ([]byte, error) {
-	if sc.Scheme != "" && sc.Parity != 0 {
-		return []byte(fmt.Sprintf("%s:%d", sc.Scheme, sc.Parity)), nil
+	if sc.Scheme == "" {
+		return []byte(""), nil
+	}
+	if sc.Percent != 0 {
+		return []byte(fmt.Sprintf("%s:%d%%", sc.Scheme, sc.Percent)), nil
+	}
+	if sc.Relative != 0 {
+		return []byte(fmt.Sprintf("%s:x%s", sc.Scheme, strconv.FormatFloat(sc.Relative, 'g', -1, 64))), nil
 	}
-	return []byte(""), nil
+	if sc.Data != 0 {
+		return []byte(fmt.Sprintf("%s:%d:%d", sc.Scheme, sc.Data, sc.Parity)), nil
+	}
+	return []byte(fmt.Sprintf("%s:%d", sc.Scheme, sc.Parity)), nil
+}
+
+// String - returns the storage class in the same format as MarshalText
+// e.g. "EC:4", or "<unset>" if the storage class is not set.
+func (sc storageClass) String() string {
+	if sc.Scheme == "" {
+		return "<unset>"
+	}
+	text, _ := sc.MarshalText()
+	return string(text)
 }
 
 // Parses given storageClassEnv and returns a storageClass structure.
-// Supported Storage Class format is "Scheme:Number of parity disks".
-// Currently only supported scheme is "EC".
+// Supported Storage Class format is "Scheme:Number of parity disks",
+// "Scheme:Percentage of total disks%", "Scheme:xFraction of Standard
+// storage class parity" or "Scheme:Number of data disks:Number of parity
+// disks" e.g. "EC:4", "EC:25%", "EC:x0.5" or "EC:12:4". Parity of the
+// percentage and relative formats is left unset until resolved by
+// resolvePercentParity and resolveRelativeParity. Parsing doesn't depend
+// on the disks, data and parity disks are checked to add up to the disks
+// by checkStorageClassDisks once the disks are known.
+// Scheme must be one of the registered storageClassSchemes, default is "EC".
 func parseStorageClass(storageClassEnv string) (sc storageClass, err error) {
 	s := strings.Split(storageClassEnv, ":")
 
-	// only two elements allowed in the string - "scheme" and "number of parity disks"
-	if len(s) > 2 {
-		return storageClass{}, errors.New("Too many sections in " + storageClassEnv)
+	// only two or three elements allowed in the string - "scheme", optionally
+	// "number of data disks" and "number of parity disks"
+	if len(s) > 3 {
+		return storageClass{}, storageClassError{errStorageClassTooManySections, storageClassEnv, "Too many sections in " + storageClassEnv}
 	} else if len(s) < 2 {
-		return storageClass{}, errors.New("Too few sections in " + storageClassEnv)
+		return storageClass{}, storageClassError{errStorageClassTooFewSections, storageClassEnv, "Too few sections in " + storageClassEnv}
 	}
 
-	// only allowed scheme is "EC"
-	if s[0] != supportedStorageClassScheme {
-		return storageClass{}, errors.New("Unsupported scheme " + s[0] + ". Supported scheme is EC")
+	// only registered schemes are allowed
+	scheme, ok := storageClassSchemes[s[0]]
+	if !ok {
+		return storageClass{}, storageClassError{errStorageClassUnsupportedScheme, storageClassEnv,
+			"Unsupported scheme " + s[0] + ". Supported scheme is " + strings.Join(getStorageClassSchemes(), ", ")}
 	}
 
-	// Number of parity disks should be integer
-	parityDisks, err := strconv.Atoi(s[1])
-	if err != nil {
-		return storageClass{}, err
+	// Parity may be specified along with data disks, as a percentage
+	// of total disks or as a fraction of Standard storage class parity
+	if len(s) == 3 {
+		dataDisks, err := strconv.Atoi(s[1])
+		if err != nil && !isErrNumRange(err) {
+			return storageClass{}, storageClassError{errStorageClassInvalidParity, storageClassEnv, err.Error()}
+		}
+		parityDisks, perr := strconv.Atoi(s[2])
+		if perr != nil && !isErrNumRange(perr) {
+			return storageClass{}, storageClassError{errStorageClassInvalidParity, storageClassEnv, perr.Error()}
+		}
+		if err != nil || dataDisks <= 0 || dataDisks > maxErasureBlocks {
+			return storageClass{}, storageClassError{errStorageClassInvalidParity, storageClassEnv,
+				"Data disks should be between 1 and " + strconv.Itoa(maxErasureBlocks) + " in " + storageClassEnv}
+		}
+		if perr != nil || parityDisks <= 0 || parityDisks > maxErasureBlocks {
+			return storageClass{}, storageClassError{errStorageClassInvalidParity, storageClassEnv,
+				"Parity disks should be between 1 and " + strconv.Itoa(maxErasureBlocks) + " in " + storageClassEnv}
+		}
+		sc = storageClass{
+			Scheme: s[0],
+			Data:   dataDisks,
+			Parity: parityDisks,
+		}
+	} else if strings.HasPrefix(s[1], "x") {
+		relative, err := strconv.ParseFloat(strings.TrimPrefix(s[1], "x"), 64)
+		if err != nil || !(relative > 0 && relative <= 1) {
+			return storageClass{}, storageClassError{errStorageClassInvalidParity, storageClassEnv,
+				"Relative parity should be greater than 0 and less than or equal to 1 in " + storageClassEnv}
+		}
+		sc = storageClass{
+			Scheme:   s[0],
+			Relative: relative,
+		}
+	} else if strings.HasSuffix(s[1], "%") {
+		percent, err := strconv.Atoi(strings.TrimSuffix(s[1], "%"))
+		if err != nil && !isErrNumRange(err) {
+			return storageClass{}, storageClassError{errStorageClassInvalidParity, storageClassEnv, err.Error()}
+		}
+		// Out of range values are reported as an invalid percentage.
+		if err != nil || percent <= 0 || percent > maximumParityPercent {
+			return storageClass{}, storageClassError{errStorageClassInvalidParity, storageClassEnv,
+				"Parity percentage should be greater than 0% and less than or equal to " + strconv.Itoa(maximumParityPercent) + "% in " + storageClassEnv}
+		}
+		sc = storageClass{
+			Scheme:  s[0],
+			Percent: percent,
+		}
+	} else {
+		// Number of parity disks should be integer
+		parityDisks, err := strconv.Atoi(s[1])
+		if err != nil && !isErrNumRange(err) {
+			return storageClass{}, storageClassError{errStorageClassInvalidParity, storageClassEnv, err.Error()}
+		}
+		// Parity can never be more than the maximum number of disks, reject
+		// out of range values here instead of failing the N/2 validation.
+		if err != nil || parityDisks < 0 || parityDisks > maxErasureBlocks {
+			return storageClass{}, storageClassError{errStorageClassInvalidParity, storageClassEnv,
+				"Parity disks should be between 0 and " + strconv.Itoa(maxErasureBlocks) + " in " + storageClassEnv}
+		}
+
+		sc = storageClass{
+			Scheme: s[0],
+			Parity: parityDisks,
+		}
 	}
 
-	sc = storageClass{
-		Scheme: s[0],
-		Parity: parityDisks,
+	if err = scheme.validate(sc); err != nil {
+		return storageClass{}, storageClassError{errStorageClassInvalidParity, storageClassEnv, err.Error()}
 	}
 
 	return sc, nil
 }
 
-// Validates the parity disks for Reduced Redundancy storage class
+// Returns the Reduced redundancy storage class with the parity of the relative
+// format resolved against the Standard storage class parity, N/2 if Standard
+// storage class is not set. Parity is floored and never drops below
+// minimumParityDisks, it is validated by the caller like any other parity.
+func resolveRelativeParity(rrsc, ssc storageClass, disks int) storageClass {
+	if rrsc.Relative == 0 {
+		return rrsc
+	}
+	ssParity := ssc.Parity
+	if ssc.Scheme == "" || ssParity == 0 {
+		ssParity = newStorageClassConfig(disks).Standard.Parity
+	}
+	rrsc.Parity = int(rrsc.Relative * float64(ssParity))
+	if rrsc.Parity < minimumParityDisks {
+		rrsc.Parity = minimumParityDisks
+	}
+	return rrsc
+}
+
+// Returns an error if a storage class other than Reduced redundancy storage
+// class is relative, only Reduced redundancy is relative to Standard parity.
+func checkRelativeStorageClasses(ssc, maxsc storageClass) error {
+	for _, class := range []struct {
+		name string
+		sc   storageClass
+	}{
+		{standardStorageClass, ssc},
+		{maxDurabilityStorageClass, maxsc},
+	} {
+		if class.sc.Relative != 0 {
+			return fmt.Errorf("Relative parity %s is only supported for %s storage class, not for %s",
+				class.sc, reducedRedundancyStorageClass, class.name)
+		}
+	}
+	return nil
+}
+
+// Returns true if err is a strconv error for a value out of range.
+func isErrNumRange(err error) bool {
+	numErr, ok := err.(*strconv.NumError)
+	return ok && numErr.Err == strconv.ErrRange
+}
+
+// Returns the parity disks for a given percentage of total disks, rounded
+// to the nearest integer. Parity never drops below minimumParityDisks and
+// never goes above N/2, so that 50% always means N/2 even for odd disks.
+func getParityFromPercent(percent, disks int) int {
+	parity := (disks*percent + 50) / 100
+	if maxParity := maxParityForDisks(disks); parity > maxParity {
+		parity = maxParity
+	}
+	if parity < minimumParityDisks {
+		parity = minimumParityDisks
+	}
+	return parity
+}
+
+// Returns the storage class with the parity of the percentage format resolved
+// against the given number of disks, e.g. the disks of the erasure set an
+// object is written to rather than all the disks of the server. Parity of
+// other formats doesn't depend on the number of disks and is left as is.
+func resolvePercentParity(sc storageClass, disks int) storageClass {
+	if sc.Percent != 0 {
+		sc.Parity = getParityFromPercent(sc.Percent, disks)
+	}
+	return sc
+}
+
+// Returns an error if the data and parity disks of a storage class set as
+// "Scheme:Data:Parity" don't add up to disks, as objects are erasure coded
+// across all the disks. Storage classes of other formats are always valid.
+func checkStorageClassDisks(sc storageClass, disks int) error {
+	if sc.Data != 0 && sc.Data+sc.Parity != disks {
+		return fmt.Errorf("Data and parity disks should add up to %d disks, found %d in %s", disks, sc.Data+sc.Parity, sc)
+	}
+	return nil
+}
+
+// Returns true if Reduced redundancy storage class is allowed on a 4 disks
+// setup by the operator via MINIO_STORAGE_CLASS_ALLOW_SMALL.
+func isSmallRRSAllowed(disks int) bool {
+	return disks == 4 && globalStorageClassAllowSmall
+}
+
+// Returns an error if disks exceeds the maximum disks of an erasure set
+func checkMaximumErasureDisks(disks int) error {
+	if disks > maximumErasureDisks {
+		return fmt.Errorf("Storage class supports at most %d disks per erasure set, found %d disks. Use multiple erasure sets for larger setups",
+			maximumErasureDisks, disks)
+	}
+	return nil
+}
+
+// ValidateParity validates the parity disks of a storage class for the given
+// number of disks. other is the parity of the storage class the class is
+// ordered against, 0 if it is not set, i.e. Reduced redundancy parity for
+// Standard storage class and Standard parity for Reduced redundancy and Max
+// durability storage classes. Parity 0 e.g. "EC:0" explicitly means the
+// default parity and is always valid. Returns the first violation, see
+// checkParity for all of them.
+func ValidateParity(class string, parity, disks, other int) error {
+	if parity == 0 {
+		return nil
+	}
+	if errs := checkParity(class, parity, disks, other); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// Returns all the violations of the parity disks of a storage class, the
+// rules shared by all storage classes are checked first followed by the
+// rules of the storage class.
+func checkParity(class string, parity, disks, other int) []error {
+	// disks < 4 means this is not a erasure coded setup and so storage class is not supported
+	if disks < 4 {
+		return []error{errStorageClassErasureOnly}
+	}
+	switch class {
+	case reducedRedundancyStorageClass:
+		return checkRRSParity(parity, other, disks)
+	case standardStorageClass:
+		return checkSSParity(parity, other, disks)
+	case maxDurabilityStorageClass:
+		return checkMaxParity(parity, other, disks)
+	}
+	return []error{fmt.Errorf("Parity is not supported for %s storage class", class)}
+}
+
+// Validates the parity disks for Reduced Redundancy storage class.
 func validateRRSParity(rrsParity, ssParity int) (err error) {
-	disks := len(globalEndpoints)
+	return ValidateParity(reducedRedundancyStorageClass, rrsParity, len(globalEndpoints), ssParity)
+}
+
+// Returns all the violations of the parity disks for Reduced Redundancy storage class
+func checkRRSParity(rrsParity, ssParity, disks int) (errs []error) {
 	// disks < 4 means this is not a erasure coded setup and so storage class is not supported
 	if disks < 4 {
-		return fmt.Errorf("Setting storage class only allowed for erasure coding mode")
+		return []error{errStorageClassErasureOnly}
+	}
+
+	// Erasure sets larger than maximumErasureDisks are not supported
+	if err := checkMaximumErasureDisks(disks); err != nil {
+		return []error{err}
 	}
 
-	// Reduced redundancy storage class is not supported for 4 disks erasure coded setup.
-	if disks == 4 && rrsParity != 0 {
-		return fmt.Errorf("Reduced redundancy storage class not supported for " + strconv.Itoa(disks) + " disk setup")
+	// Reduced redundancy storage class is not supported for 4 disks erasure coded setup,
+	// unless explicitly allowed by the operator.
+	if disks == 4 && rrsParity != 0 && !isSmallRRSAllowed(disks) {
+		errs = append(errs, fmt.Errorf("Reduced redundancy storage class not supported for "+strconv.Itoa(disks)+" disk setup"))
 	}
 
 	// RRS parity disks should be greater than or equal to minimumParityDisks. Parity below minimumParityDisks is not recommended.
 	if rrsParity < minimumParityDisks {
-		return fmt.Errorf("Reduced redundancy storage class parity should be greater than or equal to " + strconv.Itoa(minimumParityDisks))
+		errs = append(errs, fmt.Errorf("Reduced redundancy storage class parity should be greater than or equal to "+strconv.Itoa(minimumParityDisks)))
 	}
 
 	// Reduced redundancy implies lesser parity than standard storage class. So, RRS parity disks should be
 	// - less than N/2, if StorageClass parity is not set.
 	// - less than StorageClass Parity, if Storage class parity is set.
-	switch ssParity {
-	case 0:
-		if rrsParity >= disks/2 {
-			return fmt.Errorf("Reduced redundancy storage class parity disks should be less than " + strconv.Itoa(disks/2))
-		}
-	default:
-		if rrsParity >= ssParity {
-			return fmt.Errorf("Reduced redundancy storage class parity disks should be less than " + strconv.Itoa(ssParity))
+	// On a 4 disks setup where RRS is allowed there is no room for lesser parity, so RRS parity
+	// may be equal to Standard parity.
+	if ssParity == 0 {
+		ssParity = newStorageClassConfig(disks).Standard.Parity
+	}
+	if isSmallRRSAllowed(disks) {
+		if rrsParity > ssParity {
+			errs = append(errs, fmt.Errorf("Reduced redundancy storage class parity disks should be less than or equal to %d", ssParity))
 		}
+	} else if rrsParity >= ssParity {
+		errs = append(errs, fmt.Errorf("Reduced redundancy storage class parity disks should be less than "+strconv.Itoa(ssParity)))
 	}
 
-	return nil
+	// RRS parity disks should leave at least minimumDataBlocks data disks.
+	if disks-rrsParity < minimumDataBlocks {
+		errs = append(errs, fmt.Errorf("Reduced redundancy storage class parity disks should be less than or equal to %d, to leave at least %d data disks",
+			disks-minimumDataBlocks, minimumDataBlocks))
+	}
+
+	// RRS parity disks should be even if required by the operator.
+	if globalStorageClassRequireEvenParity && rrsParity%2 != 0 {
+		errs = append(errs, oddParityError("Reduced redundancy storage class", rrsParity, func(parity int) []error {
+			return checkRRSParity(parity, ssParity, disks)
+		}))
+	}
+
+	return errs
 }
 
-// Validates the parity disks for Standard storage class
+// Returns true if minimumDataBlocks rather than N/2 limits the parity disks
+// of the given number of disks.
+func isDataBlocksLimitBinding(disks int) bool {
+	return disks-minimumDataBlocks <= maxParityForDisks(disks)
+}
+
+// Validates the parity disks for Standard storage class.
 func validateSSParity(ssParity, rrsParity int) (err error) {
-	disks := len(globalEndpoints)
+	return ValidateParity(standardStorageClass, ssParity, len(globalEndpoints), rrsParity)
+}
+
+// Returns all the violations of the parity disks for Standard storage class
+func checkSSParity(ssParity, rrsParity, disks int) (errs []error) {
 	// disks < 4 means this is not a erasure coded setup and so storage class is not supported
 	if disks < 4 {
-		return fmt.Errorf("Setting storage class only allowed for erasure coding mode")
+		return []error{errStorageClassErasureOnly}
+	}
+
+	// Erasure sets larger than maximumErasureDisks are not supported
+	if err := checkMaximumErasureDisks(disks); err != nil {
+		return []error{err}
 	}
 
 	// Standard storage class implies more parity than Reduced redundancy storage class. So, Standard storage parity disks should be
@@ -164,68 +981,1450 @@ func validateSSParity(ssParity, rrsParity int) (err error) {
 	switch rrsParity {
 	case 0:
 		if ssParity < minimumParityDisks {
-			return fmt.Errorf("Standard storage class parity disks should be greater than or equal to " + strconv.Itoa(minimumParityDisks))
+			errs = append(errs, fmt.Errorf("Standard storage class parity disks should be greater than or equal to "+strconv.Itoa(minimumParityDisks)))
 		}
 	default:
-		if ssParity <= rrsParity {
-			return fmt.Errorf("Standard storage class parity disks should be greater than " + strconv.Itoa(rrsParity))
+		if isSmallRRSAllowed(disks) {
+			if ssParity < rrsParity {
+				errs = append(errs, fmt.Errorf("Standard storage class parity disks should be greater than or equal to %d", rrsParity))
+			}
+		} else if ssParity <= rrsParity {
+			errs = append(errs, fmt.Errorf("Standard storage class parity disks should be greater than "+strconv.Itoa(rrsParity)))
+		}
+	}
+
+	// Standard storage class parity should be less than or equal to N/2 and should leave at least
+	// minimumDataBlocks data disks. N/2 is the binding limit except on the smallest setups, where
+	// the data disks floor is reported instead.
+	if isDataBlocksLimitBinding(disks) {
+		if disks-ssParity < minimumDataBlocks {
+			errs = append(errs, fmt.Errorf("Standard storage class parity disks should be less than or equal to %d, to leave at least %d data disks",
+				disks-minimumDataBlocks, minimumDataBlocks))
 		}
+	} else if ssParity > maxParityForDisks(disks) {
+		errs = append(errs, fmt.Errorf("Standard storage class parity disks should be less than or equal to "+strconv.Itoa(maxParityForDisks(disks))))
 	}
 
-	// Standard storage class parity should be less than or equal to N/2
-	if ssParity > disks/2 {
-		return fmt.Errorf("Standard storage class parity disks should be less than or equal to " + strconv.Itoa(disks/2))
+	// Standard storage class parity disks should be even if required by the operator.
+	if globalStorageClassRequireEvenParity && ssParity%2 != 0 {
+		errs = append(errs, oddParityError("Standard storage class", ssParity, func(parity int) []error {
+			return checkSSParity(parity, rrsParity, disks)
+		}))
 	}
 
-	return nil
+	return errs
 }
 
-// Returns the data and parity drive count based on storage class
-// If storage class is set using the env vars MINIO_STORAGE_CLASS_RRS and MINIO_STORAGE_CLASS_STANDARD
-// -- corresponding values are returned
-// If storage class is not set using environment variables, default values are returned
-// -- Default for Reduced Redundancy Storage class is, parity = 2 and data = N-Parity
-// -- Default for Standard Storage class is, parity = N/2, data = N/2
-// If storage class is not present in metadata, default value is data = N/2, parity = N/2
-func getRedundancyCount(sc string, totalDisks int) (data, parity int) {
-	parity = totalDisks / 2
-	switch sc {
-	case reducedRedundancyStorageClass:
-		if globalRRStorageClass.Parity != 0 {
-			// set the rrs parity if available
-			parity = globalRRStorageClass.Parity
-		} else {
-			// else fall back to default value
-			parity = defaultRRSParity
-		}
-	case standardStorageClass:
-		if globalStandardStorageClass.Parity != 0 {
-			// set the standard parity if available
-			parity = globalStandardStorageClass.Parity
+// Returns the error for an odd parity of a storage class when even parity is
+// required, suggesting the nearest even parity which passes check. Higher
+// parity is suggested if both the neighbouring even parities are valid.
+func oddParityError(class string, parity int, check func(parity int) []error) error {
+	for _, even := range []int{parity + 1, parity - 1} {
+		if len(check(even)) == 0 {
+			return fmt.Errorf("%s parity disks should be even, nearest valid parity is %d", class, even)
 		}
 	}
-	// data is always totalDisks - parity
-	return totalDisks - parity, parity
+	return fmt.Errorf("%s parity disks should be even", class)
 }
 
-// Returns per object readQuorum and writeQuorum
-// readQuorum is the minimum required disks to read data.
-// writeQuorum is the minimum required disks to write data.
-func objectQuorumFromMeta(xl xlObjects, partsMetaData []xlMetaV1, errs []error) (objectReadQuorum, objectWriteQuorum int, err error) {
+// ValidateStorageClassForDisks validates the Standard and Reduced Redundancy
+// storage classes for the given number of disks rather than the disks of this
+// server, so that parity can be planned for a hypothetical setup. The rules
+// are the same as validateRRSParity and validateSSParity, a storage class
+// without a scheme is not set and a storage class with parity 0 is the
+// default parity, neither is validated.
+func ValidateStorageClassForDisks(standard, rrs storageClass, disks int) error {
+	standard = resolvePercentParity(standard, disks)
+	rrs = resolveRelativeParity(resolvePercentParity(rrs, disks), standard, disks)
+	if rrs.Scheme != "" && rrs.Parity != 0 {
+		if errs := checkRRSParity(rrs.Parity, standard.Parity, disks); len(errs) > 0 {
+			return errs[0]
+		}
+	}
+	if standard.Scheme != "" && standard.Parity != 0 {
+		if errs := checkSSParity(standard.Parity, rrs.Parity, disks); len(errs) > 0 {
+			return errs[0]
+		}
+	}
+	return nil
+}
 
-	// get the latest updated Metadata and a count of all the latest updated xlMeta(s)
-	latestXLMeta, count := getLatestXLMeta(partsMetaData, errs)
+// Validates the Standard, Reduced Redundancy and Max durability storage
+// classes for the disks of every erasure set, as parity is resolved for the
+// disks of the set an object is written to. Parity set as a percentage or
+// relative to Standard parity is resolved for each set, parity set as a
+// number of disks has to be valid on every set. Sets of less than 4 disks
+// are not erasure coded and are skipped.
+func validateStorageClassForSets(standard, rrs, maxsc storageClass, setDriveCounts []int) error {
+	for index, disks := range setDriveCounts {
+		if disks < 4 {
+			continue
+		}
+		err := ValidateStorageClassForDisks(standard, rrs, disks)
+		if err == nil && maxsc.Scheme != "" {
+			ssParity := resolvePercentParity(standard, disks).Parity
+			err = ValidateParity(maxDurabilityStorageClass, resolvePercentParity(maxsc, disks).Parity, disks, ssParity)
+		}
+		if err != nil {
+			return fmt.Errorf("Erasure set %d of %d disks: %s", index+1, disks, err)
+		}
+	}
+	return nil
+}
 
-	// latestXLMeta is updated most recently.
-	// We implicitly assume that all the xlMeta(s) have same dataBlocks and parityBlocks.
-	// We now check that at least dataBlocks number of xlMeta is available. This means count
-	// should be greater than or equal to dataBlocks field of latestXLMeta. If not we throw read quorum error.
-	if count < latestXLMeta.Erasure.DataBlocks {
-		// This is the case when we can't reliably deduce object quorum
-		return 0, 0, errXLReadQuorum
+// SuggestStorageClassConfig returns a recommended storage class config for the
+// given number of disks which passes ValidateStorageClassForDisks. Standard
+// storage class parity is N/4, at least one more than minimumParityDisks and
+// at most N/2, Reduced redundancy storage class parity is minimumParityDisks
+// if that is lesser than Standard parity and is left unset otherwise, e.g. on
+// a 4 disk setup. Storage classes are not set for non erasure coded setups.
+func SuggestStorageClassConfig(disks int) storageClassConfig {
+	var sCfg storageClassConfig
+	if disks < 4 {
+		return sCfg
+	}
+
+	ssParity := disks / 4
+	if ssParity <= minimumParityDisks {
+		ssParity = minimumParityDisks + 1
+	}
+	if maxParity := maxParityForDisks(disks); ssParity > maxParity {
+		ssParity = maxParity
+	}
+	sCfg.Standard = storageClass{Scheme: supportedStorageClassScheme, Parity: ssParity}
+
+	if minimumParityDisks < ssParity {
+		sCfg.RRS = storageClass{Scheme: supportedStorageClassScheme, Parity: minimumParityDisks}
+	}
+	return sCfg
+}
+
+// Validates that Standard and Reduced Redundancy storage classes use the same
+// scheme, as all the objects are laid out by a single backend. An empty scheme
+// means the storage class is not set and is always valid.
+func validateStorageClassSchemes(ssScheme, rrsScheme string) error {
+	if ssScheme != "" && rrsScheme != "" && ssScheme != rrsScheme {
+		return fmt.Errorf("Standard storage class scheme %s and reduced redundancy storage class scheme %s should be the same",
+			ssScheme, rrsScheme)
+	}
+	return nil
+}
+
+// Validates the parity disks for both Standard and Reduced Redundancy storage
+// classes, a parity of 0 means the storage class is not set. Unlike validating
+// each storage class separately, every violation is collected and returned as
+// a single error so that all of them can be fixed in one pass.
+func validateStorageClassConfig(ssParity, rrsParity int) error {
+	return checkStorageClassConfig(ssParity, rrsParity, len(globalEndpoints))
+}
+
+// Same as validateStorageClassConfig for the given number of disks.
+func checkStorageClassConfig(ssParity, rrsParity, disks int) error {
+	var msgs []string
+	if rrsParity != 0 {
+		for _, err := range checkRRSParity(rrsParity, ssParity, disks) {
+			msgs = append(msgs, fmt.Sprintf("%s (%s): %s", reducedRedundancyStorageClass, reducedRedundancyStorageClassEnv, err))
+		}
+	}
+	if ssParity != 0 {
+		for _, err := range checkSSParity(ssParity, rrsParity, disks) {
+			msgs = append(msgs, fmt.Sprintf("%s (%s): %s", standardStorageClass, standardStorageClassEnv, err))
+		}
+	}
+	if len(msgs) > 0 {
+		return errors.New(strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+// storageClassViolation - a parity rule violated by a storage class,
+// reported as JSON for tooling that parses the validation errors.
+type storageClassViolation struct {
+	Class  string `json:"class"`
+	Env    string `json:"env"`
+	Rule   string `json:"rule"`
+	Parity int    `json:"parity"`
+	// Lowest and highest parity which satisfies all the parity
+	// rules of the storage class, unset if no parity does.
+	MinParity int `json:"minParity,omitempty"`
+	MaxParity int `json:"maxParity,omitempty"`
+}
+
+// Returns the lowest and highest parity for which check reports no errors.
+func getValidParityRange(disks int, check func(parity int) []error) (min, max int) {
+	for parity := 1; parity <= disks; parity++ {
+		if len(check(parity)) == 0 {
+			if min == 0 {
+				min = parity
+			}
+			max = parity
+		}
+	}
+	return min, max
+}
+
+// Returns the violations of the parity rules for the given storage class
+// parities, these are the same errors as reported by checkStorageClassConfig
+// and validateMaxParity. A storage class with parity 0 is not validated.
+func getStorageClassViolations(ssParity, rrsParity, maxParity, disks int) (violations []storageClassViolation) {
+	classes := []struct {
+		name   string
+		env    string
+		parity int
+		check  func(parity int) []error
+	}{
+		{reducedRedundancyStorageClass, reducedRedundancyStorageClassEnv, rrsParity, func(parity int) []error {
+			return checkParity(reducedRedundancyStorageClass, parity, disks, ssParity)
+		}},
+		{standardStorageClass, standardStorageClassEnv, ssParity, func(parity int) []error {
+			return checkParity(standardStorageClass, parity, disks, rrsParity)
+		}},
+		{maxDurabilityStorageClass, maxDurabilityStorageClassEnv, maxParity, func(parity int) []error {
+			return checkParity(maxDurabilityStorageClass, parity, disks, ssParity)
+		}},
+	}
+	for _, class := range classes {
+		if class.parity == 0 {
+			continue
+		}
+		errs := class.check(class.parity)
+		if len(errs) == 0 {
+			continue
+		}
+		min, max := getValidParityRange(disks, class.check)
+		for _, err := range errs {
+			violations = append(violations, storageClassViolation{
+				Class:     class.name,
+				Env:       class.env,
+				Rule:      err.Error(),
+				Parity:    class.parity,
+				MinParity: min,
+				MaxParity: max,
+			})
+		}
+	}
+	return violations
+}
+
+// Returns the storage class validation error as JSON along with the parity
+// rules violated by the storage classes, e.g.
+// {"error":"...","violations":[{"class":"STANDARD","env":"MINIO_STORAGE_CLASS_STANDARD",
+// "rule":"...","parity":9,"minParity":3,"maxParity":8}]}
+func storageClassErrorJSON(err error, ssc, rrsc, maxsc storageClass, disks int) ([]byte, error) {
+	return json.Marshal(struct {
+		Error      string                  `json:"error"`
+		Violations []storageClassViolation `json:"violations"`
+	}{
+		Error:      err.Error(),
+		Violations: getStorageClassViolations(ssc.Parity, rrsc.Parity, maxsc.Parity, disks),
+	})
+}
+
+// Validates the parity disks for Max durability storage class.
+func validateMaxParity(maxParity, ssParity int) (err error) {
+	return ValidateParity(maxDurabilityStorageClass, maxParity, len(globalEndpoints), ssParity)
+}
+
+// Returns all the violations of the parity disks for Max durability storage class
+func checkMaxParity(maxParity, ssParity, disks int) (errs []error) {
+	// disks < 4 means this is not a erasure coded setup and so storage class is not supported
+	if disks < 4 {
+		return []error{errStorageClassErasureOnly}
+	}
+
+	// Max durability storage class implies more parity than Standard storage class. So, Max durability
+	// parity disks should be greater than Standard parity, which defaults to N/2 if not set.
+	if ssParity == 0 {
+		ssParity = newStorageClassConfig(disks).Standard.Parity
+	}
+	if maxParity <= ssParity {
+		errs = append(errs, fmt.Errorf("Max durability storage class parity disks should be greater than %d", ssParity))
+	}
+
+	// Max durability storage class parity should be less than or equal to N/2
+	if maxParity > maxParityForDisks(disks) {
+		errs = append(errs, fmt.Errorf("Max durability storage class parity disks should be less than or equal to %d", maxParityForDisks(disks)))
+	}
+
+	return errs
+}
+
+// Returns the storage class of an object from its metadata, objects
+// written without storage class metadata are reported as Standard storage
+// class. Such objects are laid out with the default parity rather than the
+// Standard parity, use the storage class metadata as is to resolve their
+// data and parity drive count.
+func getObjectStorageClass(meta map[string]string) string {
+	if sc := meta[amzStorageClass]; sc != "" {
+		return sc
+	}
+	return standardStorageClass
+}
+
+// Records the storage class of the source object in the storage class history
+// of the destination metadata if the storage class changes. The history of the
+// source object is carried over, only the last maxStorageClassHistory entries
+// of the form "STORAGE_CLASS=RFC3339 time" are kept.
+func recordStorageClassTransition(srcMeta, dstMeta map[string]string, t time.Time) {
+	if dstMeta == nil {
+		return
+	}
+
+	var history []string
+	if h := srcMeta[storageClassHistoryKey]; h != "" {
+		history = strings.Split(h, ",")
+	}
+
+	if srcSC := getObjectStorageClass(srcMeta); srcSC != getObjectStorageClass(dstMeta) {
+		history = append(history, srcSC+"="+t.UTC().Format(time.RFC3339))
+	}
+	if len(history) > maxStorageClassHistory {
+		history = history[len(history)-maxStorageClassHistory:]
+	}
+
+	if len(history) > 0 {
+		dstMeta[storageClassHistoryKey] = strings.Join(history, ",")
+	}
+}
+
+// redundancyInfo - data and parity drive count resolved for a storage class.
+type redundancyInfo struct {
+	Data   int
+	Parity int
+	// Storage class the drive count is resolved for, objects
+	// without storage class are Standard storage class.
+	Class string
+	// Set if the parity is the default parity rather than
+	// the parity configured for the storage class.
+	UsedDefault bool
+	// Where the parity comes from, one of storageClassSource*.
+	Source string
+}
+
+// Returns the data and parity drive count based on storage class
+// If storage class is set using the env vars MINIO_STORAGE_CLASS_RRS and MINIO_STORAGE_CLASS_STANDARD
+// -- corresponding values are returned
+// If storage class is not set using environment variables, default values are returned
+// -- Default for Reduced Redundancy Storage class is, parity = 2 and data = N-Parity
+// -- Default for Standard Storage class is, parity = N/2, data = N/2
+// -- Default for Max durability Storage class is, parity = N/2, data = N/2
+// -- Scratch Storage class is always, parity = minimumParityDisks and data = N-Parity
+// If storage class is not present in metadata, default value is data = N/2, parity = N/2
+// Data and parity drive count are resolved by the installed parity strategy, the object
+// size is not known. Drive counts resolved from the storage classes are cached, see
+// getBucketRedundancyCount.
+func getRedundancyCount(sc string, totalDisks int) redundancyInfo {
+	return getObjectSizeRedundancyCount("", sc, totalDisks, -1)
+}
+
+// Returns the data and parity drive count of an object of objSize bytes in
+// bucket, -1 if the size is not known, resolved by the installed parity
+// strategy. The storage class and the source of the parity are resolved
+// from the bucket and server storage classes like getBucketRedundancyCount.
+func getObjectSizeRedundancyCount(bucket, sc string, totalDisks int, objSize int64) redundancyInfo {
+	info := getBucketRedundancyCount(bucket, sc, totalDisks)
+	// Default strategy resolves the same data and parity drive count as info.
+	if strategy := getParityStrategy(); !isDefaultParityStrategy(strategy) {
+		info = resolveParityStrategy(strategy, bucket, info, totalDisks, objSize)
+	}
+	return info
+}
+
+// Returns info with the data and parity drive count resolved by strategy
+// for the storage class of info, alias resolved, in bucket. Drive counts
+// resolved are only used if there is at least one data disk, parity is
+// between minimumParityDisks and N/2 and they add up to totalDisks, as they
+// can't be erasure coded otherwise, info is returned as is if not.
+func resolveParityStrategy(strategy ParityStrategy, bucket string, info redundancyInfo, totalDisks int, objSize int64) redundancyInfo {
+	data, parity := strategy.Resolve(bucket, info.Class, totalDisks, objSize)
+	if data < 1 || parity < minimumParityDisks || parity > maxParityForDisks(totalDisks) || data+parity != totalDisks {
+		return info
+	}
+	info.Data, info.Parity = data, parity
+	return info
+}
+
+// Returns the parity disks for a force parity value, which is either "max"
+// for N/2 parity or the number of parity disks. Parity should be between
+// minimumParityDisks and N/2 of totalDisks.
+func parseForceParity(forceParity string, totalDisks int) (int, error) {
+	parity := maxParityForDisks(totalDisks)
+	if forceParity != forceParityMax {
+		var err error
+		if parity, err = strconv.Atoi(forceParity); err != nil {
+			return 0, errInvalidForceParity
+		}
+	}
+	if parity < minimumParityDisks || parity > maxParityForDisks(totalDisks) {
+		return 0, errInvalidForceParity
+	}
+	return parity, nil
+}
+
+// Lowers the parity of an object of size bytes to minimumParityDisks, if
+// it is smaller than the small object parity threshold of its storage
+// class, the lowered parity is recorded in the metadata of the object.
+// Objects with forced parity and storage classes without a threshold keep
+// the parity of info, parity is never raised by a threshold.
+func tuneParityForSize(info redundancyInfo, size int64, metadata map[string]string) redundancyInfo {
+	// Parity tuned for a previous size, e.g. of a copy source, doesn't apply.
+	delete(metadata, sizeTunedParityKey)
+	if _, ok := metadata[forceParityKey]; ok {
+		return info
+	}
+	// Encrypted objects are tuned by their plain size, so that
+	// encryption doesn't change the parity an object is written with.
+	if objInfo := (ObjectInfo{Size: size, UserDefined: metadata}); objInfo.IsEncrypted() {
+		if plainSize, err := objInfo.DecryptedSize(); err == nil {
+			size = plainSize
+		}
+	}
+	threshold, ok := globalSmallObjectParityThresholds[info.Class]
+	if !ok || size >= threshold || info.Parity <= minimumParityDisks {
+		return info
+	}
+	info.Data += info.Parity - minimumParityDisks
+	info.Parity = minimumParityDisks
+	info.Source = storageClassSourceSize
+	metadata[sizeTunedParityKey] = strconv.Itoa(info.Parity)
+	return info
+}
+
+// Returns the layout an object is re-encoded to on heal when
+// globalStorageClassHealReparity is set, i.e. the current parity of the
+// storage class recorded in its xl.json, along with the metadata to write
+// it with. An object without storage class is resolved to the default
+// parity, the same as when it was written, and is never re-encoded with
+// less parity. Returns false if the object is already at the target
+// parity, its parity was forced while writing it or it would be lowered.
+func getHealRedundancyInfo(meta xlMetaV1, totalDisks int) (redundancyInfo, map[string]string, bool) {
+	if _, ok := meta.Meta[forceParityKey]; ok {
+		return redundancyInfo{}, nil, false
+	}
+	metadata := make(map[string]string, len(meta.Meta))
+	for k, v := range meta.Meta {
+		metadata[k] = v
+	}
+	info := getObjectSizeRedundancyCount("", metadata[amzStorageClass], totalDisks, meta.Stat.Size)
+	info = tuneParityForSize(info, meta.Stat.Size, metadata)
+	if info.Parity == meta.Erasure.ParityBlocks {
+		return info, nil, false
+	}
+	if metadata[amzStorageClass] == "" && info.Parity < meta.Erasure.ParityBlocks {
+		return info, nil, false
+	}
+	return info, metadata, true
+}
+
+// Returns the data and parity drive count of an object of objSize bytes to be
+// written with the given metadata. Parity forced while writing the object takes
+// precedence over its storage class, the forced value is replaced by the resolved
+// parity so that it is recorded along with the object.
+func getObjectRedundancyCount(bucket string, metadata map[string]string, totalDisks int, objSize int64) (data, parity int, err error) {
+	info, err := getObjectRedundancyInfo(bucket, metadata, totalDisks, objSize)
+	return info.Data, info.Parity, err
+}
+
+// Saves in the metadata of an object to be written the parity forced by the
+// request, it is validated and resolved to the number of parity disks by the
+// object layer. Parity set via user metadata is only used if not forced with
+// the header amzForceParity. Nothing is saved if the backend is not XL, as
+// other backends would save it along with the object.
+func setForceParity(header http.Header, metadata map[string]string) {
+	if !globalIsXL {
+		return
+	}
+	if _, ok := header[amzForceParity]; ok {
+		metadata[forceParityKey] = header.Get(amzForceParity)
+	} else if _, ok = header[amzMetaParity]; ok {
+		metadata[forceParityKey] = header.Get(amzMetaParity)
+	}
+}
+
+// Sets the storage class of an object copied with the metadata of the
+// source object. Parity forced for the source object is dropped, as it
+// takes precedence over the storage class and the copy would keep the
+// source parity, unless the copy request forces parity itself.
+func setCopyStorageClass(header http.Header, metadata map[string]string, sc string) {
+	metadata[amzStorageClass] = sc
+	delete(metadata, forceParityKey)
+	setForceParity(header, metadata)
+}
+
+// Saves in the metadata of an object to be written whether the request
+// forces a storage class downgrade with the header amzForceDowngrade.
+// Nothing is saved if the backend is not XL, as only the XL object layer
+// removes it from the metadata before the object is saved.
+func setForceDowngrade(header http.Header, metadata map[string]string) {
+	if !globalIsXL {
+		return
+	}
+	if _, ok := header[amzForceDowngrade]; ok {
+		metadata[forceDowngradeKey] = header.Get(amzForceDowngrade)
+	}
+}
+
+// Removes a forced storage class downgrade from the metadata of an object
+// to be written, returns true if the downgrade is forced.
+func popForceDowngrade(metadata map[string]string) bool {
+	force, ok := metadata[forceDowngradeKey]
+	if !ok {
+		return false
+	}
+	delete(metadata, forceDowngradeKey)
+	return strings.EqualFold(force, "true")
+}
+
+// Returns the data and parity drive count of an object to be written with
+// the given metadata like getObjectRedundancyCount, along with the storage
+// class and the source of the parity.
+func getObjectRedundancyInfo(bucket string, metadata map[string]string, totalDisks int, objSize int64) (info redundancyInfo, err error) {
+	if forceParity, ok := metadata[forceParityKey]; ok {
+		parity, err := parseForceParity(forceParity, totalDisks)
+		if err != nil {
+			return info, err
+		}
+		metadata[forceParityKey] = strconv.Itoa(parity)
+		return redundancyInfo{
+			Data:   totalDisks - parity,
+			Parity: parity,
+			Class:  getStorageClassFromAlias(getObjectStorageClass(metadata)),
+			Source: storageClassSourceRequest,
+		}, nil
+	}
+	return getObjectSizeRedundancyCount(bucket, metadata[amzStorageClass], totalDisks, objSize), nil
+}
+
+// Logs the storage class applied to a write of the object, if audit of
+// storage classes is enabled. requested is the storage class in the request.
+func auditStorageClass(bucket, object, requested string, info redundancyInfo) {
+	if !globalStorageClassAudit {
+		return
+	}
+	log.logger.WithFields(logrus.Fields{
+		"audit":          "storageClass",
+		"bucket":         bucket,
+		"object":         object,
+		"requestedClass": requested,
+		"resolvedClass":  info.Class,
+		"parity":         info.Parity,
+		"source":         info.Source,
+	}).Info("Storage class applied to object")
+}
+
+// Returns the storage class an object with the given data and parity disks was
+// most likely written with, the reverse of getRedundancyCount. Storage classes
+// are matched in the order STANDARD, REDUCED_REDUNDANCY, MAX_DURABILITY and
+// SCRATCH, so if two storage classes have the same parity (e.g. RRS on a 4 disk
+// setup) the most durable match is returned. N/2 parity is the default parity of objects
+// without storage class and is STANDARD. Returns an empty string if the data
+// and parity disks don't add up to totalDisks or no storage class matches.
+func storageClassFromRedundancy(data, parity, totalDisks int) string {
+	if data+parity != totalDisks {
+		return ""
+	}
+	for _, sc := range validStorageClasses {
+		if getRedundancyCount(sc, totalDisks).Parity == parity {
+			return sc
+		}
+	}
+	if getRedundancyCount("", totalDisks).Parity == parity {
+		return standardStorageClass
+	}
+	return ""
+}
+
+// Returns the data and parity drive count based on storage class for
+// objects in a given bucket. Storage class set on the bucket takes
+// precedence over the server wide storage class. The result is cached
+// until the server or bucket storage classes change.
+func getBucketRedundancyCount(bucket, sc string, totalDisks int) redundancyInfo {
+	return globalRedundancyCache.Get(bucket, sc, totalDisks, resolveBucketRedundancyCount)
+}
+
+// Resolves the data and parity drive count like getBucketRedundancyCount,
+// without the cache.
+func resolveBucketRedundancyCount(bucket, sc string, totalDisks int) (info redundancyInfo) {
+	sc = getStorageClassFromAlias(sc)
+	if _, _, maxsc := getStorageClassGlobals(); sc == maxDurabilityStorageClass && maxsc.Parity != 0 {
+		// set the max durability parity if available
+		maxsc = resolvePercentParity(maxsc, totalDisks)
+		return clampRedundancyCount(redundancyInfo{Data: totalDisks - maxsc.Parity, Parity: maxsc.Parity, Class: sc, Source: storageClassSourceConfig}, totalDisks)
+	}
+	info = GetRedundancyCount(sc, totalDisks, getBucketStorageClass(bucket, standardStorageClass),
+		getBucketStorageClass(bucket, reducedRedundancyStorageClass))
+
+	scCfg, ok := globalBucketStorageClass.GetBucketStorageClass(bucket)
+	switch {
+	case info.UsedDefault || info.Class == scratchStorageClass:
+		// Scratch storage class parity is not configurable.
+		info.Source = storageClassSourceDefault
+	case ok && info.Class == standardStorageClass && scCfg.Standard.Scheme != "",
+		ok && info.Class == reducedRedundancyStorageClass && scCfg.RRS.Scheme != "":
+		info.Source = storageClassSourceBucket
+	default:
+		info.Source = storageClassSourceConfig
+	}
+	return clampRedundancyCount(info, totalDisks)
+}
+
+// Clamps the parity of a storage class to N/2 of totalDisks, if it is more
+// than that and MINIO_STORAGE_CLASS_CLAMP_PARITY is on. Parity is validated
+// against the disks at startup, but a setup scaled down since then may have
+// too few disks for the configured parity. Clamped parity is never less than
+// minimumParityDisks, a warning is logged the first time parity of a storage
+// class is clamped on totalDisks.
+func clampRedundancyCount(info redundancyInfo, totalDisks int) redundancyInfo {
+	maxParity := maxParityForDisks(totalDisks)
+	if maxParity < minimumParityDisks {
+		maxParity = minimumParityDisks
+	}
+	if !globalStorageClassClampParity || info.Parity <= maxParity {
+		return info
+	}
+	if globalClampedParityWarnings.Warn(info.Class, totalDisks) {
+		log.Println(colorYellow("Warning: %s storage class parity %d exceeds %d disks, clamped to %d (%s=on)",
+			info.Class, info.Parity, totalDisks, maxParity, storageClassClampParityEnv))
+	}
+	info.Data, info.Parity = totalDisks-maxParity, maxParity
+	return info
+}
+
+// clampedParityWarnings - storage classes and disk counts parity was
+// clamped for, so that clamping is logged once instead of per write.
+type clampedParityWarnings struct {
+	mutex  *sync.Mutex
+	warned map[string]bool
+}
+
+// Storage classes warned about clamped parity, reset on storage class reload.
+var globalClampedParityWarnings = newClampedParityWarnings()
+
+func newClampedParityWarnings() *clampedParityWarnings {
+	return &clampedParityWarnings{
+		mutex:  &sync.Mutex{},
+		warned: make(map[string]bool),
+	}
+}
+
+// Warn returns true if clamping parity of the storage class on totalDisks
+// was not warned about yet.
+func (w *clampedParityWarnings) Warn(sc string, totalDisks int) bool {
+	key := sc + "/" + strconv.Itoa(totalDisks)
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.warned[key] {
+		return false
+	}
+	w.warned[key] = true
+	return true
+}
+
+// Reset forgets all warnings, e.g. after the storage classes are reloaded.
+func (w *clampedParityWarnings) Reset() {
+	w.mutex.Lock()
+	w.warned = make(map[string]bool)
+	w.mutex.Unlock()
+}
+
+// GetRedundancyCount returns the data and parity drive count for a storage
+// class given the standard and reduced redundancy storage class configs,
+// unlike getRedundancyCount it doesn't depend on the configured storage classes.
+// A storage class config with parity 0 falls back to its default value. Parity
+// set as a percentage or relative to Standard parity is resolved for totalDisks,
+// the disks the object is laid out on, not for the disks of the whole server.
+func GetRedundancyCount(sc string, totalDisks int, standard, rrs storageClass) (info redundancyInfo) {
+	defaultCfg := newStorageClassConfig(totalDisks)
+	standard = resolvePercentParity(standard, totalDisks)
+	rrs = resolveRelativeParity(resolvePercentParity(rrs, totalDisks), standard, totalDisks)
+	switch sc {
+	case reducedRedundancyStorageClass:
+		info.Class = sc
+		if rrs.Parity == 0 {
+			// fall back to default value if rrs parity is not set
+			rrs = defaultCfg.RRS
+			info.UsedDefault = true
+		}
+		info.Data, info.Parity = getSchemeRedundancyCount(rrs, totalDisks)
+		return info
+	case standardStorageClass:
+		info.Class = sc
+		if standard.Parity == 0 {
+			// fall back to default value if standard parity is not set
+			standard = defaultCfg.Standard
+			info.UsedDefault = true
+		}
+		info.Data, info.Parity = getSchemeRedundancyCount(standard, totalDisks)
+		return info
+	case scratchStorageClass:
+		// Scratch storage class is not configurable, it always
+		// trades durability for the most data disks.
+		info.Class = sc
+		info.Data, info.Parity = totalDisks-minimumParityDisks, minimumParityDisks
+		return info
+	}
+	// Storage class not present in metadata, default is N/2 parity. Max
+	// durability storage class without parity falls back to N/2 parity,
+	// which is not capped by globalStorageClassMaxParity.
+	info.Class = standardStorageClass
+	info.UsedDefault = true
+	if sc == maxDurabilityStorageClass {
+		info.Class = sc
+		info.Data, info.Parity = getSchemeRedundancyCount(storageClass{Scheme: supportedStorageClassScheme, Parity: maxParityForDisks(totalDisks)}, totalDisks)
+		return info
+	}
+	info.Data, info.Parity = getSchemeRedundancyCount(defaultCfg.Standard, totalDisks)
+	return info
+}
+
+// Returns the data and parity drive count of a storage class as laid out
+// by its scheme, a storage class without a scheme is treated as EC.
+func getSchemeRedundancyCount(sc storageClass, totalDisks int) (data, parity int) {
+	scheme, ok := storageClassSchemes[sc.Scheme]
+	if !ok {
+		scheme = storageClassSchemes[supportedStorageClassScheme]
+	}
+	return scheme.redundancy(sc, totalDisks)
+}
+
+// Returns the storage overhead of objects written with the storage class, i.e.
+// the disk space consumed per byte of object data (totalDisks/data disks).
+// An empty storage class is the default storage class. Returns 0 if the
+// storage class is unknown or no data disks are available.
+func storageOverhead(sc string, totalDisks int) float64 {
+	if sc != "" && !isValidStorageClassMeta(sc) {
+		return 0
+	}
+	data := getRedundancyCount(sc, totalDisks).Data
+	if data <= 0 {
+		return 0
+	}
+	return float64(totalDisks) / float64(data)
+}
+
+// ErasureObjectSize returns the total bytes written across all the disks
+// for an object of the given size and storage class, i.e. the erasure
+// expanded size of the object. Each block of the object is split into
+// chunks of its data disks, rounded up, and a chunk is written to every
+// disk. Returns size as is on a non-erasure setup (totalDisks < 4) or if
+// no data disks are available.
+func ErasureObjectSize(size int64, sc string, totalDisks int) int64 {
+	if size <= 0 {
+		return 0
+	}
+	data := getRedundancyCount(sc, totalDisks).Data
+	// disks < 4 means this is not a erasure coded setup
+	if totalDisks < 4 || data <= 0 {
+		return size
+	}
+	return erasureShardSize(size, data) * int64(totalDisks)
+}
+
+// Returns the bytes written to each disk for an object of the given size
+// erasure coded with dataBlocks. The object is written in parts of
+// globalPutPartSize, each part is erasure coded in blocks of blockSizeV1.
+func erasureShardSize(size int64, dataBlocks int) int64 {
+	partShardSize := func(partSize int64) int64 {
+		shardSize := (partSize / blockSizeV1) * getChunkSize(blockSizeV1, dataBlocks)
+		if lastBlock := partSize % blockSizeV1; lastBlock > 0 {
+			shardSize += getChunkSize(lastBlock, dataBlocks)
+		}
+		return shardSize
+	}
+	shardSize := (size / globalPutPartSize) * partShardSize(globalPutPartSize)
+	if lastPart := size % globalPutPartSize; lastPart > 0 {
+		shardSize += partShardSize(lastPart)
+	}
+	return shardSize
+}
+
+// objectLayout - erasure layout an object is written with.
+type objectLayout struct {
+	StorageClass string
+	DataBlocks   int
+	ParityBlocks int
+	// Bytes written to each disk.
+	ShardSize   int64
+	WriteQuorum int
+}
+
+// Returns the erasure layout PutObject writes an object of the given size
+// and metadata with, without writing anything. Storage class and force
+// parity of the metadata are resolved the same way as PutObject does.
+func planObjectLayout(bucket string, metadata map[string]string, size int64, totalDisks int) (layout objectLayout, err error) {
+	info, err := getObjectRedundancyInfo(bucket, metadata, totalDisks, size)
+	if err != nil {
+		return layout, err
+	}
+	_, writeQuorum := quorumFromDataBlocks(metadata[amzStorageClass], info.Data)
+	return objectLayout{
+		StorageClass: info.Class,
+		DataBlocks:   info.Data,
+		ParityBlocks: info.Parity,
+		ShardSize:    erasureShardSize(size, info.Data),
+		WriteQuorum:  writeQuorum,
+	}, nil
+}
+
+// Checks if the free space of the object layer can hold an object of the
+// given size once erasure expanded with dataBlocks, i.e. laid out as the
+// object layer would write it. Free space of an erasure setup is reported
+// as usable space at N/2 parity, so it is doubled to compare against the
+// raw bytes written across all disks.
+func hasCapacityForObject(info StorageInfo, size int64, dataBlocks int) bool {
+	if info.Backend.Type != Erasure || size <= 0 || dataBlocks <= 0 {
+		return true
+	}
+	totalDisks := info.Backend.OnlineDisks + info.Backend.OfflineDisks
+	return uint64(erasureShardSize(size, dataBlocks)*int64(totalDisks)) <= info.Free*2
+}
+
+// Interval after which the storage info uploads are checked against
+// is fetched again from the disks.
+var freeSpaceRefreshInterval = 10 * time.Second
+
+// Variable holds the storage info fetched last, looked up on large uploads.
+var globalFreeSpace = newFreeSpaceCache()
+
+// freeSpaceCache - storage info of the object layer fetched in the background
+// at most once every freeSpaceRefreshInterval, so that uploads are checked
+// against the free space without a DiskInfo call to every disk each.
+type freeSpaceCache struct {
+	mutex      *sync.Mutex
+	info       StorageInfo
+	fetched    time.Time
+	refreshing bool
+}
+
+func newFreeSpaceCache() *freeSpaceCache {
+	return &freeSpaceCache{mutex: &sync.Mutex{}}
+}
+
+// Returns the storage info fetched last, ok is false if it was never
+// fetched. Storage info is fetched again in the background once it is
+// older than freeSpaceRefreshInterval, only one fetch runs at a time.
+func (c *freeSpaceCache) Get(objAPI ObjectLayer) (info StorageInfo, ok bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if !c.refreshing && UTCNow().Sub(c.fetched) >= freeSpaceRefreshInterval {
+		c.refreshing = true
+		go func() {
+			info := objAPI.StorageInfo()
+			c.mutex.Lock()
+			c.info, c.fetched, c.refreshing = info, UTCNow(), false
+			c.mutex.Unlock()
+		}()
+	}
+	return c.info, !c.fetched.IsZero()
+}
+
+// Returns the usable, i.e. logical, free space for objects of the given
+// storage class from the raw free space of all the disks, that is rawFree *
+// data disks / totalDisks. Without erasure coding there is no parity, so
+// raw free space is returned as is.
+func getUsableFreeSpace(rawFree uint64, sc string, totalDisks int) uint64 {
+	// disks < 4 means this is not a erasure coded setup
+	if totalDisks < 4 {
+		return rawFree
+	}
+	info := getRedundancyCount(sc, totalDisks)
+	return rawFree * uint64(info.Data) / uint64(totalDisks)
+}
+
+// Returns the usable free space of all the storage classes supported
+// with the given number of disks, see getUsableFreeSpace. Without erasure
+// coding only STANDARD storage class is supported.
+func getUsableFreeSpaces(rawFree uint64, totalDisks int) map[string]uint64 {
+	if totalDisks < 4 {
+		return map[string]uint64{standardStorageClass: rawFree}
+	}
+	usableFree := make(map[string]uint64)
+	for _, sc := range ValidStorageClasses() {
+		usableFree[sc] = getUsableFreeSpace(rawFree, sc, totalDisks)
+	}
+	return usableFree
+}
+
+// Returns the startup message describing the effective parity and storage
+// overhead of Standard storage class for the given number of disks, warn is
+// set if the parity is N/2 on a setup larger than largeSetupDisks, where a
+// lower parity is often sufficient and N/2 is likely just the default.
+func getStandardParityStartupMsg(disks int) (msg string, warn bool) {
+	parity := getRedundancyCount(standardStorageClass, disks).Parity
+	msg = fmt.Sprintf("Standard storage class parity is %d of %d disks, storage overhead is %.2fx",
+		parity, disks, storageOverhead(standardStorageClass, disks))
+	return msg, disks > largeSetupDisks && parity == maxParityForDisks(disks)
+}
+
+// Returns the startup message warning that Reduced Redundancy storage class
+// is set while Standard storage class is left unset, so that Standard storage
+// class takes the default N/2 parity which is often not intended relative to
+// the reduced redundancy parity. Empty if there is nothing to warn about,
+// Standard storage class set to EC:0 is the default parity set explicitly.
+func getImplicitStandardParityMsg(disks int) string {
+	ssc, rrsc, _ := getStorageClassGlobals()
+	if ssc.Scheme != "" || rrsc.Scheme == "" {
+		return ""
+	}
+	return fmt.Sprintf("Reduced redundancy storage class is set to %s (parity %d) but standard storage class is not set and defaults to parity %d of %d disks",
+		rrsc, getRedundancyCount(reducedRedundancyStorageClass, disks).Parity,
+		getRedundancyCount(standardStorageClass, disks).Parity, disks)
+}
+
+// Compares the durability of two storage classes by their parity on the
+// current setup, returns -1 if a is less durable than b, 1 if a is more
+// durable than b and 0 if both have the same parity. An empty storage
+// class is the default storage class. Unknown storage classes sort after
+// all known storage classes and compare equal to each other.
+func compareStorageClass(a, b string) int {
+	aKnown := a == "" || isValidStorageClassMeta(a)
+	bKnown := b == "" || isValidStorageClassMeta(b)
+	switch {
+	case !aKnown && !bKnown:
+		return 0
+	case !aKnown:
+		return 1
+	case !bKnown:
+		return -1
+	}
+
+	disks := len(globalEndpoints)
+	aParity := getRedundancyCount(a, disks).Parity
+	bParity := getRedundancyCount(b, disks).Parity
+	switch {
+	case aParity < bParity:
+		return -1
+	case aParity > bParity:
+		return 1
+	}
+	return 0
+}
+
+// Checks if objects can currently be written with the storage class given
+// the number of online disks. Writes need data disks + 1 to be online, so
+// a parity configured for all the disks may not be achievable during an
+// outage. This is only advisory and never fails a write or startup.
+func checkParityFeasibility(sc storageClass, onlineDisks int) error {
+	disks := len(globalEndpoints)
+	// disks < 4 means this is not a erasure coded setup
+	if disks < 4 || sc.Parity == 0 {
+		return nil
+	}
+
+	data, _ := getSchemeRedundancyCount(sc, disks)
+	if writeQuorum := data + 1; onlineDisks < writeQuorum {
+		return fmt.Errorf("Storage class %s:%d needs %d online disks but only %d are online",
+			sc.Scheme, sc.Parity, writeQuorum, onlineDisks)
+	}
+	return nil
+}
+
+// Validates that objects of each storage class can meet write quorum on the
+// given number of disks, i.e. data disks + 1 doesn't exceed the disks, as
+// every write would fail otherwise. Data disks are resolved the same way as
+// getRedundancyCount, but for the given storage classes, e.g. the storage
+// classes being loaded, unless a parity strategy is installed. Returns the
+// first storage class which can't be written.
+func checkStorageClassWriteQuorum(ssc, rrsc, maxsc storageClass, disks int) error {
+	// disks < 4 means this is not a erasure coded setup
+	if disks < 4 {
+		return nil
+	}
+	strategy := getParityStrategy()
+	for _, sc := range ValidStorageClasses() {
+		info := GetRedundancyCount(sc, disks, ssc, rrsc)
+		if sc == maxDurabilityStorageClass && maxsc.Parity != 0 {
+			info.Data, info.Parity = getSchemeRedundancyCount(resolvePercentParity(maxsc, disks), disks)
+		}
+		if !isDefaultParityStrategy(strategy) {
+			info = resolveParityStrategy(strategy, "", info, disks, -1)
+		}
+		if writeQuorum := info.Data + 1; writeQuorum > disks {
+			return fmt.Errorf("%s storage class write quorum of %d disks exceeds the %d total disks, writes would always fail",
+				sc, writeQuorum, disks)
+		}
+	}
+	return nil
+}
+
+// Parses given storageClassQuorumEnv and returns a quorumPolicy. Supported
+// format is "Read offset:Write offset" e.g. "0:1" which is the default.
+func parseQuorumPolicy(storageClassQuorumEnv string) (q quorumPolicy, err error) {
+	s := strings.Split(storageClassQuorumEnv, ":")
+	if len(s) != 2 {
+		return q, errors.New("Invalid quorum policy " + storageClassQuorumEnv + ". Supported format is READ_OFFSET:WRITE_OFFSET")
+	}
+	if q.ReadOffset, err = strconv.Atoi(s[0]); err != nil {
+		return q, err
+	}
+	if q.WriteOffset, err = strconv.Atoi(s[1]); err != nil {
+		return q, err
+	}
+	return q, nil
+}
+
+// Validates the quorum policy for a storage class with the given parity
+// disks. A higher quorum means fewer disks may be offline for the object
+// to be read or written, so the quorum can never exceed the total disks.
+func validateQuorumPolicy(q quorumPolicy, parity, disks int) error {
+	data := disks - parity
+	if q.ReadOffset < 0 {
+		return errors.New("Read quorum offset should be greater than or equal to 0, reading an object needs all its data disks")
+	}
+	// Write quorum of data disks or less allows two writes to succeed on
+	// different sets of disks, which risks split brain.
+	if q.WriteOffset < 1 {
+		return errors.New("Write quorum offset should be greater than or equal to 1, lower write quorum risks split brain")
+	}
+	if readQuorum := data + q.ReadOffset; readQuorum > disks {
+		return fmt.Errorf("Read quorum of %d disks exceeds the %d total disks, reads would always fail. "+
+			"Each additional read quorum disk allows one less disk to be offline while reading", readQuorum, disks)
+	}
+	if writeQuorum := data + q.WriteOffset; writeQuorum > disks {
+		return fmt.Errorf("Write quorum of %d disks exceeds the %d total disks, writes would always fail. "+
+			"Each additional write quorum disk allows one less disk to be offline while writing", writeQuorum, disks)
+	}
+	return nil
+}
+
+// Validates the quorum policy against the parity of all the storage classes
+// and sets it as the quorum policy of all the storage classes. The storage
+// classes are locked while the policy is validated, so that a concurrent
+// reload can't install storage classes the policy was not validated against.
+func setStorageClassQuorumPolicy(q quorumPolicy) error {
+	globalStorageClassMu.Lock()
+	err := checkStorageClassQuorumPolicy(q, globalStandardStorageClass, globalRRStorageClass, globalMaxStorageClass, len(globalEndpoints))
+	if err == nil {
+		globalStandardStorageClass.Quorum = q
+		globalRRStorageClass.Quorum = q
+		globalMaxStorageClass.Quorum = q
+	}
+	globalStorageClassMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// Drop the entries resolved from the previous storage classes.
+	globalRedundancyCache.Invalidate()
+	return nil
+}
+
+// Validates the quorum policy against the parity of the given storage classes,
+// a storage class without parity is validated against its default parity.
+func checkStorageClassQuorumPolicy(q quorumPolicy, ssc, rrsc, maxsc storageClass, disks int) error {
+	for _, sc := range validStorageClasses {
+		parity := GetRedundancyCount(sc, disks, ssc, rrsc).Parity
+		if sc == maxDurabilityStorageClass && maxsc.Parity != 0 {
+			parity = maxsc.Parity
+		}
+		if err := validateQuorumPolicy(q, parity, disks); err != nil {
+			return fmt.Errorf("%s: %s", sc, err)
+		}
+	}
+	return nil
+}
+
+// Returns the quorum policy of objects in the given storage class.
+func getStorageClassQuorumPolicy(sc string) quorumPolicy {
+	ssc, rrsc, maxsc := getStorageClassGlobals()
+	var q quorumPolicy
+	switch getStorageClassFromAlias(sc) {
+	case reducedRedundancyStorageClass:
+		q = rrsc.Quorum
+	case maxDurabilityStorageClass:
+		q = maxsc.Quorum
+	default:
+		q = ssc.Quorum
+	}
+	if q == (quorumPolicy{}) {
+		return defaultQuorumPolicy
+	}
+	return q
+}
+
+// Returns per object readQuorum and writeQuorum
+// readQuorum is the minimum required disks to read data.
+// writeQuorum is the minimum required disks to write data.
+// The quorum is deduced from the latest xl.json picked by getLatestXLMetaIndex,
+// which is stable for xl.json(s) written at the same time.
+// errXLReadQuorum is returned as xlReadQuorumErr with the object and the
+// count of valid metas found, use isErrXLReadQuorum to match it. ctx.Err() is returned if the
+// context is done before the metas are scanned. The storage class scans
+// pass the context of the admin request, the ObjectLayer calls pass
+// context.Background().
+func objectQuorumFromMeta(ctx context.Context, xl xlObjects, bucket, object string, partsMetaData []xlMetaV1, errs []error) (objectReadQuorum, objectWriteQuorum int, err error) {
+	if err = ctx.Err(); err != nil {
+		return 0, 0, err
+	}
+	qInfo, err := objectQuorumInfoFromMeta(xl, partsMetaData, errs)
+	if err == errXLReadQuorum {
+		err = xlReadQuorumErr{
+			Bucket:     bucket,
+			Object:     object,
+			Available:  qInfo.Available,
+			DataBlocks: qInfo.Required,
+		}
+	}
+	return qInfo.ReadQuorum, qInfo.WriteQuorum, err
+}
+
+// objectQuorumInfo - per object quorum along with the details of
+// the xl.json(s) it was deduced from.
+type objectQuorumInfo struct {
+	ReadQuorum  int
+	WriteQuorum int
+	// Number of latest valid xl.json(s) found.
+	Available int
+	// Number of latest valid xl.json(s) needed, i.e. data blocks.
+	Required int
+	// Parity blocks of the object.
+	ParityBlocks int
+	// Index of the disk whose xl.json the quorum was deduced from,
+	// -1 if no valid xl.json was found. See getLatestXLMetaIndex.
+	LatestIndex int
+	// Indices of the disks which returned an error.
+	ErrIndices []int
+	// Storage class the object was written with, empty if it was
+	// written without storage class, i.e. with the default parity.
+	StorageClass string
+}
+
+// Margin - returns the number of latest valid xl.json(s) found above the
+// data blocks needed for read quorum, negative if read quorum is lost.
+func (q objectQuorumInfo) Margin() int {
+	return q.Available - q.Required
+}
+
+// HealPriority - returns how urgently the object should be healed, higher
+// is more urgent. An object with only its data blocks of valid xl.json(s)
+// found is one disk failure away from being unreadable and gets the top
+// priority of maxErasureBlocks, every additional valid xl.json lowers the
+// priority by one, i.e. the priority is maxErasureBlocks - Margin(). An
+// object missing no xl.json or which already lost read quorum has nothing
+// to gain from being healed first and has priority 0.
+func (q objectQuorumInfo) HealPriority() int {
+	margin := q.Margin()
+	if margin < 0 || margin >= q.ParityBlocks {
+		return 0
+	}
+	return maxErasureBlocks - margin
+}
+
+func (q objectQuorumInfo) String() string {
+	return fmt.Sprintf("needed %d valid metas, found %d, disks with errors %v", q.Required, q.Available, q.ErrIndices)
+}
+
+// Returns per object readQuorum and writeQuorum like objectQuorumFromMeta,
+// the returned objectQuorumInfo is filled even on errXLReadQuorum so that
+// callers can report how far the object is from read quorum.
+func objectQuorumInfoFromMeta(xl xlObjects, partsMetaData []xlMetaV1, errs []error) (qInfo objectQuorumInfo, err error) {
+
+	// get the latest updated Metadata and a count of all the latest updated xlMeta(s),
+	// ties between xlMeta(s) with the same modTime are broken by isPreferredXLMeta.
+	latestIndex, count := getLatestXLMetaIndex(partsMetaData, errs)
+	var latestXLMeta xlMetaV1
+	if latestIndex != -1 {
+		latestXLMeta = partsMetaData[latestIndex]
+	}
+
+	qInfo.LatestIndex = latestIndex
+
+	qInfo.Available = count
+	qInfo.Required = latestXLMeta.Erasure.DataBlocks
+	qInfo.ParityBlocks = latestXLMeta.Erasure.ParityBlocks
+	qInfo.StorageClass = latestXLMeta.Meta[amzStorageClass]
+	for index, err := range errs {
+		if err != nil {
+			qInfo.ErrIndices = append(qInfo.ErrIndices, index)
+		}
+	}
+
+	// latestXLMeta is updated most recently.
+	// All the xlMeta(s) are expected to have same dataBlocks and parityBlocks, heal
+	// repairs the ones which don't, see checkErasureConsistency.
+	// We now check that at least dataBlocks number of xlMeta is available. This means count
+	// should be greater than or equal to dataBlocks field of latestXLMeta. If not we throw read quorum error.
+	if count < latestXLMeta.Erasure.DataBlocks {
+		// This is the case when we can't reliably deduce object quorum
+		return qInfo, errXLReadQuorum
 	}
 
 	// Since all the valid erasure code meta updated at the same time are equivalent, pass dataBlocks
-	// from latestXLMeta to get the quorum
-	return latestXLMeta.Erasure.DataBlocks, latestXLMeta.Erasure.DataBlocks + 1, nil
+	// from latestXLMeta to get the quorum as per the quorum policy of the storage class
+	qInfo.ReadQuorum, qInfo.WriteQuorum = quorumFromDataBlocks(latestXLMeta.Meta[amzStorageClass], latestXLMeta.Erasure.DataBlocks)
+	return qInfo, nil
+}
+
+// Returns the read and write quorum of an object in the storage class
+// with the given data blocks, as per the quorum policy of the storage class.
+// By default readQuorum is dataBlocks and writeQuorum is dataBlocks + 1.
+func quorumFromDataBlocks(sc string, dataBlocks int) (readQuorum, writeQuorum int) {
+	q := getStorageClassQuorumPolicy(sc)
+	return dataBlocks + q.ReadOffset, dataBlocks + q.WriteOffset
+}
+
+// Returns the read and write quorum expected for objects written in the
+// storage class on totalDisks, before any object exists e.g. to check if
+// write quorum can be met with the online disks. The quorum is the same
+// as objectQuorumFromMeta returns for an object written in the class.
+func quorumFromStorageClass(sc string, totalDisks int) (readQuorum, writeQuorum int) {
+	return quorumFromDataBlocks(sc, getRedundancyCount(sc, totalDisks).Data)
+}
+
+// Returns StorageClassWriteQuorum if fewer disks than writeQuorum are online,
+// so that a write fails before any data is written rather than midway. The
+// write quorum is expected from quorumFromDataBlocks, i.e. the same formula
+// as quorumFromStorageClass and objectQuorumFromMeta.
+func checkOnlineWriteQuorum(sc string, writeQuorum, onlineDisks int) error {
+	if onlineDisks < writeQuorum {
+		return StorageClassWriteQuorum{StorageClass: sc, WriteQuorum: writeQuorum, OnlineDisks: onlineDisks}
+	}
+	return nil
+}
+
+// Returns per object quorum like objectQuorumInfoFromMeta, along with whether
+// full redundancy of the object is achievable with the given number of online
+// disks. An object with more data and parity blocks than online disks can still
+// be read, but writes and heals can't restore all of its parity blocks until
+// the offline disks are back.
+func objectRedundancyFromMeta(xl xlObjects, partsMetaData []xlMetaV1, errs []error, onlineDisks int) (qInfo objectQuorumInfo, fullRedundancy bool, err error) {
+	qInfo, err = objectQuorumInfoFromMeta(xl, partsMetaData, errs)
+	if err != nil {
+		return qInfo, false, err
+	}
+	return qInfo, qInfo.Required+qInfo.ParityBlocks <= onlineDisks, nil
+}
+
+// reparityStatus - advice on re-encoding an object for a new disk count.
+type reparityStatus int
+
+const (
+	reparityNotNeeded      reparityStatus = iota // Object layout matches its storage class
+	reparityRecommended                          // Object fits but storage class parity differs
+	reparityRequired                             // Object has more data and parity blocks than disks
+	reparityReadQuorumLost                       // Object can't be read, it has more data blocks than disks
+)
+
+func (s reparityStatus) String() string {
+	switch s {
+	case reparityNotNeeded:
+		return "not needed"
+	case reparityRecommended:
+		return "recommended"
+	case reparityRequired:
+		return "required"
+	case reparityReadQuorumLost:
+		return "READ QUORUM LOST"
+	}
+	return "unknown"
+}
+
+// reparityAdvice - current and recommended layout of an object on a new
+// disk count, see recommendReparity.
+type reparityAdvice struct {
+	Status        reparityStatus
+	StorageClass  string
+	CurrentData   int
+	CurrentParity int
+	// Data and parity blocks of the storage class on the new disk count.
+	Data   int
+	Parity int
+}
+
+// Advises whether an object should be re-encoded once the setup has
+// newDiskCount disks, e.g. after decommissioning disks. The recommended
+// layout is the layout of the object storage class on the new disk count,
+// the default layout for objects without storage class. This only advises,
+// no data is moved. An object which can't meet read quorum with the new
+// disk count is reported as reparityReadQuorumLost, re-encoding is not
+// possible as the object can't be read anymore.
+func recommendReparity(meta xlMetaV1, newDiskCount int) reparityAdvice {
+	sc := meta.Meta[amzStorageClass]
+	info := getRedundancyCount(sc, newDiskCount)
+	advice := reparityAdvice{
+		StorageClass:  getObjectStorageClass(meta.Meta),
+		CurrentData:   meta.Erasure.DataBlocks,
+		CurrentParity: meta.Erasure.ParityBlocks,
+		Data:          info.Data,
+		Parity:        info.Parity,
+	}
+
+	readQuorum, _ := quorumFromDataBlocks(sc, meta.Erasure.DataBlocks)
+	switch {
+	case readQuorum > newDiskCount:
+		advice.Status = reparityReadQuorumLost
+	case meta.Erasure.DataBlocks+meta.Erasure.ParityBlocks > newDiskCount:
+		advice.Status = reparityRequired
+	case meta.Erasure.ParityBlocks != info.Parity:
+		advice.Status = reparityRecommended
+	default:
+		advice.Status = reparityNotNeeded
+	}
+	return advice
+}
+
+// StorageClassParity - resolved data and parity disks of a storage class.
+type StorageClassParity struct {
+	Data   int    `json:"data"`
+	Parity int    `json:"parity"`
+	Source string `json:"source"`
+	// Disk space consumed per byte of object data, see storageOverhead.
+	Overhead float64 `json:"overhead"`
+}
+
+// StorageClassInfo - effective storage class configuration of a server.
+type StorageClassInfo struct {
+	TotalDisks     int                `json:"totalDisks"`
+	ErasureMode    bool               `json:"erasureMode"`
+	StorageClasses []string           `json:"storageClasses"`
+	Standard       StorageClassParity `json:"standard"`
+	RRS            StorageClassParity `json:"rrs"`
+	MaxDurability  StorageClassParity `json:"maxDurability"`
+
+	// Storage classes which can not be written with the disks
+	// currently online, see checkParityFeasibility.
+	OnlineDisks int      `json:"onlineDisks,omitempty"`
+	Warnings    []string `json:"warnings,omitempty"`
+
+	// Set if the configured storage classes are no longer valid
+	// for the current disks, see checkStorageClassHealth.
+	HealthError string `json:"healthError,omitempty"`
+}
+
+// Sets the warnings for all the storage classes which can not be written
+// with the given number of online disks, the warnings are also returned.
+func (info *StorageClassInfo) checkFeasibility(onlineDisks int) (errs []error) {
+	info.OnlineDisks = onlineDisks
+	info.Warnings = nil
+	classes := []struct {
+		name   string
+		parity StorageClassParity
+	}{
+		{standardStorageClass, info.Standard},
+		{reducedRedundancyStorageClass, info.RRS},
+		{maxDurabilityStorageClass, info.MaxDurability},
+	}
+	for _, class := range classes {
+		sc := storageClass{Scheme: supportedStorageClassScheme, Parity: class.parity.Parity}
+		if err := checkParityFeasibility(sc, onlineDisks); err != nil {
+			err = fmt.Errorf("%s: %s", class.name, err)
+			info.Warnings = append(info.Warnings, err.Error())
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+const (
+	// Storage class parity set via environment or config.json
+	storageClassSourceConfig = "config"
+	// Storage class parity falls back to default value
+	storageClassSourceDefault = "default"
+	// Storage class parity set for the bucket
+	storageClassSourceBucket = "bucket"
+	// Storage class set by a bucket storage class rule
+	storageClassSourceRule = "rule"
+	// Parity forced or storage class set in the request
+	storageClassSourceRequest = "request"
+	// Parity lowered for an object smaller than the small object threshold
+	storageClassSourceSize = "size"
+)
+
+// Returns the effective data and parity disks for all the storage classes
+// based on the current disk count and the configured storage classes.
+func getStorageClassInfo() StorageClassInfo {
+	disks := len(globalEndpoints)
+	info := StorageClassInfo{
+		TotalDisks: disks,
+		// disks < 4 means this is not a erasure coded setup
+		ErasureMode: disks >= 4,
+		// Storage classes accepted in x-amz-storage-class header
+		StorageClasses: ValidStorageClasses(),
+	}
+
+	ssInfo := getRedundancyCount(standardStorageClass, disks)
+	info.Standard.Data, info.Standard.Parity = ssInfo.Data, ssInfo.Parity
+	info.Standard.Overhead = storageOverhead(standardStorageClass, disks)
+	info.Standard.Source = storageClassSourceDefault
+	if !ssInfo.UsedDefault {
+		info.Standard.Source = storageClassSourceConfig
+	}
+
+	rrsInfo := getRedundancyCount(reducedRedundancyStorageClass, disks)
+	info.RRS.Data, info.RRS.Parity = rrsInfo.Data, rrsInfo.Parity
+	info.RRS.Overhead = storageOverhead(reducedRedundancyStorageClass, disks)
+	info.RRS.Source = storageClassSourceDefault
+	if !rrsInfo.UsedDefault {
+		info.RRS.Source = storageClassSourceConfig
+	}
+
+	maxInfo := getRedundancyCount(maxDurabilityStorageClass, disks)
+	info.MaxDurability.Data, info.MaxDurability.Parity = maxInfo.Data, maxInfo.Parity
+	info.MaxDurability.Overhead = storageOverhead(maxDurabilityStorageClass, disks)
+	info.MaxDurability.Source = storageClassSourceDefault
+	if !maxInfo.UsedDefault {
+		info.MaxDurability.Source = storageClassSourceConfig
+	}
+
+	return info
+}
+
+// Returns the active storage class configuration reported in server info,
+// the data and parity disks each storage class resolves to on the current
+// disks, along with the configured scheme.
+func getServerStorageClassConfig() ServerStorageClassConfig {
+	scConfig := ServerStorageClassConfig{StorageClasses: ValidStorageClasses()}
+
+	// disks < 4 means this is not a erasure coded setup and so storage class is not supported
+	disks := len(globalEndpoints)
+	if disks < 4 {
+		return scConfig
+	}
+
+	layouts := getStorageClassLayouts(disks)
+	scConfig.Standard = layouts[standardStorageClass]
+	scConfig.RRS = layouts[reducedRedundancyStorageClass]
+	scConfig.MaxDurability = layouts[maxDurabilityStorageClass]
+	scConfig.Scratch = layouts[scratchStorageClass]
+	return scConfig
+}
+
+// Returns the layout each of ValidStorageClasses() resolves to on totalDisks,
+// reported along with the erasure backend details in storage info. It is
+// computed from the current storage class config, nothing is saved in format.
+func getStorageClassLayouts(totalDisks int) map[string]ServerStorageClassLayout {
+	ssc, rrsc, maxsc := getStorageClassGlobals()
+	layouts := make(map[string]ServerStorageClassLayout)
+	for _, sc := range ValidStorageClasses() {
+		var cfg storageClass
+		switch sc {
+		case standardStorageClass:
+			cfg = ssc
+		case reducedRedundancyStorageClass:
+			cfg = rrsc
+		case maxDurabilityStorageClass:
+			cfg = maxsc
+		}
+		layout := toServerStorageClassLayout(cfg, getRedundancyCount(sc, totalDisks))
+		if sc == scratchStorageClass {
+			// Scratch storage class is not configurable.
+			layout.Source = storageClassSourceDefault
+		}
+		layouts[sc] = layout
+	}
+	return layouts
+}
+
+// Returns the layout of a storage class configured as sc, which resolved to info.
+func toServerStorageClassLayout(sc storageClass, info redundancyInfo) ServerStorageClassLayout {
+	layout := ServerStorageClassLayout{
+		Data:   info.Data,
+		Parity: info.Parity,
+		Source: storageClassSourceDefault,
+	}
+	if !info.UsedDefault {
+		layout.Scheme = sc.Scheme
+		layout.Source = storageClassSourceConfig
+	}
+	return layout
 }
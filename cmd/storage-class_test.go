@@ -18,9 +18,22 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	errors2 "github.com/minio/minio/pkg/errors"
 )
 
 func TestParseStorageClass(t *testing.T) {
@@ -33,27 +46,47 @@ func testParseStorageClass(obj ObjectLayer, instanceType string, t TestErrHandle
 		storageClassEnv string
 		wantSc          storageClass
 		expectedError   error
+		expectedMsg     string
 	}{
 		{1, "EC:3", storageClass{
 			Scheme: "EC",
 			Parity: 3},
-			nil},
+			nil, ""},
 		{2, "EC:4", storageClass{
 			Scheme: "EC",
 			Parity: 4},
-			nil},
+			nil, ""},
 		{3, "AB:4", storageClass{
 			Scheme: "EC",
 			Parity: 4},
-			errors.New("Unsupported scheme AB. Supported scheme is EC")},
-		{4, "EC:4:5", storageClass{
+			errStorageClassUnsupportedScheme, "Unsupported scheme AB. Supported scheme is EC"},
+		{4, "EC:4:5:6", storageClass{
 			Scheme: "EC",
 			Parity: 4},
-			errors.New("Too many sections in EC:4:5")},
+			errStorageClassTooManySections, "Too many sections in EC:4:5:6"},
 		{5, "AB", storageClass{
 			Scheme: "EC",
 			Parity: 4},
-			errors.New("Too few sections in AB")},
+			errStorageClassTooFewSections, "Too few sections in AB"},
+		{6, "EC:A", storageClass{},
+			errStorageClassInvalidParity, `strconv.Atoi: parsing "A": invalid syntax`},
+		{7, "EC:16", storageClass{
+			Scheme: "EC",
+			Parity: 16},
+			nil, ""},
+		{8, "EC:0", storageClass{
+			Scheme: "EC",
+			Parity: 0},
+			nil, ""},
+		{9, "EC:17", storageClass{},
+			errStorageClassInvalidParity, "Parity disks should be between 0 and 16 in EC:17"},
+		{10, "EC:-1", storageClass{},
+			errStorageClassInvalidParity, "Parity disks should be between 0 and 16 in EC:-1"},
+		// Overflows int on all platforms.
+		{11, "EC:99999999999999999999", storageClass{},
+			errStorageClassInvalidParity, "Parity disks should be between 0 and 16 in EC:99999999999999999999"},
+		{12, "EC:-99999999999999999999", storageClass{},
+			errStorageClassInvalidParity, "Parity disks should be between 0 and 16 in EC:-99999999999999999999"},
 	}
 	for _, tt := range tests {
 		gotSc, err := parseStorageClass(tt.storageClassEnv)
@@ -69,8 +102,314 @@ func testParseStorageClass(obj ObjectLayer, instanceType string, t TestErrHandle
 			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.wantSc, gotSc)
 			return
 		}
+		if tt.expectedError != nil {
+			scErr, ok := err.(storageClassError)
+			if !ok || scErr.Err != tt.expectedError {
+				t.Errorf("Test %d, Expected %v, got %#v", tt.name, tt.expectedError, err)
+			}
+			if err.Error() != tt.expectedMsg {
+				t.Errorf("Test %d, Expected %s, got %s", tt.name, tt.expectedMsg, err)
+			}
+			if scErr.Input != tt.storageClassEnv {
+				t.Errorf("Test %d, Expected input %s in error, got %#v", tt.name, tt.storageClassEnv, err)
+			}
+		}
+	}
+}
+
+// Tests that parity set as a percentage is parsed without the disks
+// and resolved for the disks by resolvePercentParity.
+func TestParseStorageClassPercentage(t *testing.T) {
+	tests := []struct {
+		name            int
+		storageClassEnv string
+		wantSc          storageClass
+		expectedParity  int
+		expectedError   error
+	}{
+		{1, "EC:50%", storageClass{Scheme: "EC", Percent: 50}, 8, nil},
+		{2, "EC:25%", storageClass{Scheme: "EC", Percent: 25}, 4, nil},
+		// 16 * 20% = 3.2 is rounded to 3.
+		{3, "EC:20%", storageClass{Scheme: "EC", Percent: 20}, 3, nil},
+		// 16 * 22% = 3.52 is rounded to 4.
+		{4, "EC:22%", storageClass{Scheme: "EC", Percent: 22}, 4, nil},
+		// 16 * 5% = 0.8 never drops below minimum parity disks.
+		{5, "EC:5%", storageClass{Scheme: "EC", Percent: 5}, 2, nil},
+		{6, "EC:0%", storageClass{}, 0, storageClassError{errStorageClassInvalidParity, "EC:0%", "Parity percentage should be greater than 0% and less than or equal to 50% in EC:0%"}},
+		{7, "EC:51%", storageClass{}, 0, storageClassError{errStorageClassInvalidParity, "EC:51%", "Parity percentage should be greater than 0% and less than or equal to 50% in EC:51%"}},
+		{8, "EC:99999999999999999999%", storageClass{}, 0, storageClassError{errStorageClassInvalidParity, "EC:99999999999999999999%",
+			"Parity percentage should be greater than 0% and less than or equal to 50% in EC:99999999999999999999%"}},
+	}
+	for _, tt := range tests {
+		gotSc, err := parseStorageClass(tt.storageClassEnv)
+		if tt.expectedError == nil && err != nil {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedError, err)
+			continue
+		}
+		if tt.expectedError != nil && !reflect.DeepEqual(err, tt.expectedError) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedError, err)
+			continue
+		}
+		if !reflect.DeepEqual(gotSc, tt.wantSc) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.wantSc, gotSc)
+			continue
+		}
+		if tt.expectedError != nil {
+			continue
+		}
+		if parity := resolvePercentParity(gotSc, 16).Parity; parity != tt.expectedParity {
+			t.Errorf("Test %d, Expected parity %d, got %d", tt.name, tt.expectedParity, parity)
+		}
+		// Percentage must round-trip through MarshalText.
+		text, err := gotSc.MarshalText()
+		if err != nil || string(text) != tt.storageClassEnv {
+			t.Errorf("Test %d, Expected %s, got %s", tt.name, tt.storageClassEnv, text)
+		}
+	}
+}
+
+func TestParseStorageClassRelative(t *testing.T) {
+	tests := []struct {
+		name            int
+		storageClassEnv string
+		wantSc          storageClass
+		expectedError   error
+	}{
+		{1, "EC:x0.5", storageClass{Scheme: "EC", Relative: 0.5}, nil},
+		{2, "EC:x1", storageClass{Scheme: "EC", Relative: 1}, nil},
+		{3, "EC:x0.25", storageClass{Scheme: "EC", Relative: 0.25}, nil},
+		{4, "EC:x0", storageClass{}, storageClassError{errStorageClassInvalidParity, "EC:x0", "Relative parity should be greater than 0 and less than or equal to 1 in EC:x0"}},
+		{5, "EC:x1.5", storageClass{}, storageClassError{errStorageClassInvalidParity, "EC:x1.5", "Relative parity should be greater than 0 and less than or equal to 1 in EC:x1.5"}},
+		{6, "EC:xNaN", storageClass{}, storageClassError{errStorageClassInvalidParity, "EC:xNaN", "Relative parity should be greater than 0 and less than or equal to 1 in EC:xNaN"}},
+		{7, "EC:x", storageClass{}, storageClassError{errStorageClassInvalidParity, "EC:x", "Relative parity should be greater than 0 and less than or equal to 1 in EC:x"}},
+	}
+	for _, tt := range tests {
+		gotSc, err := parseStorageClass(tt.storageClassEnv)
+		if tt.expectedError == nil && err != nil {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedError, err)
+			continue
+		}
 		if tt.expectedError != nil && !reflect.DeepEqual(err, tt.expectedError) {
 			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedError, err)
+			continue
+		}
+		if !reflect.DeepEqual(gotSc, tt.wantSc) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.wantSc, gotSc)
+			continue
+		}
+		if tt.expectedError != nil {
+			continue
+		}
+		// Relative parity must round-trip through MarshalText, even once resolved.
+		gotSc.Parity = 3
+		text, err := gotSc.MarshalText()
+		if err != nil || string(text) != tt.storageClassEnv {
+			t.Errorf("Test %d, Expected %s, got %s", tt.name, tt.storageClassEnv, text)
+		}
+		var unmarshalSc storageClass
+		if err = unmarshalSc.UnmarshalText(text); err != nil || unmarshalSc.Relative != tt.wantSc.Relative {
+			t.Errorf("Test %d, Expected relative parity %v, got %v, %v", tt.name, tt.wantSc.Relative, unmarshalSc.Relative, err)
+		}
+	}
+}
+
+func TestResolveRelativeParity(t *testing.T) {
+	tests := []struct {
+		name           int
+		rrsc           storageClass
+		ssc            storageClass
+		disks          int
+		expectedParity int
+	}{
+		{1, storageClass{Scheme: "EC", Parity: 3}, storageClass{Scheme: "EC", Parity: 8}, 16, 3},
+		{2, storageClass{Scheme: "EC", Relative: 0.5}, storageClass{Scheme: "EC", Parity: 6}, 16, 3},
+		// 0.5 * 7 = 3.5 is floored to 3.
+		{3, storageClass{Scheme: "EC", Relative: 0.5}, storageClass{Scheme: "EC", Parity: 7}, 16, 3},
+		// Relative to N/2 if Standard storage class is not set.
+		{4, storageClass{Scheme: "EC", Relative: 0.5}, storageClass{}, 16, 4},
+		{5, storageClass{Scheme: "EC", Relative: 0.5}, storageClass{}, 12, 3},
+		// Parity never drops below minimum parity disks.
+		{6, storageClass{Scheme: "EC", Relative: 0.1}, storageClass{Scheme: "EC", Parity: 6}, 16, 2},
+		{7, storageClass{Scheme: "EC", Relative: 1}, storageClass{Scheme: "EC", Parity: 6}, 16, 6},
+	}
+	for _, tt := range tests {
+		rrsc := resolveRelativeParity(tt.rrsc, tt.ssc, tt.disks)
+		if rrsc.Parity != tt.expectedParity {
+			t.Errorf("Test %d, Expected parity %d, got %d", tt.name, tt.expectedParity, rrsc.Parity)
+		}
+		if rrsc.Relative != tt.rrsc.Relative {
+			t.Errorf("Test %d, Expected relative parity %v to be kept, got %v", tt.name, tt.rrsc.Relative, rrsc.Relative)
+		}
+	}
+}
+
+// Fuzz storageClass MarshalText and UnmarshalText, every storage class
+// with a registered scheme must round-trip without losing its parity.
+func FuzzStorageClassText(f *testing.F) {
+	f.Add("EC", 4, 0)
+	f.Add("EC", 0, 0)
+	f.Add("EC", 0, 25)
+	f.Add("EC", -1, 0)
+	f.Add("", 4, 0)
+	f.Add("RS", 4, 0)
+	f.Add("EC", 0, 51)
+	f.Add("EC", 17, 0)
+	f.Fuzz(func(t *testing.T, scheme string, parity, percent int) {
+		sc := storageClass{Scheme: scheme, Parity: parity, Percent: percent}
+		text, err := sc.MarshalText()
+		if err != nil {
+			t.Fatalf("Unable to marshal %v: %v", sc, err)
+		}
+
+		var gotSc storageClass
+		err = gotSc.UnmarshalText(text)
+		if scheme == "" {
+			// Storage class without a scheme is unset.
+			if err != nil || gotSc != (storageClass{}) {
+				t.Fatalf("Expected unset storage class for %v, got %v, %v", sc, gotSc, err)
+			}
+			return
+		}
+		invalidParity := percent == 0 && (parity < 0 || parity > maxErasureBlocks)
+		if _, ok := storageClassSchemes[scheme]; !ok || invalidParity || percent < 0 || percent > maximumParityPercent {
+			if err == nil {
+				t.Fatalf("Expected an error for %v, got %v", sc, gotSc)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("Unable to unmarshal %s: %v", text, err)
+		}
+
+		if percent != 0 {
+			// Parity of a percentage is always derived from total disks.
+			sc.Parity = getParityFromPercent(percent, len(globalEndpoints))
+		}
+		if gotSc != sc {
+			t.Fatalf("Expected %v, got %v", sc, gotSc)
+		}
+	})
+}
+
+func TestValidStorageClasses(t *testing.T) {
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+
+	// Scratch storage class is only valid on an erasure coded setup.
+	globalEndpoints = mustGetNewEndpointList("/d1", "/d2", "/d3", "/d4")
+	defer resetGlobalEndpoints()
+
+	validScs := ValidStorageClasses()
+	expected := []string{standardStorageClass, reducedRedundancyStorageClass, maxDurabilityStorageClass, scratchStorageClass}
+	if !reflect.DeepEqual(validScs, expected) {
+		t.Fatalf("Expected %v, got %v", expected, validScs)
+	}
+	for _, sc := range validScs {
+		if !isValidStorageClassMeta(sc) {
+			t.Errorf("Expected %s to be a valid storage class", sc)
+		}
+	}
+	if isValidStorageClassMeta("GLACIER") {
+		t.Errorf("Expected %s to be an invalid storage class", "GLACIER")
+	}
+
+	// Returned list is a copy and can't modify the valid storage classes.
+	validScs[0] = "GLACIER"
+	if isValidStorageClassMeta("GLACIER") {
+		t.Errorf("Expected %s to be an invalid storage class", "GLACIER")
+	}
+}
+
+func TestStorageClassString(t *testing.T) {
+	tests := []struct {
+		name     int
+		sc       storageClass
+		expected string
+	}{
+		{1, storageClass{Scheme: "EC", Parity: 4}, "EC:4"},
+		{2, storageClass{Scheme: "EC", Parity: 4, Percent: 25}, "EC:25%"},
+		{3, storageClass{Scheme: "EC"}, "EC:0"},
+		{4, storageClass{}, "<unset>"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf("%v", tt.sc); got != tt.expected {
+			t.Errorf("Test %d, Expected %s, got %s", tt.name, tt.expected, got)
+		}
+		if got := tt.sc.String(); got != tt.expected {
+			t.Errorf("Test %d, Expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestStorageClassConfigDiff(t *testing.T) {
+	ec4 := storageClass{Scheme: "EC", Parity: 4}
+	tests := []struct {
+		name     int
+		sCfg     storageClassConfig
+		other    storageClassConfig
+		expected string
+	}{
+		{1, storageClassConfig{}, storageClassConfig{}, ""},
+		{2, storageClassConfig{Standard: ec4}, storageClassConfig{Standard: ec4}, ""},
+		// Storage classes not set are equal irrespective of their other fields.
+		{3, storageClassConfig{RRS: storageClass{Parity: 2}}, storageClassConfig{RRS: storageClass{Quorum: quorumPolicy{1, 2}}}, ""},
+		// Unset quorum policy is the default quorum policy.
+		{4, storageClassConfig{Standard: ec4}, storageClassConfig{Standard: storageClass{Scheme: "EC", Parity: 4, Quorum: defaultQuorumPolicy}}, ""},
+		{5, storageClassConfig{Standard: ec4}, storageClassConfig{Standard: storageClass{Scheme: "EC", Parity: 6}}, "standard: EC:4 -> EC:6"},
+		{6, storageClassConfig{}, storageClassConfig{RRS: storageClass{Scheme: "EC", Parity: 2}}, "rrs: <unset> -> EC:2"},
+		{7, storageClassConfig{Standard: ec4}, storageClassConfig{Standard: storageClass{Scheme: "EC", Parity: 4, Percent: 25}}, "standard: EC:4 -> EC:25%"},
+		{8, storageClassConfig{Standard: ec4, RRS: storageClass{Scheme: "EC", Parity: 2}},
+			storageClassConfig{Standard: storageClass{Scheme: "EC", Parity: 6, Quorum: quorumPolicy{1, 2}}},
+			"standard: EC:4 -> EC:6; standard quorum: 0:1 -> 1:2; rrs: EC:2 -> <unset>"},
+	}
+	for _, tt := range tests {
+		if diff := tt.sCfg.Diff(tt.other); diff != tt.expected {
+			t.Errorf("Test %d, Expected %q, got %q", tt.name, tt.expected, diff)
+		}
+		if equal := tt.sCfg.Equal(tt.other); equal != (tt.expected == "") {
+			t.Errorf("Test %d, Expected equal %t, got %t", tt.name, tt.expected == "", equal)
+		}
+		// Equality is symmetric.
+		if equal := tt.other.Equal(tt.sCfg); equal != (tt.expected == "") {
+			t.Errorf("Test %d, Expected equal %t, got %t", tt.name, tt.expected == "", equal)
+		}
+	}
+}
+
+// Test UnmarshalText clears a reused storage class on an empty value.
+func TestStorageClassUnmarshalTextEmpty(t *testing.T) {
+	var sc storageClass
+	if err := sc.UnmarshalText([]byte("EC:25%")); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if sc.Scheme != "EC" || sc.Percent != 25 {
+		t.Fatalf("Expected EC:25%%, got %v", sc)
+	}
+	if err := sc.UnmarshalText([]byte("")); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if sc != (storageClass{}) {
+		t.Errorf("Expected storage class to be cleared, got %v", sc)
+	}
+}
+
+// Test getParityFromPercent for odd and small disk counts.
+func TestGetParityFromPercent(t *testing.T) {
+	tests := []struct {
+		percent int
+		disks   int
+		want    int
+	}{
+		{50, 16, 8},
+		{50, 15, 7},
+		{50, 5, 2},
+		{30, 10, 3},
+		{35, 10, 4},
+		{1, 100, 2},
+	}
+	for i, tt := range tests {
+		if got := getParityFromPercent(tt.percent, tt.disks); got != tt.want {
+			t.Errorf("Test %d, Expected %d, got %d", i+1, tt.want, got)
 		}
 	}
 }
@@ -96,6 +435,8 @@ func testValidateRRSParity(obj ObjectLayer, instanceType string, dirs []string,
 		{3, 7, 6, errors.New("Reduced redundancy storage class parity disks should be less than 6")},
 		{4, 9, 0, errors.New("Reduced redundancy storage class parity disks should be less than 8")},
 		{5, 3, 3, errors.New("Reduced redundancy storage class parity disks should be less than 3")},
+		// EC:0 is the default parity.
+		{6, 0, 4, nil},
 	}
 	for _, tt := range tests {
 		err := validateRRSParity(tt.rrsParity, tt.ssParity)
@@ -135,6 +476,8 @@ func testValidateSSParity(obj ObjectLayer, instanceType string, dirs []string, t
 		{4, 4, 6, errors.New("Standard storage class parity disks should be greater than 6")},
 		{5, 9, 0, errors.New("Standard storage class parity disks should be less than or equal to 8")},
 		{6, 3, 3, errors.New("Standard storage class parity disks should be greater than 3")},
+		// EC:0 is the default parity.
+		{7, 0, 6, nil},
 	}
 	for _, tt := range tests {
 		err := validateSSParity(tt.ssParity, tt.rrsParity)
@@ -152,226 +495,3387 @@ func testValidateSSParity(obj ObjectLayer, instanceType string, dirs []string, t
 	}
 }
 
-func TestRedundancyCount(t *testing.T) {
-	ExecObjectLayerTestWithDirs(t, testGetRedundancyCount)
+func TestValidateParity(t *testing.T) {
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+
+	tests := []struct {
+		name          int
+		class         string
+		parity        int
+		disks         int
+		other         int
+		allowSmall    bool
+		expectedError error
+	}{
+		// Rules shared by all storage classes.
+		{1, reducedRedundancyStorageClass, 2, 2, 0, false, errors.New("Setting storage class only allowed for erasure coding mode")},
+		{2, standardStorageClass, 2, 2, 0, false, errors.New("Setting storage class only allowed for erasure coding mode")},
+		{3, reducedRedundancyStorageClass, 2, maximumErasureDisks + 1, 0, false,
+			checkMaximumErasureDisks(maximumErasureDisks + 1)},
+		{4, standardStorageClass, 2, maximumErasureDisks + 1, 0, false,
+			checkMaximumErasureDisks(maximumErasureDisks + 1)},
+		// EC:0 is the default parity, even where storage class is not supported.
+		{5, reducedRedundancyStorageClass, 0, 2, 0, false, nil},
+		{6, standardStorageClass, 0, 2, 0, false, nil},
+		// Reduced redundancy storage class.
+		{7, reducedRedundancyStorageClass, 2, 16, 4, false, nil},
+		{8, reducedRedundancyStorageClass, 2, 16, 0, false, nil},
+		{9, reducedRedundancyStorageClass, 2, 4, 0, false, errors.New("Reduced redundancy storage class not supported for 4 disk setup")},
+		{10, reducedRedundancyStorageClass, 2, 4, 0, true, nil},
+		{11, reducedRedundancyStorageClass, 2, 4, 1, true, errors.New("Reduced redundancy storage class parity disks should be less than or equal to 1")},
+		{12, reducedRedundancyStorageClass, 1, 16, 4, false, errors.New("Reduced redundancy storage class parity should be greater than or equal to 2")},
+		{13, reducedRedundancyStorageClass, 4, 16, 4, false, errors.New("Reduced redundancy storage class parity disks should be less than 4")},
+		{14, reducedRedundancyStorageClass, 9, 16, 0, false, errors.New("Reduced redundancy storage class parity disks should be less than 8")},
+		{15, reducedRedundancyStorageClass, 4, 5, 5, false, errors.New("Reduced redundancy storage class parity disks should be less than or equal to 3, to leave at least 2 data disks")},
+		// Standard storage class.
+		{16, standardStorageClass, 4, 16, 2, false, nil},
+		{17, standardStorageClass, 8, 16, 0, false, nil},
+		{18, standardStorageClass, 1, 16, 0, false, errors.New("Standard storage class parity disks should be greater than or equal to 2")},
+		{19, standardStorageClass, 2, 16, 2, false, errors.New("Standard storage class parity disks should be greater than 2")},
+		{20, standardStorageClass, 2, 4, 2, true, nil},
+		{21, standardStorageClass, 2, 4, 3, true, errors.New("Standard storage class parity disks should be greater than or equal to 3")},
+		{22, standardStorageClass, 9, 16, 2, false, errors.New("Standard storage class parity disks should be less than or equal to 8")},
+		{23, standardStorageClass, 3, 4, 0, false, errors.New("Standard storage class parity disks should be less than or equal to 2, to leave at least 2 data disks")},
+		// Max durability storage class.
+		{24, maxDurabilityStorageClass, 8, 16, 6, false, nil},
+		{25, maxDurabilityStorageClass, 6, 16, 6, false, errors.New("Max durability storage class parity disks should be greater than 6")},
+		{26, maxDurabilityStorageClass, 8, 16, 0, false, errors.New("Max durability storage class parity disks should be greater than 8")},
+		// Storage classes without parity.
+		{27, scratchStorageClass, 2, 16, 0, false, errors.New("Parity is not supported for SCRATCH storage class")},
+	}
+	for _, tt := range tests {
+		globalStorageClassAllowSmall = tt.allowSmall
+		err := ValidateParity(tt.class, tt.parity, tt.disks, tt.other)
+		if !reflect.DeepEqual(err, tt.expectedError) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedError, err)
+		}
+	}
 }
 
-func testGetRedundancyCount(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
-	// Reset global storage class flags
+func TestSuggestStorageClassConfig(t *testing.T) {
 	resetGlobalStorageEnvs()
-	xl := obj.(*xlObjects)
+	tests := []struct {
+		name        int
+		disks       int
+		expectedCfg storageClassConfig
+	}{
+		{1, 2, storageClassConfig{}},
+		{2, 4, storageClassConfig{Standard: storageClass{Scheme: "EC", Parity: 2}}},
+		{3, 6, storageClassConfig{Standard: storageClass{Scheme: "EC", Parity: 3}, RRS: storageClass{Scheme: "EC", Parity: 2}}},
+		{4, 8, storageClassConfig{Standard: storageClass{Scheme: "EC", Parity: 3}, RRS: storageClass{Scheme: "EC", Parity: 2}}},
+		{5, 16, storageClassConfig{Standard: storageClass{Scheme: "EC", Parity: 4}, RRS: storageClass{Scheme: "EC", Parity: 2}}},
+		{6, 32, storageClassConfig{Standard: storageClass{Scheme: "EC", Parity: 8}, RRS: storageClass{Scheme: "EC", Parity: 2}}},
+	}
+	for _, tt := range tests {
+		if sCfg := SuggestStorageClassConfig(tt.disks); sCfg != tt.expectedCfg {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedCfg, sCfg)
+		}
+	}
+
+	// Suggestion is always valid for the given disks.
+	for disks := 4; disks <= 64; disks++ {
+		sCfg := SuggestStorageClassConfig(disks)
+		if err := ValidateStorageClassForDisks(sCfg.Standard, sCfg.RRS, disks); err != nil {
+			t.Errorf("Suggested storage class %v for %d disks is invalid: %v", sCfg, disks, err)
+		}
+	}
+}
 
+func TestMaximumErasureDisks(t *testing.T) {
 	tests := []struct {
-		name           int
-		sc             string
-		disks          []StorageAPI
-		expectedData   int
-		expectedParity int
+		name          int
+		disks         int
+		expectedError bool
 	}{
-		{1, reducedRedundancyStorageClass, xl.storageDisks, 14, 2},
-		{2, standardStorageClass, xl.storageDisks, 8, 8},
-		{3, "", xl.storageDisks, 8, 8},
-		{4, reducedRedundancyStorageClass, xl.storageDisks, 9, 7},
-		{5, standardStorageClass, xl.storageDisks, 10, 6},
+		{1, 8, false},
+		{2, maximumErasureDisks, false},
+		{3, maximumErasureDisks + 1, true},
+		{4, 4 * maximumErasureDisks, true},
 	}
 	for _, tt := range tests {
-		// Set env var for test case 4
-		if tt.name == 4 {
-			globalRRStorageClass.Parity = 7
+		if err := checkMaximumErasureDisks(tt.disks); (err != nil) != tt.expectedError {
+			t.Errorf("Test %d, Expected error %t, got %v", tt.name, tt.expectedError, err)
 		}
-		// Set env var for test case 5
-		if tt.name == 5 {
-			globalStandardStorageClass.Parity = 6
+		// Parity valid for any number of disks is still rejected above the maximum.
+		rrsErrs := checkRRSParity(2, 4, tt.disks)
+		if (len(rrsErrs) != 0) != tt.expectedError {
+			t.Errorf("Test %d, Expected RRS error %t, got %v", tt.name, tt.expectedError, rrsErrs)
 		}
-		data, parity := getRedundancyCount(tt.sc, len(tt.disks))
-		if data != tt.expectedData {
-			t.Errorf("Test %d, Expected data disks %d, got %d", tt.name, tt.expectedData, data)
-			return
+		ssErrs := checkSSParity(2, 0, tt.disks)
+		if (len(ssErrs) != 0) != tt.expectedError {
+			t.Errorf("Test %d, Expected standard error %t, got %v", tt.name, tt.expectedError, ssErrs)
 		}
-		if parity != tt.expectedParity {
-			t.Errorf("Test %d, Expected parity disks %d, got %d", tt.name, tt.expectedParity, parity)
-			return
+	}
+}
+
+func TestValidateStorageClassSchemes(t *testing.T) {
+	// Register a second scheme for the duration of the test.
+	storageClassSchemes["RS"] = storageClassSchemes[supportedStorageClassScheme]
+	defer delete(storageClassSchemes, "RS")
+
+	tests := []struct {
+		name          int
+		ssScheme      string
+		rrsScheme     string
+		expectedError error
+	}{
+		{1, "EC", "EC", nil},
+		{2, "EC", "", nil},
+		{3, "", "RS", nil},
+		{4, "EC", "RS", errors.New("Standard storage class scheme EC and reduced redundancy storage class scheme RS should be the same")},
+	}
+	for _, tt := range tests {
+		if err := validateStorageClassSchemes(tt.ssScheme, tt.rrsScheme); !reflect.DeepEqual(err, tt.expectedError) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedError, err)
 		}
 	}
+
+	// Mismatched schemes are rejected when config.json is loaded.
+	var cfg storageClassConfig
+	expectedErr := "Invalid storage class config: Standard storage class scheme RS and reduced redundancy storage class scheme EC should be the same"
+	if err := json.Unmarshal([]byte(`{"standard":"RS:4","rrs":"EC:2"}`), &cfg); err == nil || err.Error() != expectedErr {
+		t.Errorf("Expected %s, got %v", expectedErr, err)
+	}
 }
 
-func TestObjectQuorumFromMeta(t *testing.T) {
-	ExecObjectLayerTestWithDirs(t, testObjectQuorumFromMeta)
+func TestStorageClassConfigJSON(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testStorageClassConfigJSON)
 }
 
-func testObjectQuorumFromMeta(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+func testStorageClassConfigJSON(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
 	// Reset global storage class flags
 	resetGlobalStorageEnvs()
-	bucket := getRandomBucketName()
-
-	// make data with more than one part
-	partCount := 3
-	data := bytes.Repeat([]byte("a"), int(globalPutPartSize)*partCount)
-	xl := obj.(*xlObjects)
-	xlDisks := xl.storageDisks
+	// Set globalEndpoints for a single node XL setup.
+	globalEndpoints = mustGetNewEndpointList(dirs...)
 
-	err := obj.MakeBucketWithLocation(bucket, globalMinioDefaultRegion)
-	if err != nil {
-		t.Fatalf("Failed to make a bucket %v", err)
+	tests := []struct {
+		name          int
+		data          string
+		expectedCfg   storageClassConfig
+		expectedError error
+	}{
+		{1, `{"standard":"","rrs":""}`, storageClassConfig{}, nil},
+		{2, `{"standard":"EC:6","rrs":"EC:3"}`, storageClassConfig{
+			Standard: storageClass{Scheme: "EC", Parity: 6},
+			RRS:      storageClass{Scheme: "EC", Parity: 3},
+		}, nil},
+		{3, `{"standard":"EC:4","rrs":""}`, storageClassConfig{
+			Standard: storageClass{Scheme: "EC", Parity: 4},
+		}, nil},
+		{4, `{"standard":"EC:3","rrs":"EC:4"}`, storageClassConfig{},
+			errors.New("Invalid storage class config: REDUCED_REDUNDANCY: Reduced redundancy storage class parity disks should be less than 3; STANDARD: Standard storage class parity disks should be greater than 4")},
+		{5, `{"standard":"EC:9","rrs":""}`, storageClassConfig{},
+			errors.New("Invalid storage class config: STANDARD: Standard storage class parity disks should be less than or equal to 8")},
+		{6, `{"standard":"EC:A","rrs":""}`, storageClassConfig{},
+			errors.New(`strconv.Atoi: parsing "A": invalid syntax`)},
 	}
+	for _, tt := range tests {
+		var cfg storageClassConfig
+		err := json.Unmarshal([]byte(tt.data), &cfg)
+		if err != nil && tt.expectedError == nil {
+			t.Errorf("Test %d, Expected %s, got %s", tt.name, tt.expectedError, err)
+			continue
+		}
+		if err == nil && tt.expectedError != nil {
+			t.Errorf("Test %d, Expected %s, got %s", tt.name, tt.expectedError, err)
+			continue
+		}
+		if tt.expectedError != nil {
+			if err.Error() != tt.expectedError.Error() {
+				t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedError, err)
+			}
+			continue
+		}
+		if cfg != tt.expectedCfg {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedCfg, cfg)
+		}
 
-	// Object for test case 1 - No StorageClass defined, no MetaData in PutObject
-	object1 := "object1"
-	_, err = obj.PutObject(bucket, object1, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), nil)
-	if err != nil {
-		t.Fatalf("Failed to putObject %v", err)
+		// Marshalled config should read back the same.
+		data, err := json.Marshal(cfg)
+		if err != nil {
+			t.Fatalf("Test %d, Unexpected error %v", tt.name, err)
+		}
+		if string(data) != tt.data {
+			t.Errorf("Test %d, Expected %s, got %s", tt.name, tt.data, string(data))
+		}
 	}
+}
 
-	parts1, errs1 := readAllXLMetadata(xlDisks, bucket, object1)
-
-	// Object for test case 2 - No StorageClass defined, MetaData in PutObject requesting RRS Class
-	object2 := "object2"
-	metadata2 := make(map[string]string)
-	metadata2["x-amz-storage-class"] = reducedRedundancyStorageClass
-	_, err = obj.PutObject(bucket, object2, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), metadata2)
-	if err != nil {
-		t.Fatalf("Failed to putObject %v", err)
+func TestValidateStorageClassForDisks(t *testing.T) {
+	tests := []struct {
+		name          int
+		standard      storageClass
+		rrs           storageClass
+		disks         int
+		expectedError error
+	}{
+		{1, storageClass{}, storageClass{}, 2, nil},
+		{2, storageClass{Scheme: "EC", Parity: 2}, storageClass{}, 2, errors.New("Setting storage class only allowed for erasure coding mode")},
+		{3, storageClass{}, storageClass{Scheme: "EC", Parity: 2}, 4, errors.New("Reduced redundancy storage class not supported for 4 disk setup")},
+		{4, storageClass{Scheme: "EC", Parity: 2}, storageClass{}, 4, nil},
+		{5, storageClass{Scheme: "EC", Parity: 6}, storageClass{Scheme: "EC", Parity: 2}, 12, nil},
+		{6, storageClass{Scheme: "EC", Parity: 6}, storageClass{Scheme: "EC", Parity: 2}, 10, errors.New("Standard storage class parity disks should be less than or equal to 5")},
+		{7, storageClass{Scheme: "EC", Parity: 4}, storageClass{Scheme: "EC", Parity: 4}, 16, errors.New("Reduced redundancy storage class parity disks should be less than 4")},
+		{8, storageClass{}, storageClass{Scheme: "EC", Parity: 3}, 6, errors.New("Reduced redundancy storage class parity disks should be less than 3")},
+		{9, storageClass{Scheme: "EC", Parity: 8}, storageClass{Scheme: "EC", Parity: 2}, 16, nil},
 	}
-
-	parts2, errs2 := readAllXLMetadata(xlDisks, bucket, object2)
-
-	// Object for test case 3 - No StorageClass defined, MetaData in PutObject requesting Standard Storage Class
-	object3 := "object3"
-	metadata3 := make(map[string]string)
-	metadata3["x-amz-storage-class"] = standardStorageClass
-	_, err = obj.PutObject(bucket, object3, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), metadata3)
-	if err != nil {
-		t.Fatalf("Failed to putObject %v", err)
+	for _, tt := range tests {
+		err := ValidateStorageClassForDisks(tt.standard, tt.rrs, tt.disks)
+		if !reflect.DeepEqual(err, tt.expectedError) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedError, err)
+		}
 	}
+}
 
-	parts3, errs3 := readAllXLMetadata(xlDisks, bucket, object3)
+func TestStorageClassAllowSmall(t *testing.T) {
+	globalStorageClassAllowSmall = true
+	defer resetGlobalStorageEnvs()
 
-	// Object for test case 4 - Standard StorageClass defined as Parity 6, MetaData in PutObject requesting Standard Storage Class
-	object4 := "object4"
-	metadata4 := make(map[string]string)
-	metadata4["x-amz-storage-class"] = standardStorageClass
-	globalStandardStorageClass = storageClass{
-		Parity: 6,
-		Scheme: "EC",
+	tests := []struct {
+		name          int
+		standard      storageClass
+		rrs           storageClass
+		disks         int
+		expectedError error
+	}{
+		{1, storageClass{}, storageClass{Scheme: "EC", Parity: 2}, 4, nil},
+		{2, storageClass{Scheme: "EC", Parity: 2}, storageClass{Scheme: "EC", Parity: 2}, 4, nil},
+		// minimumParityDisks floor is still enforced.
+		{3, storageClass{}, storageClass{Scheme: "EC", Parity: 1}, 4, errors.New("Reduced redundancy storage class parity should be greater than or equal to 2")},
+		{4, storageClass{}, storageClass{Scheme: "EC", Parity: 3}, 4, errors.New("Reduced redundancy storage class parity disks should be less than or equal to 2")},
+		// Setups with more than 4 disks are not affected.
+		{5, storageClass{Scheme: "EC", Parity: 3}, storageClass{Scheme: "EC", Parity: 3}, 8, errors.New("Reduced redundancy storage class parity disks should be less than 3")},
+	}
+	for _, tt := range tests {
+		err := ValidateStorageClassForDisks(tt.standard, tt.rrs, tt.disks)
+		if !reflect.DeepEqual(err, tt.expectedError) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedError, err)
+		}
 	}
 
-	_, err = obj.PutObject(bucket, object4, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), metadata4)
-	if err != nil {
-		t.Fatalf("Failed to putObject %v", err)
+	// Without the opt-in RRS is refused on 4 disks setup.
+	globalStorageClassAllowSmall = false
+	err := ValidateStorageClassForDisks(storageClass{}, storageClass{Scheme: "EC", Parity: 2}, 4)
+	if expected := errors.New("Reduced redundancy storage class not supported for 4 disk setup"); !reflect.DeepEqual(err, expected) {
+		t.Errorf("Expected %v, got %v", expected, err)
 	}
+}
 
-	parts4, errs4 := readAllXLMetadata(xlDisks, bucket, object4)
+func TestValidateStorageClassConfig(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testValidateStorageClassConfig)
+}
 
-	// Object for test case 5 - RRS StorageClass defined as Parity 2, MetaData in PutObject requesting RRS Class
+func testValidateStorageClassConfig(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
 	// Reset global storage class flags
 	resetGlobalStorageEnvs()
-	object5 := "object5"
-	metadata5 := make(map[string]string)
-	metadata5["x-amz-storage-class"] = reducedRedundancyStorageClass
-	globalRRStorageClass = storageClass{
-		Parity: 2,
-		Scheme: "EC",
+	// Set globalEndpoints for a single node XL setup.
+	globalEndpoints = mustGetNewEndpointList(dirs...)
+
+	tests := []struct {
+		name          int
+		ssParity      int
+		rrsParity     int
+		expectedError error
+	}{
+		{1, 4, 2, nil},
+		{2, 0, 2, nil},
+		{3, 6, 0, nil},
+		{4, 1, 0, errors.New("STANDARD (MINIO_STORAGE_CLASS_STANDARD): Standard storage class parity disks should be greater than or equal to 2")},
+		{5, 9, 10, errors.New("REDUCED_REDUNDANCY (MINIO_STORAGE_CLASS_RRS): Reduced redundancy storage class parity disks should be less than 9; " +
+			"STANDARD (MINIO_STORAGE_CLASS_STANDARD): Standard storage class parity disks should be greater than 10; " +
+			"STANDARD (MINIO_STORAGE_CLASS_STANDARD): Standard storage class parity disks should be less than or equal to 8")},
+		{6, 0, 1, errors.New("REDUCED_REDUNDANCY (MINIO_STORAGE_CLASS_RRS): Reduced redundancy storage class parity should be greater than or equal to 2")},
+	}
+	for _, tt := range tests {
+		err := validateStorageClassConfig(tt.ssParity, tt.rrsParity)
+		if !reflect.DeepEqual(err, tt.expectedError) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedError, err)
+		}
 	}
 
-	_, err = obj.PutObject(bucket, object5, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), metadata5)
-	if err != nil {
-		t.Fatalf("Failed to putObject %v", err)
+	// Not an erasure coded setup, both storage classes are reported.
+	globalEndpoints = mustGetNewEndpointList(dirs[0])
+	expectedErr := errors.New("REDUCED_REDUNDANCY (MINIO_STORAGE_CLASS_RRS): Setting storage class only allowed for erasure coding mode; " +
+		"STANDARD (MINIO_STORAGE_CLASS_STANDARD): Setting storage class only allowed for erasure coding mode")
+	if err := validateStorageClassConfig(4, 2); !reflect.DeepEqual(err, expectedErr) {
+		t.Errorf("Expected %v, got %v", expectedErr, err)
 	}
+}
 
-	parts5, errs5 := readAllXLMetadata(xlDisks, bucket, object5)
+func TestScratchStorageClass(t *testing.T) {
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
 
-	// Object for test case 6 - RRS StorageClass defined as Parity 2, MetaData in PutObject requesting Standard Storage Class
-	// Reset global storage class flags
+	// Scratch parity is not affected by configured storage classes.
+	globalStandardStorageClass = storageClass{Scheme: "EC", Parity: 6}
+	globalRRStorageClass = storageClass{Scheme: "EC", Parity: 3}
+
+	tests := []struct {
+		name           int
+		totalDisks     int
+		expectedData   int
+		expectedParity int
+	}{
+		{1, 4, 2, 2},
+		{2, 8, 6, 2},
+		{3, 16, 14, 2},
+	}
+	for _, tt := range tests {
+		info := getRedundancyCount(scratchStorageClass, tt.totalDisks)
+		if info.Data != tt.expectedData || info.Parity != tt.expectedParity {
+			t.Errorf("Test %d, Expected %d data and %d parity disks, got %d and %d",
+				tt.name, tt.expectedData, tt.expectedParity, info.Data, info.Parity)
+		}
+		if info.Class != scratchStorageClass || info.UsedDefault {
+			t.Errorf("Test %d, Expected configured %s, got %s with default %t",
+				tt.name, scratchStorageClass, info.Class, info.UsedDefault)
+		}
+	}
+
+	// Rejected on a non erasure coded setup, including via an alias.
+	globalStorageClassAliases = map[string]string{"TEMP": scratchStorageClass}
+	globalEndpoints = mustGetNewEndpointList("/d1")
+	defer resetGlobalEndpoints()
+	for _, sc := range []string{scratchStorageClass, "TEMP"} {
+		if isValidStorageClassMeta(sc) {
+			t.Errorf("Expected %s to be an invalid storage class on a single disk", sc)
+		}
+	}
+
+	globalEndpoints = mustGetNewEndpointList("/d1", "/d2", "/d3", "/d4")
+	for _, sc := range []string{scratchStorageClass, "TEMP"} {
+		if !isValidStorageClassMeta(sc) {
+			t.Errorf("Expected %s to be a valid storage class on 4 disks", sc)
+		}
+	}
+}
+
+// Test the parity rules for different number of disks without globalEndpoints.
+func TestCheckParityForDisks(t *testing.T) {
 	resetGlobalStorageEnvs()
-	object6 := "object6"
-	metadata6 := make(map[string]string)
-	metadata6["x-amz-storage-class"] = standardStorageClass
-	globalRRStorageClass = storageClass{
-		Parity: 2,
-		Scheme: "EC",
+	tests := []struct {
+		name         int
+		ssParity     int
+		rrsParity    int
+		maxParity    int
+		disks        int
+		expectedRRS  []error
+		expectedSS   []error
+		expectedMax  []error
+		expectedConf error
+	}{
+		// Not an erasure coded setup.
+		{1, 2, 2, 2, 2,
+			[]error{errors.New("Setting storage class only allowed for erasure coding mode")},
+			[]error{errors.New("Setting storage class only allowed for erasure coding mode")},
+			[]error{errors.New("Setting storage class only allowed for erasure coding mode")},
+			errors.New("REDUCED_REDUNDANCY (MINIO_STORAGE_CLASS_RRS): Setting storage class only allowed for erasure coding mode; " +
+				"STANDARD (MINIO_STORAGE_CLASS_STANDARD): Setting storage class only allowed for erasure coding mode")},
+		// 4 disks has no room for reduced redundancy or max durability.
+		{2, 2, 2, 3, 4,
+			[]error{errors.New("Reduced redundancy storage class not supported for 4 disk setup"),
+				errors.New("Reduced redundancy storage class parity disks should be less than 2")},
+			[]error{errors.New("Standard storage class parity disks should be greater than 2")},
+			[]error{errors.New("Max durability storage class parity disks should be less than or equal to 2")},
+			errors.New("REDUCED_REDUNDANCY (MINIO_STORAGE_CLASS_RRS): Reduced redundancy storage class not supported for 4 disk setup; " +
+				"REDUCED_REDUNDANCY (MINIO_STORAGE_CLASS_RRS): Reduced redundancy storage class parity disks should be less than 2; " +
+				"STANDARD (MINIO_STORAGE_CLASS_STANDARD): Standard storage class parity disks should be greater than 2")},
+		{3, 2, 0, 0, 4, nil, nil,
+			[]error{errors.New("Max durability storage class parity disks should be greater than 2")}, nil},
+		{4, 4, 2, 6, 16, nil, nil, nil, nil},
+		{5, 9, 2, 9, 16, nil,
+			[]error{errors.New("Standard storage class parity disks should be less than or equal to 8")},
+			[]error{errors.New("Max durability storage class parity disks should be greater than 9"),
+				errors.New("Max durability storage class parity disks should be less than or equal to 8")},
+			errors.New("STANDARD (MINIO_STORAGE_CLASS_STANDARD): Standard storage class parity disks should be less than or equal to 8")},
+		// Max durability parity defaults to be greater than N/2 if standard parity is not set.
+		{6, 0, 2, 8, 16, nil, nil,
+			[]error{errors.New("Max durability storage class parity disks should be greater than 8")}, nil},
+	}
+	for _, tt := range tests {
+		if errs := checkRRSParity(tt.rrsParity, tt.ssParity, tt.disks); tt.rrsParity != 0 && !reflect.DeepEqual(errs, tt.expectedRRS) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedRRS, errs)
+		}
+		if errs := checkSSParity(tt.ssParity, tt.rrsParity, tt.disks); tt.ssParity != 0 && !reflect.DeepEqual(errs, tt.expectedSS) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedSS, errs)
+		}
+		if errs := checkMaxParity(tt.maxParity, tt.ssParity, tt.disks); tt.maxParity != 0 && !reflect.DeepEqual(errs, tt.expectedMax) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedMax, errs)
+		}
+		if err := checkStorageClassConfig(tt.ssParity, tt.rrsParity, tt.disks); !reflect.DeepEqual(err, tt.expectedConf) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedConf, err)
+		}
 	}
+}
 
-	_, err = obj.PutObject(bucket, object6, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), metadata6)
+func TestMinimumDataBlocks(t *testing.T) {
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+	// Allow reduced redundancy on 4 disks, so that RRS parity is not limited by N/2.
+	globalStorageClassAllowSmall = true
+
+	tests := []struct {
+		name        int
+		ssParity    int
+		rrsParity   int
+		disks       int
+		expectedRRS []error
+		expectedSS  []error
+	}{
+		// Data disks equal to minimumDataBlocks.
+		{1, 2, 2, 4, nil, nil},
+		// Data disks below minimumDataBlocks, which is the binding limit on 4 disks.
+		{2, 3, 3, 4,
+			[]error{errors.New("Reduced redundancy storage class parity disks should be less than or equal to 2, to leave at least 2 data disks")},
+			[]error{errors.New("Standard storage class parity disks should be less than or equal to 2, to leave at least 2 data disks")}},
+		// N/2 is the binding limit on 5 disks and more.
+		{3, 3, 2, 5, nil,
+			[]error{errors.New("Standard storage class parity disks should be less than or equal to 2")}},
+		{4, 3, 2, 6, nil, nil},
+		{5, 4, 2, 6, nil,
+			[]error{errors.New("Standard storage class parity disks should be less than or equal to 3")}},
+	}
+	for _, tt := range tests {
+		if errs := checkRRSParity(tt.rrsParity, tt.ssParity, tt.disks); !reflect.DeepEqual(errs, tt.expectedRRS) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedRRS, errs)
+		}
+		if errs := checkSSParity(tt.ssParity, tt.rrsParity, tt.disks); !reflect.DeepEqual(errs, tt.expectedSS) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedSS, errs)
+		}
+	}
+
+	for disks := 4; disks <= 32; disks++ {
+		if expected := disks == 4; isDataBlocksLimitBinding(disks) != expected {
+			t.Errorf("Expected data blocks limit binding %t for %d disks, got %t", expected, disks, !expected)
+		}
+	}
+}
+
+func TestStorageClassErrorJSON(t *testing.T) {
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+
+	ssc := storageClass{Scheme: "EC", Parity: 9}
+	rrsc := storageClass{Scheme: "EC", Parity: 2}
+	maxsc := storageClass{Scheme: "EC", Parity: 8}
+	err := checkStorageClassConfig(ssc.Parity, rrsc.Parity, 16)
+	if err == nil {
+		t.Fatalf("Expected storage class config to be invalid")
+	}
+	jsonBytes, err := storageClassErrorJSON(err, ssc, rrsc, maxsc, 16)
 	if err != nil {
-		t.Fatalf("Failed to putObject %v", err)
+		t.Fatalf("Unexpected error %v", err)
 	}
 
-	parts6, errs6 := readAllXLMetadata(xlDisks, bucket, object6)
+	var result struct {
+		Error      string                  `json:"error"`
+		Violations []storageClassViolation `json:"violations"`
+	}
+	if err = json.Unmarshal(jsonBytes, &result); err != nil {
+		t.Fatalf("Unable to parse %s: %v", jsonBytes, err)
+	}
+	expectedError := "STANDARD (MINIO_STORAGE_CLASS_STANDARD): Standard storage class parity disks should be less than or equal to 8"
+	if result.Error != expectedError {
+		t.Errorf("Expected %s, got %s", expectedError, result.Error)
+	}
+	expected := []storageClassViolation{
+		{standardStorageClass, standardStorageClassEnv, "Standard storage class parity disks should be less than or equal to 8", 9, 3, 8},
+		// Max durability parity is validated against the invalid standard parity.
+		{maxDurabilityStorageClass, maxDurabilityStorageClassEnv, "Max durability storage class parity disks should be greater than 9", 8, 0, 0},
+	}
+	if !reflect.DeepEqual(result.Violations, expected) {
+		t.Errorf("Expected %v, got %v", expected, result.Violations)
+	}
 
-	// Object for test case 7 - Standard StorageClass defined as Parity 5, MetaData in PutObject requesting RRS Class
-	// Reset global storage class flags
+	// Valid storage classes have no violations.
+	if violations := getStorageClassViolations(4, 2, 6, 16); violations != nil {
+		t.Errorf("Expected no violations, got %v", violations)
+	}
+}
+
+func TestRequireEvenParity(t *testing.T) {
 	resetGlobalStorageEnvs()
-	object7 := "object7"
-	metadata7 := make(map[string]string)
-	metadata7["x-amz-storage-class"] = reducedRedundancyStorageClass
-	globalStandardStorageClass = storageClass{
-		Parity: 5,
-		Scheme: "EC",
+	defer resetGlobalStorageEnvs()
+
+	// Odd parity is accepted unless even parity is required.
+	if errs := checkSSParity(5, 0, 16); errs != nil {
+		t.Fatalf("Expected no errors, got %v", errs)
 	}
+	globalStorageClassRequireEvenParity = true
 
-	_, err = obj.PutObject(bucket, object7, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), metadata7)
-	if err != nil {
-		t.Fatalf("Failed to putObject %v", err)
+	tests := []struct {
+		name        int
+		ssParity    int
+		rrsParity   int
+		disks       int
+		expectedRRS []error
+		expectedSS  []error
+	}{
+		{1, 4, 2, 16, nil, nil},
+		{2, 5, 0, 16, nil,
+			[]error{errors.New("Standard storage class parity disks should be even, nearest valid parity is 6")}},
+		{3, 0, 3, 16,
+			[]error{errors.New("Reduced redundancy storage class parity disks should be even, nearest valid parity is 4")}, nil},
+		// Lower parity is suggested if higher parity is not valid.
+		{4, 4, 3, 16,
+			[]error{errors.New("Reduced redundancy storage class parity disks should be even, nearest valid parity is 2")}, nil},
+		{5, 3, 0, 6, nil,
+			[]error{errors.New("Standard storage class parity disks should be even, nearest valid parity is 2")}},
+		// No even parity is valid for Standard storage class on 6 disks with RRS parity 2.
+		{6, 3, 2, 6, nil,
+			[]error{errors.New("Standard storage class parity disks should be even")}},
+	}
+	for _, tt := range tests {
+		if errs := checkRRSParity(tt.rrsParity, tt.ssParity, tt.disks); tt.rrsParity != 0 && !reflect.DeepEqual(errs, tt.expectedRRS) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedRRS, errs)
+		}
+		if errs := checkSSParity(tt.ssParity, tt.rrsParity, tt.disks); tt.ssParity != 0 && !reflect.DeepEqual(errs, tt.expectedSS) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedSS, errs)
+		}
 	}
+}
 
-	parts7, errs7 := readAllXLMetadata(xlDisks, bucket, object7)
+func TestValidateMaxParity(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testValidateMaxParity)
+}
+
+func testValidateMaxParity(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	// Set globalEndpoints for a single node XL setup.
+	globalEndpoints = mustGetNewEndpointList(dirs...)
 
 	tests := []struct {
-		name                int
-		xl                  xlObjects
-		parts               []xlMetaV1
-		errs                []error
-		expectedReadQuorum  int
-		expectedWriteQuorum int
-		expectedError       error
+		name          int
+		maxParity     int
+		ssParity      int
+		expectedError error
 	}{
-		{1, *xl, parts1, errs1, 8, 9, nil},
-		{2, *xl, parts2, errs2, 14, 15, nil},
-		{3, *xl, parts3, errs3, 8, 9, nil},
-		{4, *xl, parts4, errs4, 10, 11, nil},
-		{5, *xl, parts5, errs5, 14, 15, nil},
-		{6, *xl, parts6, errs6, 8, 9, nil},
-		{7, *xl, parts7, errs7, 14, 15, nil},
+		{1, 8, 4, nil},
+		{2, 6, 5, nil},
+		{3, 8, 0, errors.New("Max durability storage class parity disks should be greater than 8")},
+		{4, 4, 4, errors.New("Max durability storage class parity disks should be greater than 4")},
+		{5, 9, 6, errors.New("Max durability storage class parity disks should be less than or equal to 8")},
 	}
 	for _, tt := range tests {
-		actualReadQuorum, actualWriteQuorum, err := objectQuorumFromMeta(tt.xl, tt.parts, tt.errs)
-		if tt.expectedError != nil && err == nil {
+		err := validateMaxParity(tt.maxParity, tt.ssParity)
+		if err != nil && tt.expectedError == nil {
 			t.Errorf("Test %d, Expected %s, got %s", tt.name, tt.expectedError, err)
 			return
 		}
-		if tt.expectedError == nil && err != nil {
+		if err == nil && tt.expectedError != nil {
 			t.Errorf("Test %d, Expected %s, got %s", tt.name, tt.expectedError, err)
 			return
 		}
-		if tt.expectedReadQuorum != actualReadQuorum {
-			t.Errorf("Test %d, Expected Read Quorum %d, got %d", tt.name, tt.expectedReadQuorum, actualReadQuorum)
+		if tt.expectedError != nil && !reflect.DeepEqual(err, tt.expectedError) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedError, err)
+		}
+	}
+}
+
+func TestParseRRSDefaultParity(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testParseRRSDefaultParity)
+}
+
+func testParseRRSDefaultParity(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	// Set globalEndpoints for a single node XL setup.
+	globalEndpoints = mustGetNewEndpointList(dirs...)
+	defer resetGlobalEndpoints()
+	defer resetGlobalStorageEnvs()
+
+	tests := []struct {
+		name           int
+		value          string
+		ssParity       int
+		expectedParity int
+		expectedError  error
+	}{
+		{1, "4", 0, 4, nil},
+		{2, "4", 6, 4, nil},
+		{3, "7", 0, 7, nil},
+		{4, "abc", 0, 0, errors.New("Parity disks should be a number in abc")},
+		{5, "1", 0, 0, errors.New("Reduced redundancy storage class parity should be greater than or equal to 2")},
+		{6, "8", 0, 0, errors.New("Reduced redundancy storage class parity disks should be less than 8")},
+		{7, "6", 6, 0, errors.New("Reduced redundancy storage class parity disks should be less than 6")},
+		{8, "0", 0, 0, errors.New("Reduced redundancy storage class parity should be greater than or equal to 2")},
+	}
+	for _, tt := range tests {
+		parity, err := parseRRSDefaultParity(tt.value, tt.ssParity)
+		if !reflect.DeepEqual(err, tt.expectedError) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedError, err)
+		}
+		if parity != tt.expectedParity {
+			t.Errorf("Test %d, Expected parity %d, got %d", tt.name, tt.expectedParity, parity)
+		}
+	}
+
+	// Reduced redundancy storage class without parity uses the default parity.
+	globalRRSDefaultParity = 4
+	globalRedundancyCache.Invalidate()
+	if info := getRedundancyCount(reducedRedundancyStorageClass, len(dirs)); info.Data != 12 || info.Parity != 4 {
+		t.Errorf("Expected data disks 12 and parity disks 4, got %d and %d", info.Data, info.Parity)
+	}
+	// Explicit Reduced redundancy storage class takes precedence over the default parity.
+	globalRRStorageClass = storageClass{Scheme: supportedStorageClassScheme, Parity: 3}
+	globalRedundancyCache.Invalidate()
+	if info := getRedundancyCount(reducedRedundancyStorageClass, len(dirs)); info.Data != 13 || info.Parity != 3 {
+		t.Errorf("Expected data disks 13 and parity disks 3, got %d and %d", info.Data, info.Parity)
+	}
+}
+
+func TestParseMaxParity(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testParseMaxParity)
+}
+
+func testParseMaxParity(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	// Set globalEndpoints for a single node XL setup.
+	globalEndpoints = mustGetNewEndpointList(dirs...)
+	defer resetGlobalEndpoints()
+	defer resetGlobalStorageEnvs()
+
+	tests := []struct {
+		name           int
+		value          string
+		expectedParity int
+		expectedError  error
+	}{
+		{1, "4", 4, nil},
+		{2, "2", 2, nil},
+		// Cap above N/2 never applies, but is valid.
+		{3, "12", 12, nil},
+		{4, "abc", 0, errors.New("Parity disks should be a number in abc")},
+		{5, "1", 0, errors.New("Maximum parity should be greater than or equal to 2, found 1")},
+		{6, "0", 0, errors.New("Maximum parity should be greater than or equal to 2, found 0")},
+	}
+	for _, tt := range tests {
+		parity, err := parseMaxParity(tt.value)
+		if !reflect.DeepEqual(err, tt.expectedError) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedError, err)
+		}
+		if parity != tt.expectedParity {
+			t.Errorf("Test %d, Expected parity %d, got %d", tt.name, tt.expectedParity, parity)
+		}
+	}
+
+	// Standard storage class without parity uses the capped default parity.
+	globalStorageClassMaxParity = 4
+	globalRedundancyCache.Invalidate()
+	for _, sc := range []string{standardStorageClass, ""} {
+		if info := getRedundancyCount(sc, len(dirs)); info.Data != 12 || info.Parity != 4 {
+			t.Errorf("%q: Expected data disks 12 and parity disks 4, got %d and %d", sc, info.Data, info.Parity)
+		}
+	}
+	// Max durability storage class keeps N/2 parity.
+	if info := getRedundancyCount(maxDurabilityStorageClass, len(dirs)); info.Data != 8 || info.Parity != 8 {
+		t.Errorf("Expected data disks 8 and parity disks 8, got %d and %d", info.Data, info.Parity)
+	}
+	// Explicit Standard storage class is not capped.
+	globalStandardStorageClass = storageClass{Scheme: supportedStorageClassScheme, Parity: 6}
+	globalRedundancyCache.Invalidate()
+	if info := getRedundancyCount(standardStorageClass, len(dirs)); info.Data != 10 || info.Parity != 6 {
+		t.Errorf("Expected data disks 10 and parity disks 6, got %d and %d", info.Data, info.Parity)
+	}
+}
+
+func TestRedundancyCount(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testGetRedundancyCount)
+}
+
+func testGetRedundancyCount(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	xl := obj.(*xlObjects)
+
+	tests := []struct {
+		name                int
+		sc                  string
+		disks               []StorageAPI
+		expectedData        int
+		expectedParity      int
+		expectedClass       string
+		expectedUsedDefault bool
+	}{
+		{1, reducedRedundancyStorageClass, xl.storageDisks, 14, 2, reducedRedundancyStorageClass, true},
+		{2, standardStorageClass, xl.storageDisks, 8, 8, standardStorageClass, true},
+		{3, "", xl.storageDisks, 8, 8, standardStorageClass, true},
+		{4, reducedRedundancyStorageClass, xl.storageDisks, 9, 7, reducedRedundancyStorageClass, false},
+		{5, standardStorageClass, xl.storageDisks, 10, 6, standardStorageClass, false},
+		{6, maxDurabilityStorageClass, xl.storageDisks, 8, 8, maxDurabilityStorageClass, true},
+		{7, maxDurabilityStorageClass, xl.storageDisks, 9, 7, maxDurabilityStorageClass, false},
+		// Objects without storage class use N/2 parity even if Standard storage class parity is set.
+		{8, "", xl.storageDisks, 8, 8, standardStorageClass, true},
+	}
+	for _, tt := range tests {
+		// Set env var for test case 4
+		if tt.name == 4 {
+			globalRRStorageClass.Parity = 7
+		}
+		// Set env var for test case 5
+		if tt.name == 5 {
+			globalStandardStorageClass.Parity = 6
+		}
+		// Set env var for test case 7
+		if tt.name == 7 {
+			globalMaxStorageClass.Parity = 7
+		}
+		globalRedundancyCache.Invalidate()
+		info := getRedundancyCount(tt.sc, len(tt.disks))
+		if info.Data != tt.expectedData {
+			t.Errorf("Test %d, Expected data disks %d, got %d", tt.name, tt.expectedData, info.Data)
 			return
 		}
-		if tt.expectedWriteQuorum != actualWriteQuorum {
-			t.Errorf("Test %d, Expected Write Quorum %d, got %d", tt.name, tt.expectedWriteQuorum, actualWriteQuorum)
+		if info.Parity != tt.expectedParity {
+			t.Errorf("Test %d, Expected parity disks %d, got %d", tt.name, tt.expectedParity, info.Parity)
 			return
 		}
+		if info.Class != tt.expectedClass {
+			t.Errorf("Test %d, Expected storage class %s, got %s", tt.name, tt.expectedClass, info.Class)
+		}
+		if info.UsedDefault != tt.expectedUsedDefault {
+			t.Errorf("Test %d, Expected used default %t, got %t", tt.name, tt.expectedUsedDefault, info.UsedDefault)
+		}
 	}
 }
 
-// Test isValidStorageClassMeta method with valid and invalid inputs
-func TestIsValidStorageClassMeta(t *testing.T) {
+// Tests that storage classes set as data and parity disks are parsed
+// and laid out as is.
+func TestParseStorageClassDataParity(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testParseStorageClassDataParity)
+}
+
+func testParseStorageClassDataParity(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+	// Set globalEndpoints for a single node XL setup.
+	globalEndpoints = mustGetNewEndpointList(dirs...)
+	defer resetGlobalEndpoints()
+
 	tests := []struct {
-		name int
-		sc   string
-		want bool
+		name            int
+		storageClassEnv string
+		wantSc          storageClass
+		expectedMsg     string
 	}{
-		{1, "STANDARD", true},
-		{2, "REDUCED_REDUNDANCY", true},
-		{3, "", false},
-		{4, "INVALID", false},
-		{5, "123", false},
-		{6, "MINIO_STORAGE_CLASS_RRS", false},
-		{7, "MINIO_STORAGE_CLASS_STANDARD", false},
+		{1, "EC:12:4", storageClass{Scheme: "EC", Data: 12, Parity: 4}, ""},
+		{2, "EC:8:8", storageClass{Scheme: "EC", Data: 8, Parity: 8}, ""},
+		// Data and parity disks are checked against the disks by checkStorageClassDisks.
+		{3, "EC:8:4", storageClass{Scheme: "EC", Data: 8, Parity: 4}, ""},
+		{4, "EC:14:4", storageClass{Scheme: "EC", Data: 14, Parity: 4}, ""},
+		{5, "EC:A:4", storageClass{}, `strconv.Atoi: parsing "A": invalid syntax`},
+		{6, "EC:12:B", storageClass{}, `strconv.Atoi: parsing "B": invalid syntax`},
+		{7, "EC:0:16", storageClass{}, "Data disks should be between 1 and 16 in EC:0:16"},
+		{8, "EC:16:0", storageClass{}, "Parity disks should be between 1 and 16 in EC:16:0"},
+		{9, "EC:12:4%", storageClass{}, `strconv.Atoi: parsing "4%": invalid syntax`},
+		{10, "AB:12:4", storageClass{}, "Unsupported scheme AB. Supported scheme is EC"},
 	}
 	for _, tt := range tests {
-		if got := isValidStorageClassMeta(tt.sc); got != tt.want {
-			t.Errorf("Test %d, Expected Storage Class to be %t, got %t", tt.name, tt.want, got)
+		gotSc, err := parseStorageClass(tt.storageClassEnv)
+		if tt.expectedMsg == "" {
+			if err != nil {
+				t.Errorf("Test %d, Expected no error, got %s", tt.name, err)
+			} else if gotSc != tt.wantSc {
+				t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.wantSc, gotSc)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.expectedMsg {
+			t.Errorf("Test %d, Expected %s, got %v", tt.name, tt.expectedMsg, err)
 		}
 	}
+
+	// Data and parity disks survive a round-trip through config.json.
+	sc, err := parseStorageClass("EC:12:4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.String() != "EC:12:4" {
+		t.Errorf("Expected %s, got %s", "EC:12:4", sc)
+	}
+	var gotSc storageClass
+	if err = gotSc.UnmarshalText([]byte(sc.String())); err != nil {
+		t.Fatal(err)
+	}
+	if gotSc != sc {
+		t.Errorf("Expected %v, got %v", sc, gotSc)
+	}
+
+	// Explicit data disks are honored, and derived from parity if they don't
+	// lay out on the disks.
+	globalStandardStorageClass = sc
+	globalRedundancyCache.Invalidate()
+	if info := getRedundancyCount(standardStorageClass, 16); info.Data != 12 || info.Parity != 4 {
+		t.Errorf("Expected 12 data and 4 parity disks, got %d data and %d parity disks", info.Data, info.Parity)
+	}
+	if info := GetRedundancyCount(standardStorageClass, 8, sc, storageClass{}); info.Data != 4 || info.Parity != 4 {
+		t.Errorf("Expected 4 data and 4 parity disks, got %d data and %d parity disks", info.Data, info.Parity)
+	}
+}
+
+// Test checkStorageClassDisks.
+func TestCheckStorageClassDisks(t *testing.T) {
+	tests := []struct {
+		name        int
+		sc          storageClass
+		disks       int
+		expectedMsg string
+	}{
+		{1, storageClass{Scheme: "EC", Data: 12, Parity: 4}, 16, ""},
+		{2, storageClass{Scheme: "EC", Data: 8, Parity: 4}, 16, "Data and parity disks should add up to 16 disks, found 12 in EC:8:4"},
+		{3, storageClass{Scheme: "EC", Data: 14, Parity: 4}, 16, "Data and parity disks should add up to 16 disks, found 18 in EC:14:4"},
+		{4, storageClass{Scheme: "EC", Data: 8, Parity: 4}, 12, ""},
+		// Other formats don't set data disks.
+		{5, storageClass{Scheme: "EC", Parity: 4}, 16, ""},
+		{6, storageClass{}, 16, ""},
+	}
+	for _, tt := range tests {
+		err := checkStorageClassDisks(tt.sc, tt.disks)
+		if tt.expectedMsg == "" {
+			if err != nil {
+				t.Errorf("Test %d, Expected no error, got %s", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.expectedMsg {
+			t.Errorf("Test %d, Expected %s, got %v", tt.name, tt.expectedMsg, err)
+		}
+	}
+}
+
+// Tests that EC:0 is kept as an explicit default parity through
+// MarshalText and config.json, and resolves to the default parity.
+func TestStorageClassExplicitDefault(t *testing.T) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+	globalRRSDefaultParity = 3
+
+	sc, err := parseStorageClass("EC:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := sc.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(text) != "EC:0" {
+		t.Errorf("Expected %s, got %s", "EC:0", text)
+	}
+
+	sCfg := storageClassConfig{Standard: sc, RRS: sc}
+	data, err := json.Marshal(&sCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"standard":"EC:0","rrs":"EC:0"}` {
+		t.Errorf("Expected %s, got %s", `{"standard":"EC:0","rrs":"EC:0"}`, data)
+	}
+	var gotCfg storageClassConfig
+	if err = json.Unmarshal(data, &gotCfg); err != nil {
+		t.Fatal(err)
+	}
+	if gotCfg != sCfg {
+		t.Errorf("Expected %v, got %v", sCfg, gotCfg)
+	}
+
+	tests := []struct {
+		name           int
+		sc             string
+		expectedParity int
+	}{
+		// N/2 for Standard storage class.
+		{1, standardStorageClass, 8},
+		// Default parity for Reduced redundancy storage class.
+		{2, reducedRedundancyStorageClass, 3},
+	}
+	for _, tt := range tests {
+		info := GetRedundancyCount(tt.sc, 16, gotCfg.Standard, gotCfg.RRS)
+		if info.Parity != tt.expectedParity || !info.UsedDefault {
+			t.Errorf("Test %d, Expected default parity %d, got %d", tt.name, tt.expectedParity, info.Parity)
+		}
+	}
+
+	if err = ValidateStorageClassForDisks(gotCfg.Standard, gotCfg.RRS, 16); err != nil {
+		t.Errorf("Expected EC:0 to be valid, got %v", err)
+	}
+}
+
+// Test GetRedundancyCount with storage class configs passed explicitly
+// across different cluster sizes.
+func TestGetRedundancyCountWithConfig(t *testing.T) {
+	tests := []struct {
+		name           int
+		sc             string
+		totalDisks     int
+		standard       storageClass
+		rrs            storageClass
+		expectedData   int
+		expectedParity int
+	}{
+		{1, standardStorageClass, 16, storageClass{}, storageClass{}, 8, 8},
+		{2, reducedRedundancyStorageClass, 16, storageClass{}, storageClass{}, 14, 2},
+		{3, "", 16, storageClass{Scheme: "EC", Parity: 4}, storageClass{}, 8, 8},
+		{4, standardStorageClass, 12, storageClass{Scheme: "EC", Parity: 4}, storageClass{Scheme: "EC", Parity: 3}, 8, 4},
+		{5, reducedRedundancyStorageClass, 12, storageClass{Scheme: "EC", Parity: 4}, storageClass{Scheme: "EC", Parity: 3}, 9, 3},
+		{6, standardStorageClass, 4, storageClass{}, storageClass{}, 2, 2},
+		{7, standardStorageClass, 7, storageClass{}, storageClass{}, 4, 3},
+		{8, maxDurabilityStorageClass, 10, storageClass{Scheme: "EC", Parity: 3}, storageClass{}, 5, 5},
+		// EC:0 resolves to the default parity.
+		{9, standardStorageClass, 16, storageClass{Scheme: "EC"}, storageClass{Scheme: "EC"}, 8, 8},
+		{10, reducedRedundancyStorageClass, 16, storageClass{Scheme: "EC"}, storageClass{Scheme: "EC"}, 14, 2},
+	}
+	for _, tt := range tests {
+		info := GetRedundancyCount(tt.sc, tt.totalDisks, tt.standard, tt.rrs)
+		if info.Data != tt.expectedData {
+			t.Errorf("Test %d, Expected data disks %d, got %d", tt.name, tt.expectedData, info.Data)
+		}
+		if info.Parity != tt.expectedParity {
+			t.Errorf("Test %d, Expected parity disks %d, got %d", tt.name, tt.expectedParity, info.Parity)
+		}
+	}
+}
+
+// Tests that parity set as a percentage or relative to Standard parity is
+// resolved for the disks an object is laid out on, e.g. its erasure set.
+func TestGetRedundancyCountForSetDisks(t *testing.T) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+
+	percent25, err := parseStorageClass("EC:25%")
+	if err != nil {
+		t.Fatal(err)
+	}
+	relative, err := parseStorageClass("EC:x0.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           int
+		sc             string
+		setDisks       int
+		standard       storageClass
+		rrs            storageClass
+		expectedParity int
+	}{
+		{1, standardStorageClass, 16, percent25, storageClass{}, 4},
+		{2, standardStorageClass, 8, percent25, storageClass{}, 2},
+		{3, standardStorageClass, 32, percent25, storageClass{}, 8},
+		// Relative to the Standard parity of the set.
+		{4, reducedRedundancyStorageClass, 32, percent25, relative, 4},
+		{5, reducedRedundancyStorageClass, 12, storageClass{}, relative, 3},
+		// Absolute parity is the same on every set.
+		{6, standardStorageClass, 32, storageClass{Scheme: "EC", Parity: 6}, storageClass{}, 6},
+	}
+	for _, tt := range tests {
+		info := GetRedundancyCount(tt.sc, tt.setDisks, tt.standard, tt.rrs)
+		if info.Parity != tt.expectedParity || info.Data != tt.setDisks-tt.expectedParity {
+			t.Errorf("Test %d, Expected %d data %d parity, got %d data %d parity", tt.name,
+				tt.setDisks-tt.expectedParity, tt.expectedParity, info.Data, info.Parity)
+		}
+	}
+}
+
+// Tests that storage classes are validated for the disks of every erasure set.
+func TestValidateStorageClassForSets(t *testing.T) {
+	percent25, err := parseStorageClass("EC:25%")
+	if err != nil {
+		t.Fatal(err)
+	}
+	relative, err := parseStorageClass("EC:x0.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ec4 := storageClass{Scheme: supportedStorageClassScheme, Parity: 4}
+	ec6 := storageClass{Scheme: supportedStorageClassScheme, Parity: 6}
+
+	tests := []struct {
+		name           int
+		standard       storageClass
+		rrs            storageClass
+		maxsc          storageClass
+		setDriveCounts []int
+		expectedErr    string
+	}{
+		// Percentage and relative parity scale with the disks of each set.
+		{1, percent25, storageClass{}, storageClass{}, []int{16, 8}, ""},
+		{2, ec4, relative, storageClass{}, []int{16, 16}, ""},
+		// Absolute parity has to be valid on every set.
+		{3, ec6, storageClass{}, storageClass{}, []int{16}, ""},
+		{4, ec6, storageClass{}, storageClass{}, []int{16, 8}, "Erasure set 2 of 8 disks"},
+		{5, ec4, storageClass{}, ec6, []int{16, 8}, "Erasure set 2 of 8 disks"},
+		{6, ec4, relative, storageClass{}, []int{6, 16}, "Erasure set 1 of 6 disks"},
+		// Sets which are not erasure coded are skipped.
+		{7, ec6, storageClass{}, storageClass{}, []int{16, 2}, ""},
+	}
+	for _, tt := range tests {
+		err := validateStorageClassForSets(tt.standard, tt.rrs, tt.maxsc, tt.setDriveCounts)
+		switch {
+		case tt.expectedErr == "" && err != nil:
+			t.Errorf("Test %d, Expected no error, got %v", tt.name, err)
+		case tt.expectedErr != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.expectedErr)):
+			t.Errorf("Test %d, Expected error starting with %q, got %v", tt.name, tt.expectedErr, err)
+		}
+	}
+}
+
+func TestStorageClassSchemes(t *testing.T) {
+	// Register a replication like scheme which keeps a single data disk.
+	storageClassSchemes["REP"] = storageClassScheme{
+		validate: func(sc storageClass) error {
+			if sc.Percent != 0 {
+				return errors.New("Percentage is not supported by REP scheme")
+			}
+			return nil
+		},
+		redundancy: func(sc storageClass, totalDisks int) (data, parity int) {
+			return 1, totalDisks - 1
+		},
+	}
+	defer delete(storageClassSchemes, "REP")
+
+	tests := []struct {
+		name           int
+		storageClass   string
+		expectedErr    error
+		expectedData   int
+		expectedParity int
+	}{
+		{1, "EC:4", nil, 12, 4},
+		{2, "REP:1", nil, 1, 15},
+		{3, "REP:25%", errStorageClassInvalidParity, 0, 0},
+		{4, "XX:2", errStorageClassUnsupportedScheme, 0, 0},
+	}
+	for _, tt := range tests {
+		sc, err := parseStorageClass(tt.storageClass)
+		if tt.expectedErr != nil {
+			if scErr, ok := err.(storageClassError); !ok || scErr.Err != tt.expectedErr {
+				t.Errorf("Test %d, Expected %v, got %#v", tt.name, tt.expectedErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Test %d, Unexpected error %v", tt.name, err)
+		}
+		info := GetRedundancyCount(standardStorageClass, 16, sc, storageClass{})
+		if info.Data != tt.expectedData {
+			t.Errorf("Test %d, Expected data disks %d, got %d", tt.name, tt.expectedData, info.Data)
+		}
+		if info.Parity != tt.expectedParity {
+			t.Errorf("Test %d, Expected parity disks %d, got %d", tt.name, tt.expectedParity, info.Parity)
+		}
+	}
+
+	if _, err := parseStorageClass("XX:2"); err == nil || err.Error() != "Unsupported scheme XX. Supported scheme is EC, REP" {
+		t.Errorf("Expected unsupported scheme error listing EC, REP, got %v", err)
+	}
+}
+
+func TestNewStorageClassConfig(t *testing.T) {
+	tests := []struct {
+		name        int
+		totalDisks  int
+		expectedCfg storageClassConfig
+	}{
+		{1, 4, storageClassConfig{Standard: storageClass{Scheme: "EC", Parity: 2}, RRS: storageClass{Scheme: "EC", Parity: 2}}},
+		{2, 7, storageClassConfig{Standard: storageClass{Scheme: "EC", Parity: 3}, RRS: storageClass{Scheme: "EC", Parity: 2}}},
+		{3, 16, storageClassConfig{Standard: storageClass{Scheme: "EC", Parity: 8}, RRS: storageClass{Scheme: "EC", Parity: 2}}},
+	}
+	for _, tt := range tests {
+		cfg := newStorageClassConfig(tt.totalDisks)
+		if cfg != tt.expectedCfg {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedCfg, cfg)
+		}
+	}
+
+	// Default config should serialize in its "Scheme:Parity" form.
+	data, err := json.Marshal(newStorageClassConfig(16))
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if string(data) != `{"standard":"EC:8","rrs":"EC:2"}` {
+		t.Errorf("Expected %s, got %s", `{"standard":"EC:8","rrs":"EC:2"}`, string(data))
+	}
+}
+
+func TestCopyObjectStorageClass(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testCopyObjectStorageClass)
+}
+
+func testCopyObjectStorageClass(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	bucket := getRandomBucketName()
+	xl := obj.(*xlObjects)
+
+	err := obj.MakeBucketWithLocation(bucket, globalMinioDefaultRegion)
+	if err != nil {
+		t.Fatalf("Failed to make a bucket %v", err)
+	}
+
+	data := bytes.Repeat([]byte("a"), 1024)
+	srcObject := "src-object"
+	metadata := map[string]string{amzStorageClass: reducedRedundancyStorageClass}
+	_, err = obj.PutObject(bucket, srcObject, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), metadata)
+	if err != nil {
+		t.Fatalf("Failed to putObject %v", err)
+	}
+
+	srcInfo, err := obj.GetObjectInfo(bucket, srcObject)
+	if err != nil {
+		t.Fatalf("Failed to getObjectInfo %v", err)
+	}
+
+	tests := []struct {
+		name           int
+		dstObject      string
+		header         http.Header
+		expectedClass  string
+		expectedData   int
+		expectedParity int
+		// Expected storage classes in the history of the object.
+		expectedHistory []string
+	}{
+		// Source RRS class is retained if no class is specified.
+		{1, "dst-object1", http.Header{}, reducedRedundancyStorageClass, 14, 2, nil},
+		// Storage class in the request overrides source class.
+		{2, "dst-object2", http.Header{amzStorageClassCanonical: []string{standardStorageClass}}, standardStorageClass, 8, 8,
+			[]string{reducedRedundancyStorageClass}},
+		// Replaced metadata without a class is written with the default parity.
+		{3, "dst-object3", http.Header{"X-Amz-Metadata-Directive": []string{"REPLACE"}}, "", 8, 8,
+			[]string{reducedRedundancyStorageClass}},
+		// Changing storage class of the same object rewrites it.
+		{4, srcObject, http.Header{amzStorageClassCanonical: []string{standardStorageClass}}, standardStorageClass, 8, 8,
+			[]string{reducedRedundancyStorageClass}},
+		// Changing storage class of the same object with replaced metadata rewrites it.
+		{5, srcObject, http.Header{
+			"X-Amz-Metadata-Directive": []string{"REPLACE"},
+			amzStorageClassCanonical:   []string{reducedRedundancyStorageClass},
+		}, reducedRedundancyStorageClass, 14, 2,
+			[]string{reducedRedundancyStorageClass, standardStorageClass}},
+	}
+	for _, tt := range tests {
+		meta, err := getCpObjMetadataFromHeader(tt.header, srcInfo.UserDefined)
+		if err != nil {
+			t.Fatalf("Test %d, Unexpected error %v", tt.name, err)
+		}
+		if _, err = obj.CopyObject(bucket, srcObject, bucket, tt.dstObject, meta); err != nil {
+			t.Fatalf("Test %d, Failed to copyObject %v", tt.name, err)
+		}
+		xlMeta, err := readXLMeta(xl.storageDisks[0], bucket, tt.dstObject)
+		if err != nil {
+			t.Fatalf("Test %d, Failed to read xl.json %v", tt.name, err)
+		}
+		if xlMeta.Meta[amzStorageClass] != tt.expectedClass {
+			t.Errorf("Test %d, Expected storage class %s, got %s", tt.name, tt.expectedClass, xlMeta.Meta[amzStorageClass])
+		}
+		if xlMeta.Erasure.DataBlocks != tt.expectedData {
+			t.Errorf("Test %d, Expected data disks %d, got %d", tt.name, tt.expectedData, xlMeta.Erasure.DataBlocks)
+		}
+		if xlMeta.Erasure.ParityBlocks != tt.expectedParity {
+			t.Errorf("Test %d, Expected parity disks %d, got %d", tt.name, tt.expectedParity, xlMeta.Erasure.ParityBlocks)
+		}
+		var history []string
+		if h := xlMeta.Meta[storageClassHistoryKey]; h != "" {
+			for _, entry := range strings.Split(h, ",") {
+				history = append(history, strings.Split(entry, "=")[0])
+			}
+		}
+		if !reflect.DeepEqual(history, tt.expectedHistory) {
+			t.Errorf("Test %d, Expected storage class history %v, got %v", tt.name, tt.expectedHistory, history)
+		}
+		var buffer bytes.Buffer
+		if err = obj.GetObject(bucket, tt.dstObject, 0, int64(len(data)), &buffer); err != nil {
+			t.Fatalf("Test %d, Failed to getObject %v", tt.name, err)
+		}
+		if !bytes.Equal(buffer.Bytes(), data) {
+			t.Errorf("Test %d, Data mismatch in copied object", tt.name)
+		}
+	}
+
+	// Storage class resolving to the same parity only updates the label.
+	globalStandardStorageClass = storageClass{Scheme: "EC", Parity: 2}
+	globalRedundancyCache.Invalidate()
+	defer resetGlobalStorageEnvs()
+	prevMeta, err := readXLMeta(xl.storageDisks[0], bucket, srcObject)
+	if err != nil {
+		t.Fatalf("Failed to read xl.json %v", err)
+	}
+	meta, err := getCpObjMetadataFromHeader(http.Header{amzStorageClassCanonical: []string{standardStorageClass}}, prevMeta.Meta)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if _, err = obj.CopyObject(bucket, srcObject, bucket, srcObject, meta); err != nil {
+		t.Fatalf("Failed to copyObject %v", err)
+	}
+	xlMeta, err := readXLMeta(xl.storageDisks[0], bucket, srcObject)
+	if err != nil {
+		t.Fatalf("Failed to read xl.json %v", err)
+	}
+	if xlMeta.Meta[amzStorageClass] != standardStorageClass {
+		t.Errorf("Expected storage class %s, got %s", standardStorageClass, xlMeta.Meta[amzStorageClass])
+	}
+	if xlMeta.Erasure.DataBlocks != 14 || xlMeta.Erasure.ParityBlocks != 2 {
+		t.Errorf("Expected 14 data and 2 parity disks, got %d and %d", xlMeta.Erasure.DataBlocks, xlMeta.Erasure.ParityBlocks)
+	}
+	if !xlMeta.Stat.ModTime.Equal(prevMeta.Stat.ModTime) {
+		t.Errorf("Expected object not to be rewritten, modTime changed from %v to %v", prevMeta.Stat.ModTime, xlMeta.Stat.ModTime)
+	}
+
+	// Storage class resolving to the same parity once tuned for the
+	// size of the object only updates the label.
+	meta, err = getCpObjMetadataFromHeader(http.Header{amzStorageClassCanonical: []string{reducedRedundancyStorageClass}}, xlMeta.Meta)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if _, err = obj.CopyObject(bucket, srcObject, bucket, srcObject, meta); err != nil {
+		t.Fatalf("Failed to copyObject %v", err)
+	}
+	resetGlobalStorageEnvs()
+	globalSmallObjectParityThresholds = map[string]int64{standardStorageClass: int64(len(data)) + 1}
+	if prevMeta, err = readXLMeta(xl.storageDisks[0], bucket, srcObject); err != nil {
+		t.Fatalf("Failed to read xl.json %v", err)
+	}
+	meta, err = getCpObjMetadataFromHeader(http.Header{amzStorageClassCanonical: []string{standardStorageClass}}, prevMeta.Meta)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if _, err = obj.CopyObject(bucket, srcObject, bucket, srcObject, meta); err != nil {
+		t.Fatalf("Failed to copyObject %v", err)
+	}
+	if xlMeta, err = readXLMeta(xl.storageDisks[0], bucket, srcObject); err != nil {
+		t.Fatalf("Failed to read xl.json %v", err)
+	}
+	if xlMeta.Meta[amzStorageClass] != standardStorageClass || xlMeta.Meta[sizeTunedParityKey] != "2" {
+		t.Errorf("Expected storage class %s with size tuned parity 2, got %s with %q", standardStorageClass,
+			xlMeta.Meta[amzStorageClass], xlMeta.Meta[sizeTunedParityKey])
+	}
+	if xlMeta.Erasure.ParityBlocks != 2 {
+		t.Errorf("Expected 2 parity disks, got %d", xlMeta.Erasure.ParityBlocks)
+	}
+	if !xlMeta.Stat.ModTime.Equal(prevMeta.Stat.ModTime) {
+		t.Errorf("Expected object not to be rewritten, modTime changed from %v to %v", prevMeta.Stat.ModTime, xlMeta.Stat.ModTime)
+	}
+}
+
+// Log target which keeps the log entries fired.
+type storageClassAuditTarget struct {
+	entries []*logrus.Entry
+}
+
+func (target *storageClassAuditTarget) Fire(entry *logrus.Entry) error {
+	target.entries = append(target.entries, entry)
+	return nil
+}
+
+func (target *storageClassAuditTarget) String() string {
+	return "storageClassAudit"
+}
+
+func TestStorageClassAudit(t *testing.T) {
+	// initialize NSLock, bucket storage class config is read under a namespace lock.
+	initNSLock(false)
+	ExecObjectLayerTestWithDirs(t, testStorageClassAudit)
+}
+
+func testStorageClassAudit(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+
+	// Capture log entries instead of printing them on console,
+	// logging is quiet during tests so the logger hook is added back.
+	target := &storageClassAuditTarget{}
+	prevHooks, prevTargets, prevConsole := log.logger.Hooks, log.targets, log.consoleTarget
+	log.logger.Hooks = make(logrus.LevelHooks)
+	log.logger.Hooks.Add(log)
+	log.AddTarget(target)
+	log.SetConsoleTarget(ConsoleLogger{})
+	defer func() {
+		log.logger.Hooks = prevHooks
+		log.targets = prevTargets
+		log.SetConsoleTarget(prevConsole)
+	}()
+
+	bucket := getRandomBucketName()
+	if err := obj.MakeBucketWithLocation(bucket, globalMinioDefaultRegion); err != nil {
+		t.Fatalf("Failed to make a bucket %v", err)
+	}
+	if err := initBucketStorageClass(obj); err != nil {
+		t.Fatalf("Failed to load bucket storage class %v", err)
+	}
+	globalBucketStorageClass.SetBucketStorageClass(bucket, &bucketStorageClassConfig{
+		Standard: storageClass{Scheme: "EC", Parity: 6},
+	})
+	defer globalBucketStorageClass.SetBucketStorageClass(bucket, nil)
+	globalRRStorageClass = storageClass{Scheme: "EC", Parity: 3}
+
+	data := bytes.Repeat([]byte("a"), 1024)
+	tests := []struct {
+		name           int
+		bucket         string
+		metadata       map[string]string
+		expectedClass  string
+		expectedParity int
+		expectedSource string
+	}{
+		// Object without storage class is written with N/2 default parity.
+		{1, "otherbucket", nil, standardStorageClass, 8, storageClassSourceDefault},
+		{2, "otherbucket", map[string]string{amzStorageClass: reducedRedundancyStorageClass}, reducedRedundancyStorageClass, 3, storageClassSourceConfig},
+		{3, bucket, map[string]string{amzStorageClass: standardStorageClass}, standardStorageClass, 6, storageClassSourceBucket},
+		{4, bucket, map[string]string{amzStorageClass: reducedRedundancyStorageClass}, reducedRedundancyStorageClass, 3, storageClassSourceConfig},
+		{5, bucket, map[string]string{forceParityKey: "4"}, standardStorageClass, 4, storageClassSourceRequest},
+	}
+	for _, tt := range tests {
+		var requested string
+		if tt.metadata != nil {
+			requested = tt.metadata[amzStorageClass]
+		}
+		info, err := getObjectRedundancyInfo(tt.bucket, tt.metadata, len(dirs), -1)
+		if err != nil {
+			t.Fatalf("Test %d, Unexpected error %v", tt.name, err)
+		}
+		if info.Class != tt.expectedClass || info.Parity != tt.expectedParity || info.Source != tt.expectedSource {
+			t.Errorf("Test %d, Expected %s with parity %d from %s, got %s with parity %d from %s", tt.name,
+				tt.expectedClass, tt.expectedParity, tt.expectedSource, info.Class, info.Parity, info.Source)
+		}
+
+		// Audit log entry is only written if enabled.
+		target.entries = nil
+		auditStorageClass(tt.bucket, "object", requested, info)
+		if len(target.entries) != 0 {
+			t.Errorf("Test %d, Expected no audit log entry, got %d", tt.name, len(target.entries))
+		}
+		globalStorageClassAudit = true
+		auditStorageClass(tt.bucket, "object", requested, info)
+		globalStorageClassAudit = false
+		if len(target.entries) != 1 {
+			t.Fatalf("Test %d, Expected an audit log entry, got %d", tt.name, len(target.entries))
+		}
+		expectedFields := logrus.Fields{
+			"audit":          "storageClass",
+			"bucket":         tt.bucket,
+			"object":         "object",
+			"requestedClass": requested,
+			"resolvedClass":  tt.expectedClass,
+			"parity":         tt.expectedParity,
+			"source":         tt.expectedSource,
+		}
+		if !reflect.DeepEqual(target.entries[0].Data, expectedFields) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, expectedFields, target.entries[0].Data)
+		}
+	}
+
+	// Writes are audited with the object name.
+	globalStorageClassAudit = true
+	target.entries = nil
+	if _, err := obj.PutObject(bucket, "audited", mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), nil); err != nil {
+		t.Fatalf("Failed to putObject %v", err)
+	}
+	if _, err := obj.NewMultipartUpload(bucket, "audited-multipart", map[string]string{amzStorageClass: reducedRedundancyStorageClass}); err != nil {
+		t.Fatalf("Failed to create multipart upload %v", err)
+	}
+	var audited []string
+	for _, entry := range target.entries {
+		if entry.Data["audit"] == "storageClass" {
+			audited = append(audited, fmt.Sprintf("%s:%s", entry.Data["object"], entry.Data["source"]))
+		}
+	}
+	expected := []string{"audited:" + storageClassSourceDefault, "audited-multipart:" + storageClassSourceConfig}
+	if !reflect.DeepEqual(audited, expected) {
+		t.Errorf("Expected audited writes %v, got %v", expected, audited)
+	}
+}
+
+func TestCheckParityFeasibility(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testCheckParityFeasibility)
+}
+
+func testCheckParityFeasibility(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	// Set globalEndpoints for a single node XL setup.
+	globalEndpoints = mustGetNewEndpointList(dirs...)
+
+	tests := []struct {
+		name          int
+		sc            storageClass
+		onlineDisks   int
+		expectedError error
+	}{
+		{1, storageClass{Scheme: "EC", Parity: 8}, 16, nil},
+		{2, storageClass{Scheme: "EC", Parity: 8}, 9, nil},
+		{3, storageClass{Scheme: "EC", Parity: 8}, 8, errors.New("Storage class EC:8 needs 9 online disks but only 8 are online")},
+		{4, storageClass{Scheme: "EC", Parity: 2}, 14, errors.New("Storage class EC:2 needs 15 online disks but only 14 are online")},
+		{5, storageClass{}, 0, nil},
+	}
+	for _, tt := range tests {
+		err := checkParityFeasibility(tt.sc, tt.onlineDisks)
+		if err != nil && tt.expectedError == nil {
+			t.Errorf("Test %d, Expected %s, got %s", tt.name, tt.expectedError, err)
+			continue
+		}
+		if err == nil && tt.expectedError != nil {
+			t.Errorf("Test %d, Expected %s, got %s", tt.name, tt.expectedError, err)
+			continue
+		}
+		if tt.expectedError != nil && !reflect.DeepEqual(err, tt.expectedError) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedError, err)
+		}
+	}
+
+	// Default storage classes with 12 disks online, RRS needs 15 and
+	// Standard needs 9 disks.
+	info := getStorageClassInfo()
+	info.checkFeasibility(12)
+	expected := []string{"REDUCED_REDUNDANCY: Storage class EC:2 needs 15 online disks but only 12 are online"}
+	if !reflect.DeepEqual(info.Warnings, expected) {
+		t.Errorf("Expected %v, got %v", expected, info.Warnings)
+	}
+}
+
+func TestRecordStorageClassTransition(t *testing.T) {
+	t1 := time.Date(2017, time.December, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name            int
+		srcMeta         map[string]string
+		dstMeta         map[string]string
+		expectedHistory string
+	}{
+		// No storage class change.
+		{1, map[string]string{}, map[string]string{amzStorageClass: standardStorageClass}, ""},
+		// History of the source object is carried over.
+		{2, map[string]string{storageClassHistoryKey: "STANDARD=2017-11-01T10:00:00Z"}, map[string]string{},
+			"STANDARD=2017-11-01T10:00:00Z"},
+		{3, map[string]string{amzStorageClass: reducedRedundancyStorageClass}, map[string]string{},
+			"REDUCED_REDUNDANCY=2017-12-01T10:00:00Z"},
+		// History is bounded to maxStorageClassHistory entries.
+		{4, map[string]string{
+			amzStorageClass:        maxDurabilityStorageClass,
+			storageClassHistoryKey: "STANDARD=2017-01-01T10:00:00Z,REDUCED_REDUNDANCY=2017-02-01T10:00:00Z,STANDARD=2017-03-01T10:00:00Z,REDUCED_REDUNDANCY=2017-04-01T10:00:00Z,STANDARD=2017-05-01T10:00:00Z",
+		}, map[string]string{amzStorageClass: standardStorageClass},
+			"REDUCED_REDUNDANCY=2017-02-01T10:00:00Z,STANDARD=2017-03-01T10:00:00Z,REDUCED_REDUNDANCY=2017-04-01T10:00:00Z,STANDARD=2017-05-01T10:00:00Z,MAX_DURABILITY=2017-12-01T10:00:00Z"},
+	}
+	for _, tt := range tests {
+		recordStorageClassTransition(tt.srcMeta, tt.dstMeta, t1)
+		if got := tt.dstMeta[storageClassHistoryKey]; got != tt.expectedHistory {
+			t.Errorf("Test %d, Expected %s, got %s", tt.name, tt.expectedHistory, got)
+		}
+	}
+}
+
+func TestParseQuorumPolicy(t *testing.T) {
+	tests := []struct {
+		name           int
+		quorumEnv      string
+		expectedPolicy quorumPolicy
+		expectedError  error
+	}{
+		{1, "0:1", quorumPolicy{ReadOffset: 0, WriteOffset: 1}, nil},
+		{2, "1:3", quorumPolicy{ReadOffset: 1, WriteOffset: 3}, nil},
+		{3, "1", quorumPolicy{}, errors.New("Invalid quorum policy 1. Supported format is READ_OFFSET:WRITE_OFFSET")},
+		{4, "1:2:3", quorumPolicy{}, errors.New("Invalid quorum policy 1:2:3. Supported format is READ_OFFSET:WRITE_OFFSET")},
+	}
+	for _, tt := range tests {
+		q, err := parseQuorumPolicy(tt.quorumEnv)
+		if !reflect.DeepEqual(err, tt.expectedError) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedError, err)
+			continue
+		}
+		if q != tt.expectedPolicy {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedPolicy, q)
+		}
+	}
+}
+
+func TestValidateQuorumPolicy(t *testing.T) {
+	tests := []struct {
+		name          int
+		policy        quorumPolicy
+		parity        int
+		disks         int
+		expectedError error
+	}{
+		{1, defaultQuorumPolicy, 8, 16, nil},
+		// Stricter write quorum of data disks + parity/2.
+		{2, quorumPolicy{ReadOffset: 0, WriteOffset: 4}, 8, 16, nil},
+		{3, quorumPolicy{ReadOffset: 0, WriteOffset: 8}, 8, 16, nil},
+		{4, quorumPolicy{ReadOffset: 0, WriteOffset: 3}, 2, 16, errors.New("Write quorum of 17 disks exceeds the 16 total disks, writes would always fail. " +
+			"Each additional write quorum disk allows one less disk to be offline while writing")},
+		{5, quorumPolicy{ReadOffset: 3, WriteOffset: 1}, 2, 16, errors.New("Read quorum of 17 disks exceeds the 16 total disks, reads would always fail. " +
+			"Each additional read quorum disk allows one less disk to be offline while reading")},
+		{6, quorumPolicy{ReadOffset: 0, WriteOffset: 0}, 8, 16, errors.New("Write quorum offset should be greater than or equal to 1, lower write quorum risks split brain")},
+		{7, quorumPolicy{ReadOffset: -1, WriteOffset: 1}, 8, 16, errors.New("Read quorum offset should be greater than or equal to 0, reading an object needs all its data disks")},
+	}
+	for _, tt := range tests {
+		if err := validateQuorumPolicy(tt.policy, tt.parity, tt.disks); !reflect.DeepEqual(err, tt.expectedError) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedError, err)
+		}
+	}
+}
+
+func TestStorageClassQuorumPolicy(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testStorageClassQuorumPolicy)
+}
+
+func testStorageClassQuorumPolicy(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	// Set globalEndpoints for a single node XL setup.
+	globalEndpoints = mustGetNewEndpointList(dirs...)
+	defer resetGlobalEndpoints()
+	defer resetGlobalStorageEnvs()
+
+	// RRS objects have 14 data disks so a write offset of 3 is not possible.
+	if err := setStorageClassQuorumPolicy(quorumPolicy{ReadOffset: 0, WriteOffset: 3}); err == nil {
+		t.Fatalf("Expected quorum policy to be rejected for %s storage class", reducedRedundancyStorageClass)
+	}
+	if err := setStorageClassQuorumPolicy(quorumPolicy{ReadOffset: 1, WriteOffset: 2}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	xl := obj.(*xlObjects)
+	bucket := getRandomBucketName()
+	if err := obj.MakeBucketWithLocation(bucket, globalMinioDefaultRegion); err != nil {
+		t.Fatalf("Failed to make a bucket %v", err)
+	}
+	data := bytes.Repeat([]byte("a"), 1024)
+	if _, err := obj.PutObject(bucket, "object", mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), nil); err != nil {
+		t.Fatalf("Failed to putObject %v", err)
+	}
+	parts, errs := readAllXLMetadata(xl.storageDisks, bucket, "object")
+	readQuorum, writeQuorum, err := objectQuorumFromMeta(context.Background(), *xl, bucket, "object", parts, errs)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if readQuorum != 9 || writeQuorum != 10 {
+		t.Errorf("Expected read quorum 9 and write quorum 10, got %d and %d", readQuorum, writeQuorum)
+	}
+}
+
+func TestQuorumFromStorageClass(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testQuorumFromStorageClass)
+}
+
+func testQuorumFromStorageClass(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+	bucket := getRandomBucketName()
+	xl := obj.(*xlObjects)
+
+	if err := obj.MakeBucketWithLocation(bucket, globalMinioDefaultRegion); err != nil {
+		t.Fatalf("Failed to make a bucket %v", err)
+	}
+
+	data := bytes.Repeat([]byte("a"), 1024)
+	tests := []struct {
+		name                int
+		standard            storageClass
+		rrs                 storageClass
+		sc                  string
+		expectedReadQuorum  int
+		expectedWriteQuorum int
+	}{
+		{1, storageClass{}, storageClass{}, "", 8, 9},
+		{2, storageClass{}, storageClass{}, standardStorageClass, 8, 9},
+		{3, storageClass{}, storageClass{}, reducedRedundancyStorageClass, 14, 15},
+		{4, storageClass{Scheme: "EC", Parity: 6}, storageClass{}, standardStorageClass, 10, 11},
+		// Quorum policy of the storage class is applied.
+		{5, storageClass{}, storageClass{Scheme: "EC", Parity: 4, Quorum: quorumPolicy{1, 2}}, reducedRedundancyStorageClass, 13, 14},
+	}
+	for _, tt := range tests {
+		globalStandardStorageClass, globalRRStorageClass = tt.standard, tt.rrs
+		globalRedundancyCache.Invalidate()
+
+		readQuorum, writeQuorum := quorumFromStorageClass(tt.sc, len(xl.storageDisks))
+		if readQuorum != tt.expectedReadQuorum || writeQuorum != tt.expectedWriteQuorum {
+			t.Errorf("Test %d, Expected quorum %d:%d, got %d:%d", tt.name,
+				tt.expectedReadQuorum, tt.expectedWriteQuorum, readQuorum, writeQuorum)
+		}
+
+		// Quorum of an object written in the storage class is the same.
+		object := fmt.Sprintf("object%d", tt.name)
+		var metadata map[string]string
+		if tt.sc != "" {
+			metadata = map[string]string{amzStorageClass: tt.sc}
+		}
+		if _, err := obj.PutObject(bucket, object, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), metadata); err != nil {
+			t.Fatalf("Test %d, Failed to putObject %v", tt.name, err)
+		}
+		parts, errs := readAllXLMetadata(xl.storageDisks, bucket, object)
+		metaReadQuorum, metaWriteQuorum, err := objectQuorumFromMeta(context.Background(), *xl, bucket, object, parts, errs)
+		if err != nil {
+			t.Fatalf("Test %d, Unexpected error %v", tt.name, err)
+		}
+		if metaReadQuorum != readQuorum || metaWriteQuorum != writeQuorum {
+			t.Errorf("Test %d, Expected quorum from meta %d:%d, got %d:%d", tt.name,
+				readQuorum, writeQuorum, metaReadQuorum, metaWriteQuorum)
+		}
+	}
+}
+
+func TestObjectQuorumFromMeta(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testObjectQuorumFromMeta)
+}
+
+func testObjectQuorumFromMeta(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	bucket := getRandomBucketName()
+
+	// make data with more than one part
+	partCount := 3
+	data := bytes.Repeat([]byte("a"), int(globalPutPartSize)*partCount)
+	xl := obj.(*xlObjects)
+	xlDisks := xl.storageDisks
+
+	err := obj.MakeBucketWithLocation(bucket, globalMinioDefaultRegion)
+	if err != nil {
+		t.Fatalf("Failed to make a bucket %v", err)
+	}
+
+	// Object for test case 1 - No StorageClass defined, no MetaData in PutObject
+	object1 := "object1"
+	_, err = obj.PutObject(bucket, object1, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), nil)
+	if err != nil {
+		t.Fatalf("Failed to putObject %v", err)
+	}
+
+	parts1, errs1 := readAllXLMetadata(xlDisks, bucket, object1)
+
+	// Object for test case 2 - No StorageClass defined, MetaData in PutObject requesting RRS Class
+	object2 := "object2"
+	metadata2 := make(map[string]string)
+	metadata2["x-amz-storage-class"] = reducedRedundancyStorageClass
+	_, err = obj.PutObject(bucket, object2, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), metadata2)
+	if err != nil {
+		t.Fatalf("Failed to putObject %v", err)
+	}
+
+	parts2, errs2 := readAllXLMetadata(xlDisks, bucket, object2)
+
+	// Object for test case 3 - No StorageClass defined, MetaData in PutObject requesting Standard Storage Class
+	object3 := "object3"
+	metadata3 := make(map[string]string)
+	metadata3["x-amz-storage-class"] = standardStorageClass
+	_, err = obj.PutObject(bucket, object3, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), metadata3)
+	if err != nil {
+		t.Fatalf("Failed to putObject %v", err)
+	}
+
+	parts3, errs3 := readAllXLMetadata(xlDisks, bucket, object3)
+
+	// Object for test case 4 - Standard StorageClass defined as Parity 6, MetaData in PutObject requesting Standard Storage Class
+	object4 := "object4"
+	metadata4 := make(map[string]string)
+	metadata4["x-amz-storage-class"] = standardStorageClass
+	globalStandardStorageClass = storageClass{
+		Parity: 6,
+		Scheme: "EC",
+	}
+	globalRedundancyCache.Invalidate()
+
+	_, err = obj.PutObject(bucket, object4, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), metadata4)
+	if err != nil {
+		t.Fatalf("Failed to putObject %v", err)
+	}
+
+	parts4, errs4 := readAllXLMetadata(xlDisks, bucket, object4)
+
+	// Object for test case 5 - RRS StorageClass defined as Parity 2, MetaData in PutObject requesting RRS Class
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	object5 := "object5"
+	metadata5 := make(map[string]string)
+	metadata5["x-amz-storage-class"] = reducedRedundancyStorageClass
+	globalRRStorageClass = storageClass{
+		Parity: 2,
+		Scheme: "EC",
+	}
+	globalRedundancyCache.Invalidate()
+
+	_, err = obj.PutObject(bucket, object5, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), metadata5)
+	if err != nil {
+		t.Fatalf("Failed to putObject %v", err)
+	}
+
+	parts5, errs5 := readAllXLMetadata(xlDisks, bucket, object5)
+
+	// Object for test case 6 - RRS StorageClass defined as Parity 2, MetaData in PutObject requesting Standard Storage Class
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	object6 := "object6"
+	metadata6 := make(map[string]string)
+	metadata6["x-amz-storage-class"] = standardStorageClass
+	globalRRStorageClass = storageClass{
+		Parity: 2,
+		Scheme: "EC",
+	}
+	globalRedundancyCache.Invalidate()
+
+	_, err = obj.PutObject(bucket, object6, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), metadata6)
+	if err != nil {
+		t.Fatalf("Failed to putObject %v", err)
+	}
+
+	parts6, errs6 := readAllXLMetadata(xlDisks, bucket, object6)
+
+	// Object for test case 7 - Standard StorageClass defined as Parity 5, MetaData in PutObject requesting RRS Class
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	object7 := "object7"
+	metadata7 := make(map[string]string)
+	metadata7["x-amz-storage-class"] = reducedRedundancyStorageClass
+	globalStandardStorageClass = storageClass{
+		Parity: 5,
+		Scheme: "EC",
+	}
+	globalRedundancyCache.Invalidate()
+
+	_, err = obj.PutObject(bucket, object7, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), metadata7)
+	if err != nil {
+		t.Fatalf("Failed to putObject %v", err)
+	}
+
+	parts7, errs7 := readAllXLMetadata(xlDisks, bucket, object7)
+
+	tests := []struct {
+		name                int
+		xl                  xlObjects
+		parts               []xlMetaV1
+		errs                []error
+		expectedReadQuorum  int
+		expectedWriteQuorum int
+		expectedError       error
+	}{
+		{1, *xl, parts1, errs1, 8, 9, nil},
+		{2, *xl, parts2, errs2, 14, 15, nil},
+		{3, *xl, parts3, errs3, 8, 9, nil},
+		{4, *xl, parts4, errs4, 10, 11, nil},
+		{5, *xl, parts5, errs5, 14, 15, nil},
+		{6, *xl, parts6, errs6, 8, 9, nil},
+		{7, *xl, parts7, errs7, 14, 15, nil},
+	}
+	for _, tt := range tests {
+		actualReadQuorum, actualWriteQuorum, err := objectQuorumFromMeta(context.Background(), tt.xl, bucket, "object", tt.parts, tt.errs)
+		if tt.expectedError != nil && err == nil {
+			t.Errorf("Test %d, Expected %s, got %s", tt.name, tt.expectedError, err)
+			return
+		}
+		if tt.expectedError == nil && err != nil {
+			t.Errorf("Test %d, Expected %s, got %s", tt.name, tt.expectedError, err)
+			return
+		}
+		if tt.expectedReadQuorum != actualReadQuorum {
+			t.Errorf("Test %d, Expected Read Quorum %d, got %d", tt.name, tt.expectedReadQuorum, actualReadQuorum)
+			return
+		}
+		if tt.expectedWriteQuorum != actualWriteQuorum {
+			t.Errorf("Test %d, Expected Write Quorum %d, got %d", tt.name, tt.expectedWriteQuorum, actualWriteQuorum)
+			return
+		}
+	}
+	// Degrade object2 (14 data blocks) to 13 valid xl.json(s).
+	degradedParts := make([]xlMetaV1, len(parts2))
+	copy(degradedParts, parts2)
+	degradedErrs := make([]error, len(errs2))
+	copy(degradedErrs, errs2)
+	for _, index := range []int{1, 5, 9} {
+		degradedParts[index] = xlMetaV1{}
+		degradedErrs[index] = errDiskNotFound
+	}
+	qInfo, err := objectQuorumInfoFromMeta(*xl, degradedParts, degradedErrs)
+	if err != errXLReadQuorum {
+		t.Errorf("Expected %s, got %s", errXLReadQuorum, err)
+	}
+	if qInfo.Available != 13 || qInfo.Required != 14 || !reflect.DeepEqual(qInfo.ErrIndices, []int{1, 5, 9}) {
+		t.Errorf("Expected 13 of 14 valid metas with errors on disks [1 5 9], got %s", qInfo)
+	}
+	if expected := "needed 14 valid metas, found 13, disks with errors [1 5 9]"; qInfo.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, qInfo)
+	}
+
+	// Read quorum error is wrapped with the object and the valid metas found.
+	_, _, err = objectQuorumFromMeta(context.Background(), *xl, bucket, object2, degradedParts, degradedErrs)
+	if !isErrXLReadQuorum(err) {
+		t.Fatalf("Expected %s, got %v", errXLReadQuorum, err)
+	}
+	if expected := (xlReadQuorumErr{Bucket: bucket, Object: object2, Available: 13, DataBlocks: 14}); err != expected {
+		t.Errorf("Expected %v, got %v", expected, err)
+	}
+	if !isErrXLReadQuorum(errors2.Trace(err)) {
+		t.Errorf("Expected traced %v to match %s", err, errXLReadQuorum)
+	}
+	for _, field := range []string{bucket + "/" + object2, "found 13 valid metas", "need 14 data blocks"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("Expected %q in %q", field, err)
+		}
+	}
+	if _, ok := toObjectErr(err, bucket, object2).(InsufficientReadQuorum); !ok {
+		t.Errorf("Expected InsufficientReadQuorum, got %v", toObjectErr(err, bucket, object2))
+	}
+
+	// Cancelled context is returned before the metas are scanned.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err = objectQuorumFromMeta(ctx, *xl, bucket, object2, parts2, errs2); err != context.Canceled {
+		t.Errorf("Expected %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestRecommendReparity(t *testing.T) {
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+
+	newMeta := func(sc string, data, parity int) xlMetaV1 {
+		meta := newXLMetaV1("object", data, parity)
+		meta.Meta = map[string]string{amzStorageClass: sc}
+		return meta
+	}
+	tests := []struct {
+		name           int
+		meta           xlMetaV1
+		newDiskCount   int
+		expectedStatus reparityStatus
+		expectedData   int
+		expectedParity int
+	}{
+		// Layout matches the storage class on the new disk count.
+		{1, newMeta(standardStorageClass, 8, 8), 16, reparityNotNeeded, 8, 8},
+		{2, newMeta(reducedRedundancyStorageClass, 6, 2), 8, reparityNotNeeded, 6, 2},
+		// Object written at N/2 on 8 disks, N/2 parity differs on 12 disks.
+		{3, newMeta(standardStorageClass, 4, 4), 12, reparityRecommended, 6, 6},
+		// More blocks than disks, but still readable.
+		{4, newMeta(standardStorageClass, 6, 6), 8, reparityRequired, 4, 4},
+		{5, newMeta(reducedRedundancyStorageClass, 14, 2), 15, reparityRequired, 13, 2},
+		// Fewer disks than data blocks, object can't be read anymore.
+		{6, newMeta(reducedRedundancyStorageClass, 14, 2), 12, reparityReadQuorumLost, 10, 2},
+	}
+	for _, tt := range tests {
+		advice := recommendReparity(tt.meta, tt.newDiskCount)
+		if advice.Status != tt.expectedStatus {
+			t.Errorf("Test %d, Expected %s, got %s", tt.name, tt.expectedStatus, advice.Status)
+		}
+		if advice.Data != tt.expectedData || advice.Parity != tt.expectedParity {
+			t.Errorf("Test %d, Expected %d data and %d parity blocks, got %d and %d",
+				tt.name, tt.expectedData, tt.expectedParity, advice.Data, advice.Parity)
+		}
+		if advice.CurrentData != tt.meta.Erasure.DataBlocks || advice.CurrentParity != tt.meta.Erasure.ParityBlocks {
+			t.Errorf("Test %d, Expected current layout %d+%d, got %d+%d", tt.name, tt.meta.Erasure.DataBlocks,
+				tt.meta.Erasure.ParityBlocks, advice.CurrentData, advice.CurrentParity)
+		}
+	}
+
+	// Objects without storage class keep the default layout, not the Standard layout.
+	globalStandardStorageClass = storageClass{Scheme: supportedStorageClassScheme, Parity: 4}
+	globalRedundancyCache.Invalidate()
+	if advice := recommendReparity(newMeta("", 8, 8), 16); advice.Status != reparityNotNeeded || advice.Parity != 8 {
+		t.Errorf("Expected %s with parity 8, got %s with parity %d", reparityNotNeeded, advice.Status, advice.Parity)
+	}
+	if advice := recommendReparity(newMeta(standardStorageClass, 8, 8), 16); advice.Status != reparityRecommended || advice.Parity != 4 {
+		t.Errorf("Expected %s with parity 4, got %s with parity %d", reparityRecommended, advice.Status, advice.Parity)
+	}
+}
+
+func TestObjectRedundancyFromMeta(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testObjectRedundancyFromMeta)
+}
+
+func testObjectRedundancyFromMeta(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+	xl := obj.(*xlObjects)
+
+	bucket := getRandomBucketName()
+	if err := obj.MakeBucketWithLocation(bucket, globalMinioDefaultRegion); err != nil {
+		t.Fatalf("Failed to make a bucket %v", err)
+	}
+	data := bytes.Repeat([]byte("a"), 1024)
+	metadata := map[string]string{amzStorageClass: reducedRedundancyStorageClass}
+	if _, err := obj.PutObject(bucket, "object", mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), metadata); err != nil {
+		t.Fatalf("Failed to putObject %v", err)
+	}
+	parts, errs := readAllXLMetadata(xl.storageDisks, bucket, "object")
+
+	tests := []struct {
+		name                   int
+		onlineDisks            int
+		expectedFullRedundancy bool
+	}{
+		{1, 16, true},
+		// Object with 14 data and 2 parity blocks can still be read, but not fully protected.
+		{2, 15, false},
+		{3, 14, false},
+	}
+	for _, tt := range tests {
+		qInfo, fullRedundancy, err := objectRedundancyFromMeta(*xl, parts, errs, tt.onlineDisks)
+		if err != nil {
+			t.Fatalf("Test %d, Unexpected error %v", tt.name, err)
+		}
+		if qInfo.Required != 14 || qInfo.ParityBlocks != 2 {
+			t.Errorf("Test %d, Expected 14 data and 2 parity blocks, got %d and %d", tt.name, qInfo.Required, qInfo.ParityBlocks)
+		}
+		if fullRedundancy != tt.expectedFullRedundancy {
+			t.Errorf("Test %d, Expected full redundancy %t, got %t", tt.name, tt.expectedFullRedundancy, fullRedundancy)
+		}
+	}
+
+	// Heal status reports the object can only be partially healed while a disk is offline.
+	if status := xlHealStat(*xl, parts, errs).Status; status != canHeal {
+		t.Errorf("Expected heal status %v, got %v", canHeal, status)
+	}
+	parts[0], errs[0] = xlMetaV1{}, errDiskNotFound
+	healStat := xlHealStat(*xl, parts, errs)
+	if healStat.Status != canPartiallyHeal {
+		t.Errorf("Expected heal status %v, got %v", canPartiallyHeal, healStat.Status)
+	}
+	// One valid xl.json above the data blocks.
+	if healStat.HealPriority != maxErasureBlocks-1 {
+		t.Errorf("Expected heal priority %d, got %d", maxErasureBlocks-1, healStat.HealPriority)
+	}
+
+	// Object without read quorum is never fully protected.
+	for _, index := range []int{1, 2} {
+		parts[index], errs[index] = xlMetaV1{}, errDiskNotFound
+	}
+	if _, fullRedundancy, err := objectRedundancyFromMeta(*xl, parts, errs, 16); err != errXLReadQuorum || fullRedundancy {
+		t.Errorf("Expected %s without full redundancy, got %v and %t", errXLReadQuorum, err, fullRedundancy)
+	}
+}
+
+// Test isValidStorageClassMeta method with valid and invalid inputs
+func TestIsValidStorageClassMeta(t *testing.T) {
+	tests := []struct {
+		name int
+		sc   string
+		want bool
+	}{
+		{1, "STANDARD", true},
+		{2, "REDUCED_REDUNDANCY", true},
+		{3, "", false},
+		{4, "INVALID", false},
+		{5, "123", false},
+		{6, "MINIO_STORAGE_CLASS_RRS", false},
+		{7, "MINIO_STORAGE_CLASS_STANDARD", false},
+		{8, "MAX_DURABILITY", true},
+		{9, "MINIO_STORAGE_CLASS_MAX", false},
+		{10, "GLACIER", true},
+		{11, "ONEZONE_IA", false},
+		// Storage classes and aliases are case-insensitive.
+		{12, "standard", true},
+		{13, "Reduced_Redundancy", true},
+		{14, "glacier", true},
+		{15, "invalid", false},
+	}
+	globalStorageClassAliases = map[string]string{"GLACIER": reducedRedundancyStorageClass}
+	defer resetGlobalStorageEnvs()
+	for _, tt := range tests {
+		if got := isValidStorageClassMeta(tt.sc); got != tt.want {
+			t.Errorf("Test %d, Expected Storage Class to be %t, got %t", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestGetStorageClassFromAliasMixedCase(t *testing.T) {
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+	globalStorageClassAliases = map[string]string{"GLACIER": reducedRedundancyStorageClass}
+
+	tests := []struct {
+		name           int
+		sc             string
+		expectedSc     string
+		expectedParity int
+	}{
+		{1, "standard", standardStorageClass, 8},
+		{2, "Standard", standardStorageClass, 8},
+		{3, "reduced_redundancy", reducedRedundancyStorageClass, 2},
+		{4, "Reduced_Redundancy", reducedRedundancyStorageClass, 2},
+		{5, "Glacier", reducedRedundancyStorageClass, 2},
+		// Unknown storage classes are returned as is.
+		{6, "Cold", "Cold", 8},
+	}
+	for _, tt := range tests {
+		if sc := getStorageClassFromAlias(tt.sc); sc != tt.expectedSc {
+			t.Errorf("Test %d, Expected %s, got %s", tt.name, tt.expectedSc, sc)
+		}
+		if parity := getRedundancyCount(tt.sc, 16).Parity; parity != tt.expectedParity {
+			t.Errorf("Test %d, Expected parity %d, got %d", tt.name, tt.expectedParity, parity)
+		}
+	}
+}
+
+func TestParseStorageClassAliases(t *testing.T) {
+	tests := []struct {
+		name            int
+		aliasesEnv      string
+		expectedAliases map[string]string
+		expectedError   error
+	}{
+		{1, "GLACIER=REDUCED_REDUNDANCY", map[string]string{"GLACIER": "REDUCED_REDUNDANCY"}, nil},
+		{2, "GLACIER=REDUCED_REDUNDANCY,INTELLIGENT_TIERING=STANDARD", map[string]string{
+			"GLACIER":             "REDUCED_REDUNDANCY",
+			"INTELLIGENT_TIERING": "STANDARD",
+		}, nil},
+		{3, "GLACIER", nil, errors.New("Invalid storage class alias GLACIER. Supported format is ALIAS=STORAGE_CLASS")},
+		{4, "=STANDARD", nil, errors.New("Invalid storage class alias =STANDARD. Supported format is ALIAS=STORAGE_CLASS")},
+		{5, "STANDARD=REDUCED_REDUNDANCY", nil, errors.New("Storage class alias STANDARD can not be a supported storage class")},
+		{6, "GLACIER=COLD", nil, errors.New("Unsupported storage class COLD for alias GLACIER")},
+	}
+	for _, tt := range tests {
+		aliases, err := parseStorageClassAliases(tt.aliasesEnv)
+		if !reflect.DeepEqual(err, tt.expectedError) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedError, err)
+			continue
+		}
+		if !reflect.DeepEqual(aliases, tt.expectedAliases) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedAliases, aliases)
+		}
+	}
+}
+
+func TestParseBucketStorageClassRules(t *testing.T) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+	globalStorageClassAliases = map[string]string{"GLACIER": reducedRedundancyStorageClass}
+
+	tests := []struct {
+		name          int
+		rulesEnv      string
+		expectedRules []bucketStorageClassRule
+		expectedError error
+	}{
+		{1, "logs-*=REDUCED_REDUNDANCY", []bucketStorageClassRule{{"logs-*", reducedRedundancyStorageClass}}, nil},
+		{2, "logs-*=GLACIER,*=MAX_DURABILITY", []bucketStorageClassRule{
+			{"logs-*", "GLACIER"},
+			{"*", maxDurabilityStorageClass},
+		}, nil},
+		{3, "logs-*", nil, errors.New("Invalid bucket storage class rule logs-*. Supported format is PATTERN=STORAGE_CLASS")},
+		{4, "=STANDARD", nil, errors.New("Invalid bucket storage class rule =STANDARD. Supported format is PATTERN=STORAGE_CLASS")},
+		{5, "logs-[=STANDARD", nil, errors.New("Invalid bucket pattern logs-[: syntax error in pattern")},
+		{6, "logs-*=COLD", nil, errors.New("Unsupported storage class COLD for bucket pattern logs-*")},
+	}
+	for _, tt := range tests {
+		rules, err := parseBucketStorageClassRules(tt.rulesEnv)
+		if !reflect.DeepEqual(err, tt.expectedError) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedError, err)
+			continue
+		}
+		if !reflect.DeepEqual(rules, tt.expectedRules) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedRules, rules)
+		}
+	}
+
+	// Reduced redundancy storage class, or an alias of it, is rejected if denied as a default.
+	globalStorageClassDenyRRSDefault = true
+	expectedErr := errors.New("Reduced redundancy storage class can not be a default storage class, " +
+		"MINIO_STORAGE_CLASS_DENY_RRS_DEFAULT is on, found for bucket pattern logs-*")
+	if _, err := parseBucketStorageClassRules("*-tmp=MAX_DURABILITY,logs-*=GLACIER"); !reflect.DeepEqual(err, expectedErr) {
+		t.Errorf("Expected %v, got %v", expectedErr, err)
+	}
+	if _, err := parseBucketStorageClassRules("*-tmp=MAX_DURABILITY,logs-*=STANDARD"); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestParseSmallObjectParityThresholds(t *testing.T) {
+	tests := []struct {
+		name               int
+		thresholdsEnv      string
+		expectedThresholds map[string]int64
+		expectedError      error
+	}{
+		{1, "STANDARD=1MiB", map[string]int64{standardStorageClass: 1 << 20}, nil},
+		{2, "STANDARD=1MiB,REDUCED_REDUNDANCY=64KiB", map[string]int64{
+			standardStorageClass:          1 << 20,
+			reducedRedundancyStorageClass: 64 << 10,
+		}, nil},
+		{3, "STANDARD", nil, errors.New("Invalid small object parity threshold STANDARD. Supported format is STORAGE_CLASS=SIZE")},
+		{4, "COLD=1MiB", nil, errors.New("Unsupported storage class COLD for small object parity threshold")},
+		{5, "STANDARD=abc", nil, errors.New("Invalid small object parity threshold abc for storage class STANDARD")},
+		{6, "STANDARD=0", nil, errors.New("Invalid small object parity threshold 0 for storage class STANDARD")},
+	}
+	for _, tt := range tests {
+		thresholds, err := parseSmallObjectParityThresholds(tt.thresholdsEnv)
+		if !reflect.DeepEqual(err, tt.expectedError) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedError, err)
+			continue
+		}
+		if !reflect.DeepEqual(thresholds, tt.expectedThresholds) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedThresholds, thresholds)
+		}
+	}
+}
+
+func TestGetHealRedundancyInfo(t *testing.T) {
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+	globalRRStorageClass = storageClass{Scheme: supportedStorageClassScheme, Parity: 4}
+	globalSmallObjectParityThresholds = map[string]int64{standardStorageClass: 1024}
+
+	newMeta := func(parity int, size int64, meta map[string]string) xlMetaV1 {
+		xlMeta := newXLMetaV1("object", 16-parity, parity)
+		xlMeta.Stat.Size = size
+		xlMeta.Meta = meta
+		return xlMeta
+	}
+	tests := []struct {
+		name             int
+		meta             xlMetaV1
+		expectedParity   int
+		expectedReencode bool
+	}{
+		// Recorded parity differs from the storage class parity.
+		{1, newMeta(2, 4096, map[string]string{amzStorageClass: reducedRedundancyStorageClass}), 4, true},
+		// Object already at the storage class parity.
+		{2, newMeta(4, 4096, map[string]string{amzStorageClass: reducedRedundancyStorageClass}), 4, false},
+		// Objects without storage class are resolved to the default parity.
+		{3, newMeta(2, 4096, map[string]string{}), 8, true},
+		// Small objects are re-encoded with the size tuned parity.
+		{4, newMeta(2, 100, map[string]string{amzStorageClass: standardStorageClass}), 2, false},
+		{5, newMeta(8, 100, map[string]string{amzStorageClass: standardStorageClass}), 2, true},
+		// Forced parity is kept.
+		{6, newMeta(6, 4096, map[string]string{amzStorageClass: reducedRedundancyStorageClass, forceParityKey: "6"}), 0, false},
+	}
+	for _, tt := range tests {
+		info, metadata, reencode := getHealRedundancyInfo(tt.meta, 16)
+		if reencode != tt.expectedReencode {
+			t.Errorf("Test %d, Expected re-encode %t, got %t", tt.name, tt.expectedReencode, reencode)
+		}
+		if info.Parity != tt.expectedParity {
+			t.Errorf("Test %d, Expected parity %d, got %d", tt.name, tt.expectedParity, info.Parity)
+		}
+		if reencode && info.Source == storageClassSourceSize && metadata[sizeTunedParityKey] == "" {
+			t.Errorf("Test %d, Expected size tuned parity to be recorded", tt.name)
+		}
+	}
+
+	// Standard parity below N/2 applies to objects written with Standard storage
+	// class, objects without storage class keep the default parity.
+	globalStandardStorageClass = storageClass{Scheme: supportedStorageClassScheme, Parity: 2}
+	globalRedundancyCache.Invalidate()
+	defaultTests := []struct {
+		name             int
+		meta             xlMetaV1
+		expectedParity   int
+		expectedReencode bool
+	}{
+		{1, newMeta(8, 4096, map[string]string{}), 8, false},
+		{2, newMeta(8, 4096, map[string]string{amzStorageClass: standardStorageClass}), 2, true},
+		// Size tuned parity never lowers the parity of objects without storage class.
+		{3, newMeta(8, 100, map[string]string{}), 2, false},
+	}
+	for _, tt := range defaultTests {
+		info, _, reencode := getHealRedundancyInfo(tt.meta, 16)
+		if reencode != tt.expectedReencode {
+			t.Errorf("Test %d, Expected re-encode %t, got %t", tt.name, tt.expectedReencode, reencode)
+		}
+		if info.Parity != tt.expectedParity {
+			t.Errorf("Test %d, Expected parity %d, got %d", tt.name, tt.expectedParity, info.Parity)
+		}
+	}
+}
+
+func TestTuneParityForSize(t *testing.T) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+	globalSmallObjectParityThresholds = map[string]int64{standardStorageClass: 1 << 20}
+
+	tests := []struct {
+		name           int
+		sc             string
+		size           int64
+		metadata       map[string]string
+		expectedData   int
+		expectedParity int
+		expectedTuned  string
+	}{
+		// Smaller than the threshold.
+		{1, standardStorageClass, 1024, map[string]string{}, 14, 2, "2"},
+		// Not smaller than the threshold.
+		{2, standardStorageClass, 1 << 20, map[string]string{}, 8, 8, ""},
+		// Storage class without threshold.
+		{3, reducedRedundancyStorageClass, 1024, map[string]string{}, 14, 2, ""},
+		// Forced parity is never tuned.
+		{4, standardStorageClass, 1024, map[string]string{forceParityKey: "6"}, 8, 8, ""},
+		// Parity tuned for a previous size is dropped.
+		{5, standardStorageClass, 1 << 20, map[string]string{sizeTunedParityKey: "2"}, 8, 8, ""},
+		// Encrypted objects are tuned by their plain size, 1 MiB - 1 bytes
+		// encrypted are 1049087 bytes.
+		{6, standardStorageClass, 1049087, map[string]string{ServerSideEncryptionSealAlgorithm: SSESealAlgorithmDareSha256}, 14, 2, "2"},
+		{7, standardStorageClass, 1049087, map[string]string{}, 8, 8, ""},
+	}
+	for _, tt := range tests {
+		info := tuneParityForSize(getRedundancyCount(tt.sc, 16), tt.size, tt.metadata)
+		if info.Data != tt.expectedData || info.Parity != tt.expectedParity {
+			t.Errorf("Test %d, Expected %d data %d parity, got %d data %d parity", tt.name, tt.expectedData, tt.expectedParity, info.Data, info.Parity)
+		}
+		if tuned := tt.metadata[sizeTunedParityKey]; tuned != tt.expectedTuned {
+			t.Errorf("Test %d, Expected tuned parity %q, got %q", tt.name, tt.expectedTuned, tuned)
+		}
+	}
+}
+
+func TestStorageClassAliasRedundancyCount(t *testing.T) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	globalStorageClassAliases = map[string]string{
+		"GLACIER":             reducedRedundancyStorageClass,
+		"INTELLIGENT_TIERING": standardStorageClass,
+	}
+	defer resetGlobalStorageEnvs()
+
+	tests := []struct {
+		name           int
+		sc             string
+		expectedData   int
+		expectedParity int
+	}{
+		{1, "GLACIER", 14, 2},
+		{2, "INTELLIGENT_TIERING", 8, 8},
+		{3, reducedRedundancyStorageClass, 14, 2},
+	}
+	for _, tt := range tests {
+		info := getRedundancyCount(tt.sc, 16)
+		if info.Data != tt.expectedData {
+			t.Errorf("Test %d, Expected data disks %d, got %d", tt.name, tt.expectedData, info.Data)
+		}
+		if info.Parity != tt.expectedParity {
+			t.Errorf("Test %d, Expected parity disks %d, got %d", tt.name, tt.expectedParity, info.Parity)
+		}
+	}
+
+	// Alias is saved as the storage class it maps to.
+	metadata, err := extractMetadataFromHeader(http.Header{amzStorageClassCanonical: []string{"GLACIER"}})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if metadata[amzStorageClass] != reducedRedundancyStorageClass {
+		t.Errorf("Expected %s, got %s", reducedRedundancyStorageClass, metadata[amzStorageClass])
+	}
+}
+
+func TestCheckStorageClassHeader(t *testing.T) {
+	ExecObjectLayerTest(t, testCheckStorageClassHeader)
+}
+
+func testCheckStorageClassHeader(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+	globalStorageClassAliases = map[string]string{"GLACIER": reducedRedundancyStorageClass}
+
+	tests := []struct {
+		name       int
+		sc         string
+		expectedFS APIErrorCode
+		expectedXL APIErrorCode
+	}{
+		{1, standardStorageClass, ErrNone, ErrNone},
+		{2, reducedRedundancyStorageClass, ErrStorageClassNotSupported, ErrNone},
+		{3, maxDurabilityStorageClass, ErrStorageClassNotSupported, ErrNone},
+		{4, "GLACIER", ErrStorageClassNotSupported, ErrNone},
+		{5, "INVALID", ErrInvalidStorageClass, ErrInvalidStorageClass},
+	}
+	for _, tt := range tests {
+		expected := tt.expectedXL
+		if instanceType == FSTestStr {
+			expected = tt.expectedFS
+		}
+		if got := checkStorageClassHeader(obj, tt.sc); got != expected {
+			t.Errorf("Test %d, Minio %s: Expected %v, got %v", tt.name, instanceType, expected, got)
+		}
+	}
+
+	if instanceType != FSTestStr {
+		return
+	}
+	// STANDARD storage class is saved and returned as is by FS.
+	bucket := getRandomBucketName()
+	if err := obj.MakeBucketWithLocation(bucket, globalMinioDefaultRegion); err != nil {
+		t.Fatalf("Failed to make a bucket %v", err)
+	}
+	data := []byte("hello")
+	metadata := map[string]string{amzStorageClass: standardStorageClass}
+	if _, err := obj.PutObject(bucket, "object", mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), metadata); err != nil {
+		t.Fatalf("Failed to putObject %v", err)
+	}
+	objInfo, err := obj.GetObjectInfo(bucket, "object")
+	if err != nil {
+		t.Fatalf("Unable to get object info %v", err)
+	}
+	if sc := objInfo.UserDefined[amzStorageClass]; sc != standardStorageClass {
+		t.Errorf("Expected storage class %s, got %s", standardStorageClass, sc)
+	}
+}
+
+func TestStorageOverhead(t *testing.T) {
+	resetGlobalStorageEnvs()
+	tests := []struct {
+		name             int
+		sc               string
+		totalDisks       int
+		expectedOverhead float64
+	}{
+		{1, standardStorageClass, 8, 2},
+		{2, reducedRedundancyStorageClass, 8, 8.0 / 6},
+		{3, "", 16, 2},
+		{4, reducedRedundancyStorageClass, 16, 16.0 / 14},
+		// Unknown storage class.
+		{5, "GLACIER", 16, 0},
+		// No data disks.
+		{6, standardStorageClass, 0, 0},
+		{7, reducedRedundancyStorageClass, 2, 0},
+	}
+	for _, tt := range tests {
+		if got := storageOverhead(tt.sc, tt.totalDisks); got != tt.expectedOverhead {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedOverhead, got)
+		}
+	}
+}
+
+func TestErasureObjectSize(t *testing.T) {
+	resetGlobalStorageEnvs()
+	tests := []struct {
+		name         int
+		size         int64
+		sc           string
+		totalDisks   int
+		expectedSize int64
+	}{
+		// Zero byte object.
+		{1, 0, standardStorageClass, 16, 0},
+		// Small object, each disk holds a chunk of 1 byte.
+		{2, 1, standardStorageClass, 16, 16},
+		{3, 100, "", 4, 200},
+		// Full block at N/2 parity.
+		{4, blockSizeV1, standardStorageClass, 8, 2 * blockSizeV1},
+		{5, 2*blockSizeV1 + 1, standardStorageClass, 8, 4*blockSizeV1 + 8},
+		// Reduced redundancy with 14 data disks.
+		{6, blockSizeV1, reducedRedundancyStorageClass, 16, getChunkSize(blockSizeV1, 14) * 16},
+		// Non-erasure setup.
+		{7, blockSizeV1, standardStorageClass, 1, blockSizeV1},
+	}
+	for _, tt := range tests {
+		if got := ErasureObjectSize(tt.size, tt.sc, tt.totalDisks); got != tt.expectedSize {
+			t.Errorf("Test %d, Expected %d, got %d", tt.name, tt.expectedSize, got)
+		}
+	}
+}
+
+func TestHasCapacityForObject(t *testing.T) {
+	resetGlobalStorageEnvs()
+	info := StorageInfo{Free: 10 * blockSizeV1}
+	info.Backend.Type = Erasure
+	info.Backend.OnlineDisks = 6
+	info.Backend.OfflineDisks = 2
+
+	tests := []struct {
+		name       int
+		size       int64
+		dataBlocks int
+		expected   bool
+	}{
+		{1, 10 * blockSizeV1, 4, true},
+		{2, 10*blockSizeV1 + 1, 4, false},
+		{3, 10*blockSizeV1 + 1, 6, true},
+		// Zero-byte objects and missing data blocks are never rejected.
+		{4, 0, 4, true},
+		{5, 100 * blockSizeV1, 0, true},
+	}
+	for _, tt := range tests {
+		if got := hasCapacityForObject(info, tt.size, tt.dataBlocks); got != tt.expected {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+
+	// FS backend is never rejected.
+	info.Backend.Type = FS
+	if !hasCapacityForObject(info, 100*blockSizeV1, 4) {
+		t.Errorf("Expected FS backend to have capacity")
+	}
+}
+
+// Tests storage info is fetched in the background once at a time,
+// the storage info fetched last is served meanwhile.
+func TestFreeSpaceCache(t *testing.T) {
+	obj, fsDirs, err := prepareXL16()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	c := newFreeSpaceCache()
+	for i := 0; i < 10; i++ {
+		if _, ok := c.Get(obj); ok {
+			t.Fatalf("Expected storage info to not be fetched yet")
+		}
+	}
+	for i := 0; i < 100; i++ {
+		c.mutex.Lock()
+		refreshing := c.refreshing
+		c.mutex.Unlock()
+		if !refreshing {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	info, ok := c.Get(obj)
+	if !ok {
+		t.Fatalf("Expected storage info to be fetched")
+	}
+	if info.Backend.OnlineDisks != 16 || info.Free == 0 {
+		t.Errorf("Expected storage info of 16 disks, got %v", info.Backend)
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.refreshing {
+		t.Errorf("Expected fresh storage info to not be fetched again")
+	}
+}
+
+func TestGetUsableFreeSpace(t *testing.T) {
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+	globalRRStorageClass = storageClass{Scheme: supportedStorageClassScheme, Parity: 4}
+	globalRedundancyCache.Invalidate()
+
+	tests := []struct {
+		name       int
+		rawFree    uint64
+		sc         string
+		totalDisks int
+		expected   uint64
+	}{
+		// Default parity N/2, half the raw free space is usable.
+		{1, 1600, standardStorageClass, 16, 800},
+		{2, 1600, reducedRedundancyStorageClass, 16, 1200},
+		{3, 1600, scratchStorageClass, 16, 1400},
+		{4, 1000, standardStorageClass, 8, 500},
+		// Raw free space is returned as is without erasure coding.
+		{5, 1000, standardStorageClass, 1, 1000},
+		{6, 0, reducedRedundancyStorageClass, 16, 0},
+	}
+	for _, tt := range tests {
+		if got := getUsableFreeSpace(tt.rawFree, tt.sc, tt.totalDisks); got != tt.expected {
+			t.Errorf("Test %d, Expected %d, got %d", tt.name, tt.expected, got)
+		}
+	}
+
+	expected := map[string]uint64{
+		standardStorageClass:          800,
+		reducedRedundancyStorageClass: 1200,
+		maxDurabilityStorageClass:     800,
+		scratchStorageClass:           1400,
+	}
+	if got := getUsableFreeSpaces(1600, 16); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	expected = map[string]uint64{standardStorageClass: 1000}
+	if got := getUsableFreeSpaces(1000, 1); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestLoadStorageClassConfigFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "minio-storage-class")
+	if err != nil {
+		t.Fatalf("Unable to create temporary directory %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name          int
+		content       string
+		expectedCfg   storageClassConfig
+		expectedError string
+	}{
+		{1, `{"standard":"EC:6","rrs":"EC:2"}`, storageClassConfig{
+			Standard: storageClass{Scheme: "EC", Parity: 6},
+			RRS:      storageClass{Scheme: "EC", Parity: 2},
+		}, ""},
+		// Storage class not set in the file is left unset.
+		{2, `{"standard":"EC:4"}`, storageClassConfig{Standard: storageClass{Scheme: "EC", Parity: 4}}, ""},
+		{3, `{"standard":"EC:4","rrs":"AB:2"}`, storageClassConfig{}, "Invalid field rrs in storage class config file %s: Unsupported scheme AB. Supported scheme is EC"},
+		{4, `{"standard":"EC"}`, storageClassConfig{}, "Invalid field standard in storage class config file %s: Too few sections in EC"},
+		{5, `{"standard":`, storageClassConfig{}, "Unable to parse storage class config file %s: unexpected end of JSON input"},
+	}
+	for _, tt := range tests {
+		configFile := filepath.Join(dir, "storageclass.json")
+		if err = ioutil.WriteFile(configFile, []byte(tt.content), 0644); err != nil {
+			t.Fatalf("Unable to write config file %v", err)
+		}
+		sCfg, err := loadStorageClassConfigFile(configFile)
+		if tt.expectedError != "" {
+			if err == nil || err.Error() != fmt.Sprintf(tt.expectedError, configFile) {
+				t.Errorf("Test %d, Expected %s, got %v", tt.name, fmt.Sprintf(tt.expectedError, configFile), err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d, Unexpected error %v", tt.name, err)
+			continue
+		}
+		if sCfg != tt.expectedCfg {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedCfg, sCfg)
+		}
+	}
+
+	// Missing config file.
+	if _, err = loadStorageClassConfigFile(filepath.Join(dir, "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected file not found error, got %v", err)
+	}
+}
+
+func TestGetStandardParityStartupMsg(t *testing.T) {
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+	tests := []struct {
+		name         int
+		ssc          storageClass
+		disks        int
+		expectedMsg  string
+		expectedWarn bool
+	}{
+		{1, storageClass{}, 16, "Standard storage class parity is 8 of 16 disks, storage overhead is 2.00x", false},
+		{2, storageClass{}, 32, "Standard storage class parity is 16 of 32 disks, storage overhead is 2.00x", true},
+		{3, storageClass{Scheme: "EC", Parity: 4}, 32, "Standard storage class parity is 4 of 32 disks, storage overhead is 1.14x", false},
+		{4, storageClass{Scheme: "EC", Parity: 9}, 18, "Standard storage class parity is 9 of 18 disks, storage overhead is 2.00x", true},
+	}
+	for _, tt := range tests {
+		globalStandardStorageClass = tt.ssc
+		globalRedundancyCache.Invalidate()
+		msg, warn := getStandardParityStartupMsg(tt.disks)
+		if msg != tt.expectedMsg {
+			t.Errorf("Test %d, Expected %s, got %s", tt.name, tt.expectedMsg, msg)
+		}
+		if warn != tt.expectedWarn {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedWarn, warn)
+		}
+	}
+}
+
+// Tests the parity cap is N/2 of the disks, rounded down for odd disks.
+func TestMaxParityForDisks(t *testing.T) {
+	tests := []struct {
+		name           int
+		disks          int
+		expectedParity int
+	}{
+		{1, 4, 2},
+		{2, 5, 2},
+		{3, 6, 3},
+		{4, 7, 3},
+		{5, 15, 7},
+		{6, 16, 8},
+		{7, 17, 8},
+		{8, 32, 16},
+	}
+	for _, tt := range tests {
+		if parity := maxParityForDisks(tt.disks); parity != tt.expectedParity {
+			t.Errorf("Test %d, Expected %d, got %d", tt.name, tt.expectedParity, parity)
+		}
+	}
+}
+
+func TestGetImplicitStandardParityMsg(t *testing.T) {
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+	tests := []struct {
+		name        int
+		ssc         storageClass
+		rrsc        storageClass
+		maxParity   int
+		disks       int
+		expectedMsg string
+	}{
+		{1, storageClass{}, storageClass{}, 0, 16, ""},
+		{2, storageClass{}, storageClass{Scheme: "EC", Parity: 2}, 0, 16,
+			"Reduced redundancy storage class is set to EC:2 (parity 2) but standard storage class is not set and defaults to parity 8 of 16 disks"},
+		{3, storageClass{}, storageClass{Scheme: "EC", Relative: 0.5}, 0, 16,
+			"Reduced redundancy storage class is set to EC:x0.5 (parity 4) but standard storage class is not set and defaults to parity 8 of 16 disks"},
+		// Implied parity is capped by the maximum default parity.
+		{4, storageClass{}, storageClass{Scheme: "EC", Parity: 2}, 6, 32,
+			"Reduced redundancy storage class is set to EC:2 (parity 2) but standard storage class is not set and defaults to parity 6 of 32 disks"},
+		// Default parity set explicitly.
+		{5, storageClass{Scheme: "EC"}, storageClass{Scheme: "EC", Parity: 2}, 0, 16, ""},
+		{6, storageClass{Scheme: "EC", Parity: 4}, storageClass{Scheme: "EC", Parity: 2}, 0, 16, ""},
+		{7, storageClass{Scheme: "EC", Parity: 4}, storageClass{}, 0, 16, ""},
+	}
+	for _, tt := range tests {
+		globalStandardStorageClass, globalRRStorageClass = tt.ssc, tt.rrsc
+		globalStorageClassMaxParity = tt.maxParity
+		globalRedundancyCache.Invalidate()
+		if msg := getImplicitStandardParityMsg(tt.disks); msg != tt.expectedMsg {
+			t.Errorf("Test %d, Expected %s, got %s", tt.name, tt.expectedMsg, msg)
+		}
+	}
+}
+
+func TestParseForceParity(t *testing.T) {
+	tests := []struct {
+		name           int
+		forceParity    string
+		totalDisks     int
+		expectedParity int
+		expectedError  error
+	}{
+		{1, "max", 16, 8, nil},
+		{2, "max", 5, 2, nil},
+		{3, "6", 16, 6, nil},
+		{4, "2", 4, 2, nil},
+		// Parity above N/2.
+		{5, "9", 16, 0, errInvalidForceParity},
+		// Parity below minimum parity disks.
+		{6, "1", 16, 0, errInvalidForceParity},
+		{7, "MAX", 16, 0, errInvalidForceParity},
+		{8, "", 16, 0, errInvalidForceParity},
+	}
+	for _, tt := range tests {
+		parity, err := parseForceParity(tt.forceParity, tt.totalDisks)
+		if err != tt.expectedError {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedError, err)
+		}
+		if parity != tt.expectedParity {
+			t.Errorf("Test %d, Expected %d, got %d", tt.name, tt.expectedParity, parity)
+		}
+	}
+}
+
+func TestPutObjectForceParity(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testPutObjectForceParity)
+}
+
+func testPutObjectForceParity(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	// Set globalEndpoints for a single node XL setup.
+	globalEndpoints = mustGetNewEndpointList(dirs...)
+	defer resetGlobalEndpoints()
+	defer resetGlobalStorageEnvs()
+	// Forced parity is only extracted from the headers for XL.
+	globalIsXL = true
+	defer func() { globalIsXL = false }()
+
+	xl := obj.(*xlObjects)
+	bucket := getRandomBucketName()
+	if err := obj.MakeBucketWithLocation(bucket, globalMinioDefaultRegion); err != nil {
+		t.Fatalf("Failed to make a bucket %v", err)
+	}
+
+	data := bytes.Repeat([]byte("a"), 1024)
+	tests := []struct {
+		name           int
+		header         http.Header
+		expectedParity int
+		expectedErr    APIErrorCode
+	}{
+		{1, http.Header{amzStorageClassCanonical: {reducedRedundancyStorageClass}, amzForceParity: {forceParityMax}}, 8, ErrNone},
+		{2, http.Header{amzForceParity: {"4"}}, 4, ErrNone},
+		{3, http.Header{amzStorageClassCanonical: {reducedRedundancyStorageClass}}, 2, ErrNone},
+		{4, http.Header{amzForceParity: {"10"}}, 0, ErrInvalidForceParity},
+		// Parity set via user metadata wins over storage class.
+		{5, http.Header{amzStorageClassCanonical: {reducedRedundancyStorageClass}, amzMetaParity: {"6"}}, 6, ErrNone},
+		// Forced parity wins over parity set via user metadata.
+		{6, http.Header{amzForceParity: {"4"}, amzMetaParity: {"6"}}, 4, ErrNone},
+		{7, http.Header{amzMetaParity: {"1"}}, 0, ErrInvalidForceParity},
+		{8, http.Header{amzMetaParity: {"parity"}}, 0, ErrInvalidForceParity},
+	}
+	for _, tt := range tests {
+		metadata, err := extractMetadataFromHeader(tt.header)
+		if err != nil {
+			t.Fatalf("Test %d, Unable to extract metadata %v", tt.name, err)
+		}
+		object := fmt.Sprintf("object-%d", tt.name)
+		_, err = obj.PutObject(bucket, object, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), metadata)
+		if apiErr := toAPIErrorCode(err); apiErr != tt.expectedErr {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedErr, apiErr)
+			continue
+		}
+		if tt.expectedErr != ErrNone {
+			continue
+		}
+		parts, _ := readAllXLMetadata(xl.storageDisks, bucket, object)
+		if parts[0].Erasure.ParityBlocks != tt.expectedParity {
+			t.Errorf("Test %d, Expected parity %d, got %d", tt.name, tt.expectedParity, parts[0].Erasure.ParityBlocks)
+		}
+		if _, ok := parts[0].Meta[forceParityKey]; ok && parts[0].Meta[forceParityKey] != strconv.Itoa(tt.expectedParity) {
+			t.Errorf("Test %d, Expected forced parity %d, got %s", tt.name, tt.expectedParity, parts[0].Meta[forceParityKey])
+		}
+	}
+}
+
+func TestCopyObjectForceParity(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testCopyObjectForceParity)
+}
+
+func testCopyObjectForceParity(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	// Set globalEndpoints for a single node XL setup.
+	globalEndpoints = mustGetNewEndpointList(dirs...)
+	defer resetGlobalEndpoints()
+	defer resetGlobalStorageEnvs()
+	// Forced parity is only extracted from the headers for XL.
+	globalIsXL = true
+	defer func() { globalIsXL = false }()
+
+	xl := obj.(*xlObjects)
+	bucket := getRandomBucketName()
+	if err := obj.MakeBucketWithLocation(bucket, globalMinioDefaultRegion); err != nil {
+		t.Fatalf("Failed to make a bucket %v", err)
+	}
+
+	data := bytes.Repeat([]byte("a"), 1024)
+	tests := []struct {
+		name           int
+		header         http.Header
+		expectedClass  string
+		expectedParity int
+	}{
+		// Forced parity of the source is dropped with a new storage class.
+		{1, http.Header{amzStorageClassCanonical: {reducedRedundancyStorageClass}}, reducedRedundancyStorageClass, 2},
+		// Parity forced by the copy request is kept.
+		{2, http.Header{amzStorageClassCanonical: {reducedRedundancyStorageClass}, amzForceParity: {"4"}}, reducedRedundancyStorageClass, 4},
+		// Forced parity of the source is retained without a new storage class.
+		{3, http.Header{}, "", 8},
+	}
+	for _, tt := range tests {
+		object := fmt.Sprintf("object-%d", tt.name)
+		metadata, err := extractMetadataFromHeader(http.Header{amzForceParity: {forceParityMax}})
+		if err != nil {
+			t.Fatalf("Test %d, Unable to extract metadata %v", tt.name, err)
+		}
+		if _, err = obj.PutObject(bucket, object, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), metadata); err != nil {
+			t.Fatalf("Test %d, Failed to putObject %v", tt.name, err)
+		}
+		srcInfo, err := obj.GetObjectInfo(bucket, object)
+		if err != nil {
+			t.Fatalf("Test %d, Failed to getObjectInfo %v", tt.name, err)
+		}
+		meta, err := getCpObjMetadataFromHeader(tt.header, srcInfo.UserDefined)
+		if err != nil {
+			t.Fatalf("Test %d, Unexpected error %v", tt.name, err)
+		}
+		if _, err = obj.CopyObject(bucket, object, bucket, object, meta); err != nil {
+			t.Fatalf("Test %d, Failed to copyObject %v", tt.name, err)
+		}
+		xlMeta, err := readXLMeta(xl.storageDisks[0], bucket, object)
+		if err != nil {
+			t.Fatalf("Test %d, Failed to read xl.json %v", tt.name, err)
+		}
+		if xlMeta.Meta[amzStorageClass] != tt.expectedClass {
+			t.Errorf("Test %d, Expected storage class %s, got %s", tt.name, tt.expectedClass, xlMeta.Meta[amzStorageClass])
+		}
+		if xlMeta.Erasure.ParityBlocks != tt.expectedParity {
+			t.Errorf("Test %d, Expected parity %d, got %d", tt.name, tt.expectedParity, xlMeta.Erasure.ParityBlocks)
+		}
+	}
+}
+
+func TestObjectStorageClassMeta(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testObjectStorageClassMeta)
+}
+
+func testObjectStorageClassMeta(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	// Set globalEndpoints for a single node XL setup.
+	globalEndpoints = mustGetNewEndpointList(dirs...)
+	defer resetGlobalEndpoints()
+	defer resetGlobalStorageEnvs()
+
+	// Standard storage class parity is different from the default parity.
+	globalStandardStorageClass = storageClass{Scheme: "EC", Parity: 4}
+
+	xl := obj.(*xlObjects)
+	bucket := getRandomBucketName()
+	if err := obj.MakeBucketWithLocation(bucket, globalMinioDefaultRegion); err != nil {
+		t.Fatalf("Failed to make a bucket %v", err)
+	}
+
+	data := bytes.Repeat([]byte("a"), 1024)
+	tests := []struct {
+		name           int
+		metadata       map[string]string
+		expectedClass  string
+		expectedParity int
+	}{
+		// Object without storage class is written with the default parity, not with
+		// the Standard parity, and is saved without storage class.
+		{1, nil, "", 8},
+		{2, map[string]string{amzStorageClass: standardStorageClass}, standardStorageClass, 4},
+		{3, map[string]string{amzStorageClass: reducedRedundancyStorageClass}, reducedRedundancyStorageClass, 2},
+	}
+	for _, tt := range tests {
+		object := fmt.Sprintf("object-%d", tt.name)
+		if _, err := obj.PutObject(bucket, object, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), tt.metadata); err != nil {
+			t.Fatalf("Test %d, Failed to putObject %v", tt.name, err)
+		}
+		parts, errs := readAllXLMetadata(xl.storageDisks, bucket, object)
+		if parts[0].Meta[amzStorageClass] != tt.expectedClass {
+			t.Errorf("Test %d, Expected storage class %s, got %s", tt.name, tt.expectedClass, parts[0].Meta[amzStorageClass])
+		}
+
+		// STANDARD storage class is not returned in response headers, same as AWS S3.
+		objInfo, err := obj.GetObjectInfo(bucket, object)
+		if err != nil {
+			t.Fatalf("Test %d, Failed to getObjectInfo %v", tt.name, err)
+		}
+		if expected := removeStandardStorageClass(map[string]string{amzStorageClass: tt.expectedClass}); objInfo.UserDefined[amzStorageClass] != expected[amzStorageClass] {
+			t.Errorf("Test %d, Expected storage class %s, got %s", tt.name, expected[amzStorageClass], objInfo.UserDefined[amzStorageClass])
+		}
+		// Object without storage class is reported as Standard.
+		if expected := getObjectStorageClass(map[string]string{amzStorageClass: tt.expectedClass}); objInfo.StorageClass != expected {
+			t.Errorf("Test %d, Expected reported storage class %s, got %s", tt.name, expected, objInfo.StorageClass)
+		}
+
+		qInfo, err := objectQuorumInfoFromMeta(*xl, parts, errs)
+		if err != nil {
+			t.Fatalf("Test %d, Unexpected error %v", tt.name, err)
+		}
+		if qInfo.StorageClass != tt.expectedClass {
+			t.Errorf("Test %d, Expected storage class %s, got %s", tt.name, tt.expectedClass, qInfo.StorageClass)
+		}
+		if parts[0].Erasure.ParityBlocks != tt.expectedParity {
+			t.Errorf("Test %d, Expected parity %d, got %d", tt.name, tt.expectedParity, parts[0].Erasure.ParityBlocks)
+		}
+	}
+
+	// Copying the object without storage class to Standard storage class
+	// re-encodes it with the Standard parity rather than only relabelling it.
+	metadata := map[string]string{amzStorageClass: standardStorageClass}
+	if _, err := obj.CopyObject(bucket, "object-1", bucket, "object-1", metadata); err != nil {
+		t.Fatalf("Failed to copyObject %v", err)
+	}
+	xlMeta, err := readXLMeta(xl.storageDisks[0], bucket, "object-1")
+	if err != nil {
+		t.Fatalf("Failed to read xl.json %v", err)
+	}
+	if xlMeta.Meta[amzStorageClass] != standardStorageClass || xlMeta.Erasure.ParityBlocks != 4 {
+		t.Errorf("Expected storage class %s with parity 4, got %s with parity %d", standardStorageClass,
+			xlMeta.Meta[amzStorageClass], xlMeta.Erasure.ParityBlocks)
+	}
+}
+
+func TestStorageClassFromRedundancy(t *testing.T) {
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+
+	tests := []struct {
+		name          int
+		ssc           storageClass
+		rrsc          storageClass
+		maxsc         storageClass
+		data          int
+		parity        int
+		totalDisks    int
+		expectedClass string
+	}{
+		{1, storageClass{}, storageClass{}, storageClass{}, 8, 8, 16, standardStorageClass},
+		{2, storageClass{}, storageClass{}, storageClass{}, 14, 2, 16, reducedRedundancyStorageClass},
+		{3, storageClass{}, storageClass{}, storageClass{}, 12, 4, 16, ""},
+		// Data and parity disks don't add up to total disks.
+		{4, storageClass{}, storageClass{}, storageClass{}, 8, 8, 12, ""},
+		// Configured storage classes.
+		{5, storageClass{Scheme: "EC", Parity: 4}, storageClass{Scheme: "EC", Parity: 3}, storageClass{}, 12, 4, 16, standardStorageClass},
+		{6, storageClass{Scheme: "EC", Parity: 4}, storageClass{Scheme: "EC", Parity: 3}, storageClass{}, 13, 3, 16, reducedRedundancyStorageClass},
+		{7, storageClass{Scheme: "EC", Parity: 4}, storageClass{Scheme: "EC", Parity: 3}, storageClass{Scheme: "EC", Parity: 6}, 10, 6, 16, maxDurabilityStorageClass},
+		// Default N/2 parity of objects without storage class.
+		{8, storageClass{Scheme: "EC", Parity: 4}, storageClass{}, storageClass{Scheme: "EC", Parity: 6}, 8, 8, 16, standardStorageClass},
+		// RRS parity equal to STANDARD parity on 4 disks resolves to the more durable STANDARD.
+		{9, storageClass{Scheme: "EC", Parity: 2}, storageClass{Scheme: "EC", Parity: 2}, storageClass{}, 2, 2, 4, standardStorageClass},
+	}
+	for _, tt := range tests {
+		globalStandardStorageClass, globalRRStorageClass, globalMaxStorageClass = tt.ssc, tt.rrsc, tt.maxsc
+		globalRedundancyCache.Invalidate()
+		if sc := storageClassFromRedundancy(tt.data, tt.parity, tt.totalDisks); sc != tt.expectedClass {
+			t.Errorf("Test %d, Expected %s, got %s", tt.name, tt.expectedClass, sc)
+		}
+	}
+}
+
+func TestCompareStorageClass(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testCompareStorageClass)
+}
+
+func testCompareStorageClass(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	// Set globalEndpoints for a single node XL setup.
+	globalEndpoints = mustGetNewEndpointList(dirs...)
+	defer resetGlobalEndpoints()
+	defer resetGlobalStorageEnvs()
+
+	globalStorageClassAliases = map[string]string{"GLACIER": reducedRedundancyStorageClass}
+	tests := []struct {
+		name     int
+		a        string
+		b        string
+		expected int
+	}{
+		{1, reducedRedundancyStorageClass, standardStorageClass, -1},
+		{2, standardStorageClass, reducedRedundancyStorageClass, 1},
+		{3, standardStorageClass, standardStorageClass, 0},
+		// Empty storage class is the default storage class.
+		{4, "", standardStorageClass, 0},
+		{5, "", reducedRedundancyStorageClass, 1},
+		// Alias resolves to its storage class.
+		{6, "GLACIER", reducedRedundancyStorageClass, 0},
+		// Unknown storage classes sort last.
+		{7, "UNKNOWN", standardStorageClass, 1},
+		{8, reducedRedundancyStorageClass, "UNKNOWN", -1},
+		{9, "UNKNOWN", "OTHER", 0},
+	}
+	for _, tt := range tests {
+		if got := compareStorageClass(tt.a, tt.b); got != tt.expected {
+			t.Errorf("Test %d, Expected %d, got %d", tt.name, tt.expected, got)
+		}
+	}
+
+	// Ordering follows the configured parity.
+	globalStandardStorageClass = storageClass{Scheme: "EC", Parity: 4}
+	globalRRStorageClass = storageClass{Scheme: "EC", Parity: 2}
+	if got := compareStorageClass("", reducedRedundancyStorageClass); got != 1 {
+		t.Errorf("Expected %d, got %d", 1, got)
+	}
+}
+
+func TestGetStorageClassInfo(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testGetStorageClassInfo)
+}
+
+func testGetStorageClassInfo(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	// Set globalEndpoints for a single node XL setup.
+	globalEndpoints = mustGetNewEndpointList(dirs...)
+	defer resetGlobalEndpoints()
+
+	tests := []struct {
+		name       int
+		ssc        storageClass
+		rrsc       storageClass
+		wantResult StorageClassInfo
+	}{
+		{1, storageClass{}, storageClass{}, StorageClassInfo{
+			TotalDisks:     16,
+			ErasureMode:    true,
+			StorageClasses: []string{standardStorageClass, reducedRedundancyStorageClass, maxDurabilityStorageClass, scratchStorageClass},
+			Standard:       StorageClassParity{8, 8, storageClassSourceDefault, 16.0 / 8},
+			RRS:            StorageClassParity{14, 2, storageClassSourceDefault, 16.0 / 14},
+			MaxDurability:  StorageClassParity{8, 8, storageClassSourceDefault, 16.0 / 8},
+		}},
+		{2, storageClass{Scheme: "EC", Parity: 6}, storageClass{Scheme: "EC", Parity: 3}, StorageClassInfo{
+			TotalDisks:     16,
+			ErasureMode:    true,
+			StorageClasses: []string{standardStorageClass, reducedRedundancyStorageClass, maxDurabilityStorageClass, scratchStorageClass},
+			Standard:       StorageClassParity{10, 6, storageClassSourceConfig, 16.0 / 10},
+			RRS:            StorageClassParity{13, 3, storageClassSourceConfig, 16.0 / 13},
+			MaxDurability:  StorageClassParity{8, 8, storageClassSourceDefault, 16.0 / 8},
+		}},
+		{3, storageClass{Scheme: "EC", Parity: 4}, storageClass{}, StorageClassInfo{
+			TotalDisks:     16,
+			ErasureMode:    true,
+			StorageClasses: []string{standardStorageClass, reducedRedundancyStorageClass, maxDurabilityStorageClass, scratchStorageClass},
+			Standard:       StorageClassParity{12, 4, storageClassSourceConfig, 16.0 / 12},
+			RRS:            StorageClassParity{14, 2, storageClassSourceDefault, 16.0 / 14},
+			MaxDurability:  StorageClassParity{8, 8, storageClassSourceDefault, 16.0 / 8},
+		}},
+	}
+	for _, tt := range tests {
+		globalStandardStorageClass = tt.ssc
+		globalRRStorageClass = tt.rrsc
+		globalRedundancyCache.Invalidate()
+		if got := getStorageClassInfo(); !reflect.DeepEqual(got, tt.wantResult) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.wantResult, got)
+		}
+	}
+	resetGlobalStorageEnvs()
+}
+
+func TestObjectQuorumInfoHealPriority(t *testing.T) {
+	tests := []struct {
+		name             int
+		available        int
+		required         int
+		parity           int
+		expectedPriority int
+	}{
+		// Nothing missing.
+		{1, 16, 8, 8, 0},
+		// One disk failure away from being unreadable.
+		{2, 8, 8, 8, maxErasureBlocks},
+		{3, 9, 8, 8, maxErasureBlocks - 1},
+		{4, 15, 8, 8, maxErasureBlocks - 7},
+		{5, 14, 14, 2, maxErasureBlocks},
+		// Read quorum lost, can't be healed.
+		{6, 7, 8, 8, 0},
+	}
+	for _, tt := range tests {
+		qInfo := objectQuorumInfo{Available: tt.available, Required: tt.required, ParityBlocks: tt.parity}
+		if priority := qInfo.HealPriority(); priority != tt.expectedPriority {
+			t.Errorf("Test %d, Expected heal priority %d, got %d", tt.name, tt.expectedPriority, priority)
+		}
+	}
+}
+
+func TestGetServerStorageClassConfig(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testGetServerStorageClassConfig)
+}
+
+func testGetServerStorageClassConfig(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+	defer resetGlobalEndpoints()
+
+	storageClasses := []string{standardStorageClass, reducedRedundancyStorageClass, maxDurabilityStorageClass, scratchStorageClass}
+	tests := []struct {
+		name       int
+		dirs       []string
+		ssc        storageClass
+		rrsc       storageClass
+		wantResult ServerStorageClassConfig
+	}{
+		{1, dirs, storageClass{}, storageClass{}, ServerStorageClassConfig{
+			StorageClasses: storageClasses,
+			Standard:       ServerStorageClassLayout{"", 8, 8, storageClassSourceDefault},
+			RRS:            ServerStorageClassLayout{"", 14, 2, storageClassSourceDefault},
+			MaxDurability:  ServerStorageClassLayout{"", 8, 8, storageClassSourceDefault},
+			Scratch:        ServerStorageClassLayout{"", 14, 2, storageClassSourceDefault},
+		}},
+		{2, dirs, storageClass{Scheme: "EC", Parity: 6}, storageClass{Scheme: "EC", Parity: 3}, ServerStorageClassConfig{
+			StorageClasses: storageClasses,
+			Standard:       ServerStorageClassLayout{"EC", 10, 6, storageClassSourceConfig},
+			RRS:            ServerStorageClassLayout{"EC", 13, 3, storageClassSourceConfig},
+			MaxDurability:  ServerStorageClassLayout{"", 8, 8, storageClassSourceDefault},
+			Scratch:        ServerStorageClassLayout{"", 14, 2, storageClassSourceDefault},
+		}},
+		{3, dirs, storageClass{Scheme: "EC", Parity: 4}, storageClass{}, ServerStorageClassConfig{
+			StorageClasses: storageClasses,
+			Standard:       ServerStorageClassLayout{"EC", 12, 4, storageClassSourceConfig},
+			RRS:            ServerStorageClassLayout{"", 14, 2, storageClassSourceDefault},
+			MaxDurability:  ServerStorageClassLayout{"", 8, 8, storageClassSourceDefault},
+			Scratch:        ServerStorageClassLayout{"", 14, 2, storageClassSourceDefault},
+		}},
+		// Not erasure coded, storage classes have no layout.
+		{4, dirs[:1], storageClass{}, storageClass{}, ServerStorageClassConfig{StorageClasses: storageClasses}},
+	}
+	for _, tt := range tests {
+		globalEndpoints = mustGetNewEndpointList(tt.dirs...)
+		globalStandardStorageClass = tt.ssc
+		globalRRStorageClass = tt.rrsc
+		globalRedundancyCache.Invalidate()
+		if got := getServerStorageClassConfig(); !reflect.DeepEqual(got, tt.wantResult) {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.wantResult, got)
+		}
+	}
+}
+
+func TestGetStorageClassLayouts(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testGetStorageClassLayouts)
+}
+
+func testGetStorageClassLayouts(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+	globalEndpoints = mustGetNewEndpointList(dirs...)
+	defer resetGlobalEndpoints()
+
+	globalStandardStorageClass = storageClass{Scheme: "EC", Parity: 6}
+	globalMaxStorageClass = storageClass{Scheme: "EC", Parity: 8}
+	expected := map[string]ServerStorageClassLayout{
+		standardStorageClass:          {"EC", 10, 6, storageClassSourceConfig},
+		reducedRedundancyStorageClass: {"", 14, 2, storageClassSourceDefault},
+		maxDurabilityStorageClass:     {"EC", 8, 8, storageClassSourceConfig},
+		scratchStorageClass:           {"", 14, 2, storageClassSourceDefault},
+	}
+	if got := getStorageClassLayouts(len(dirs)); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestListObjectsStorageClass(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testListObjectsStorageClass)
+}
+
+func testListObjectsStorageClass(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	// Set globalEndpoints for a single node XL setup.
+	globalEndpoints = mustGetNewEndpointList(dirs...)
+	defer resetGlobalEndpoints()
+	defer resetGlobalStorageEnvs()
+
+	bucket := getRandomBucketName()
+	if err := obj.MakeBucketWithLocation(bucket, globalMinioDefaultRegion); err != nil {
+		t.Fatalf("Failed to make a bucket %v", err)
+	}
+
+	data := bytes.Repeat([]byte("a"), 1024)
+	objects := map[string]map[string]string{
+		"object-1": nil,
+		"object-2": {amzStorageClass: standardStorageClass},
+		"object-3": {amzStorageClass: reducedRedundancyStorageClass},
+	}
+	for object, metadata := range objects {
+		if _, err := obj.PutObject(bucket, object, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), metadata); err != nil {
+			t.Fatalf("Failed to putObject %s %v", object, err)
+		}
+	}
+
+	result, err := obj.ListObjects(bucket, "", "", "", 10)
+	if err != nil {
+		t.Fatalf("Failed to list objects %v", err)
+	}
+	v1 := generateListObjectsV1Response(bucket, "", "", "", 10, result)
+	v2 := generateListObjectsV2Response(bucket, "", "", "", "", "", false, false, 10, result.Objects, result.Prefixes)
+
+	tests := []struct {
+		name          int
+		object        string
+		expectedClass string
+	}{
+		{1, "object-1", standardStorageClass},
+		{2, "object-2", standardStorageClass},
+		{3, "object-3", reducedRedundancyStorageClass},
+	}
+	if len(result.Objects) != len(tests) || len(v1.Contents) != len(tests) || len(v2.Contents) != len(tests) {
+		t.Fatalf("Expected %d objects, got %d", len(tests), len(result.Objects))
+	}
+	for i, tt := range tests {
+		if result.Objects[i].Name != tt.object {
+			t.Fatalf("Test %d, Expected object %s, got %s", tt.name, tt.object, result.Objects[i].Name)
+		}
+		if result.Objects[i].StorageClass != tt.expectedClass {
+			t.Errorf("Test %d, Expected storage class %s, got %s", tt.name, tt.expectedClass, result.Objects[i].StorageClass)
+		}
+		if v1.Contents[i].StorageClass != tt.expectedClass {
+			t.Errorf("Test %d, Expected storage class %s in ListObjects response, got %s", tt.name, tt.expectedClass, v1.Contents[i].StorageClass)
+		}
+		if v2.Contents[i].StorageClass != tt.expectedClass {
+			t.Errorf("Test %d, Expected storage class %s in ListObjectsV2 response, got %s", tt.name, tt.expectedClass, v2.Contents[i].StorageClass)
+		}
+	}
+
+	// Objects without storage class in xl.json are listed as Standard storage class.
+	if sc := newXLMetaV1("object", 8, 8).ToObjectInfo(bucket, "object").StorageClass; sc != standardStorageClass {
+		t.Errorf("Expected storage class %s, got %s", standardStorageClass, sc)
+	}
+}
+
+// Storage classes and disk counts getRedundancyCount is benchmarked with.
+var redundancyCountBenchmarks = []struct {
+	sc    string
+	disks int
+}{
+	{standardStorageClass, 4},
+	{standardStorageClass, 16},
+	{standardStorageClass, 64},
+	{reducedRedundancyStorageClass, 4},
+	{reducedRedundancyStorageClass, 16},
+	{reducedRedundancyStorageClass, 64},
+	{"UNKNOWN", 4},
+	{"UNKNOWN", 16},
+	{"UNKNOWN", 64},
+}
+
+// Sets both Standard and Reduced redundancy storage classes, so that
+// getRedundancyCount resolves configured parity rather than defaults.
+func setRedundancyCountBenchmarkEnvs() {
+	resetGlobalStorageEnvs()
+	globalStandardStorageClass = storageClass{Scheme: supportedStorageClassScheme, Parity: 2}
+	globalRRStorageClass = storageClass{Scheme: supportedStorageClassScheme, Parity: 1}
+}
+
+func BenchmarkGetRedundancyCount(b *testing.B) {
+	setRedundancyCountBenchmarkEnvs()
+	defer resetGlobalStorageEnvs()
+
+	for _, bb := range redundancyCountBenchmarks {
+		b.Run(fmt.Sprintf("%s-%d", bb.sc, bb.disks), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				getRedundancyCount(bb.sc, bb.disks)
+			}
+		})
+	}
+}
+
+// Tests that getRedundancyCount, called on every write, doesn't allocate.
+func TestGetRedundancyCountAllocs(t *testing.T) {
+	setRedundancyCountBenchmarkEnvs()
+	defer resetGlobalStorageEnvs()
+
+	for i, bb := range redundancyCountBenchmarks {
+		allocs := testing.AllocsPerRun(100, func() {
+			getRedundancyCount(bb.sc, bb.disks)
+		})
+		if allocs != 0 {
+			t.Errorf("Test %d, Expected 0 allocations for %s on %d disks, got %v", i+1, bb.sc, bb.disks, allocs)
+		}
+	}
+}
+
+func TestListObjectPartsStorageClass(t *testing.T) {
+	ExecObjectLayerTestWithDirs(t, testListObjectPartsStorageClass)
+}
+
+func testListObjectPartsStorageClass(obj ObjectLayer, instanceType string, dirs []string, t TestErrHandler) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+
+	bucket := getRandomBucketName()
+	if err := obj.MakeBucketWithLocation(bucket, globalMinioDefaultRegion); err != nil {
+		t.Fatalf("Failed to make a bucket %v", err)
+	}
+
+	tests := []struct {
+		name          int
+		metadata      map[string]string
+		expectedClass string
+	}{
+		{1, nil, standardStorageClass},
+		{2, map[string]string{amzStorageClass: reducedRedundancyStorageClass}, reducedRedundancyStorageClass},
+	}
+	for _, tt := range tests {
+		uploadID, err := obj.NewMultipartUpload(bucket, "object", tt.metadata)
+		if err != nil {
+			t.Fatalf("Test %d, Failed to create multipart upload %v", tt.name, err)
+		}
+		// Storage class is reported even if no parts are listed.
+		partsInfo, err := obj.ListObjectParts(bucket, "object", uploadID, 0, 0)
+		if err != nil {
+			t.Fatalf("Test %d, Failed to list parts %v", tt.name, err)
+		}
+		if partsInfo.StorageClass != tt.expectedClass {
+			t.Errorf("Test %d, Expected storage class %s, got %s", tt.name, tt.expectedClass, partsInfo.StorageClass)
+		}
+		if resp := generateListPartsResponse(partsInfo); resp.StorageClass != tt.expectedClass {
+			t.Errorf("Test %d, Expected storage class %s in ListParts response, got %s", tt.name, tt.expectedClass, resp.StorageClass)
+		}
+	}
+}
+
+// Tests that parity exceeding N/2 of the disks, e.g. after scale down,
+// is clamped only if MINIO_STORAGE_CLASS_CLAMP_PARITY is on.
+func TestClampRedundancyCount(t *testing.T) {
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+
+	// Storage classes validated against 16 disks.
+	globalStandardStorageClass = storageClass{Scheme: supportedStorageClassScheme, Parity: 8}
+	globalRRStorageClass = storageClass{Scheme: supportedStorageClassScheme, Parity: 3}
+
+	tests := []struct {
+		name           int
+		sc             string
+		disks          int
+		clamp          bool
+		expectedData   int
+		expectedParity int
+	}{
+		{1, standardStorageClass, 16, true, 8, 8},
+		// Without clamping the configured parity is laid out as is.
+		{2, standardStorageClass, 12, false, 4, 8},
+		{3, standardStorageClass, 12, true, 6, 6},
+		{4, reducedRedundancyStorageClass, 12, true, 9, 3},
+		// Clamped parity is never less than minimumParityDisks.
+		{5, reducedRedundancyStorageClass, 4, true, 2, 2},
+		{6, standardStorageClass, 3, true, 1, 2},
+	}
+	for _, tt := range tests {
+		globalStorageClassClampParity = tt.clamp
+		globalRedundancyCache.Invalidate()
+		info := getRedundancyCount(tt.sc, tt.disks)
+		if info.Data != tt.expectedData || info.Parity != tt.expectedParity {
+			t.Errorf("Test %d, Expected %d data and %d parity disks, got %d and %d", tt.name, tt.expectedData, tt.expectedParity, info.Data, info.Parity)
+		}
+	}
+}
+
+// Tests that clamped parity is warned about once per storage class and disks.
+func TestClampedParityWarnings(t *testing.T) {
+	w := newClampedParityWarnings()
+
+	tests := []struct {
+		name     int
+		sc       string
+		disks    int
+		expected bool
+	}{
+		{1, standardStorageClass, 12, true},
+		{2, standardStorageClass, 12, false},
+		// Other storage classes and disk counts are warned about separately.
+		{3, reducedRedundancyStorageClass, 12, true},
+		{4, standardStorageClass, 10, true},
+		{5, reducedRedundancyStorageClass, 12, false},
+	}
+	for _, tt := range tests {
+		if warn := w.Warn(tt.sc, tt.disks); warn != tt.expected {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expected, warn)
+		}
+	}
+
+	// Warned again after a reset.
+	w.Reset()
+	if !w.Warn(standardStorageClass, 12) {
+		t.Errorf("Expected a warning after reset")
+	}
+}
+
+// Test checkStorageClassWriteQuorum.
+func TestCheckStorageClassWriteQuorum(t *testing.T) {
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+
+	tests := []struct {
+		name          int
+		ssc           storageClass
+		rrsc          storageClass
+		maxsc         storageClass
+		disks         int
+		expectedError error
+	}{
+		{1, storageClass{}, storageClass{}, storageClass{}, 16, nil},
+		{2, storageClass{Scheme: "EC", Parity: 4}, storageClass{Scheme: "EC", Parity: 2}, storageClass{Scheme: "EC", Parity: 8}, 16, nil},
+		{3, storageClass{Scheme: "EC", Data: 12, Parity: 4}, storageClass{}, storageClass{}, 16, nil},
+		// Not erasure coded, nothing to validate.
+		{4, storageClass{Scheme: "EC", Data: 2, Parity: 0}, storageClass{}, storageClass{}, 2, nil},
+		// Parity is never negative once parsed, but may be in a corrupted config.json.
+		{5, storageClass{Scheme: "EC", Parity: -1}, storageClass{}, storageClass{}, 16,
+			errors.New("STANDARD storage class write quorum of 18 disks exceeds the 16 total disks, writes would always fail")},
+		{6, storageClass{}, storageClass{Scheme: "EC", Data: 9, Parity: -1}, storageClass{}, 8,
+			errors.New("REDUCED_REDUNDANCY storage class write quorum of 10 disks exceeds the 8 total disks, writes would always fail")},
+	}
+	for _, tt := range tests {
+		err := checkStorageClassWriteQuorum(tt.ssc, tt.rrsc, tt.maxsc, tt.disks)
+		if err == nil && tt.expectedError != nil {
+			t.Errorf("Test %d, Expected %s, got nil", tt.name, tt.expectedError)
+		}
+		if err != nil && tt.expectedError == nil {
+			t.Errorf("Test %d, Expected nil, got %s", tt.name, err)
+		}
+		if err != nil && tt.expectedError != nil && err.Error() != tt.expectedError.Error() {
+			t.Errorf("Test %d, Expected %s, got %s", tt.name, tt.expectedError, err)
+		}
+	}
+
+	// Parity strategy without parity disks is ignored, the storage
+	// classes are laid out as configured.
+	SetParityStrategy(fixedParityStrategy{parity: 0})
+	if err := checkStorageClassWriteQuorum(storageClass{}, storageClass{}, storageClass{}, 16); err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
 }
@@ -498,6 +498,24 @@ func resetGlobalIsEnvs() {
 func resetGlobalStorageEnvs() {
 	globalStandardStorageClass = storageClass{}
 	globalRRStorageClass = storageClass{}
+	globalMaxStorageClass = storageClass{}
+	globalStorageClassAliases = nil
+	globalStorageClassAllowSmall = false
+	globalStorageClassQuiet = false
+	globalStorageClassRequireEvenParity = false
+	globalRRSDefaultParity = defaultRRSParity
+	globalStorageClassMaxParity = 0
+	globalStorageClassJSONErrors = false
+	globalStorageClassAudit = false
+	globalStorageClassStrict = false
+	globalStorageClassClampParity = false
+	globalBucketStorageClassRules = nil
+	globalSmallObjectParityThresholds = nil
+	globalStorageClassHealReparity = false
+	globalStorageClassDenyRRSDefault = false
+	globalParityStrategy = DefaultParityStrategy{}
+	globalRedundancyCache.Invalidate()
+	globalClampedParityWarnings.Reset()
 }
 
 // Resets all the globals used modified in tests.
@@ -2095,6 +2113,9 @@ func registerBucketLevelFunc(bucket *router.Router, api objectAPIHandlers, apiFu
 		case "GetObject":
 			// Register GetObject handler.
 			bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectHandler)
+		case "PutObjectDryRun":
+			// Register PutObjectDryRun handler.
+			bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.PutObjectDryRunHandler).Queries("dryRun", "true")
 		case "PutObject":
 			// Register PutObject handler.
 			bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.PutObjectHandler)
@@ -935,10 +935,16 @@ type PresignedGetRep struct {
 	UIVersion string `json:"uiVersion"`
 	// Presigned URL of the object.
 	URL string `json:"url"`
+	// Storage class of the object, empty if the object doesn't exist.
+	StorageClass string `json:"storageClass,omitempty"`
 }
 
 // PresignedGET - returns presigned-Get url.
 func (web *webAPIHandlers) PresignedGet(r *http.Request, args *PresignedGetArgs, reply *PresignedGetRep) error {
+	objectAPI := web.ObjectAPI()
+	if objectAPI == nil {
+		return toJSONError(errServerNotInitialized)
+	}
 	if !isHTTPRequestValid(r) {
 		return toJSONError(errAuthentication)
 	}
@@ -950,6 +956,15 @@ func (web *webAPIHandlers) PresignedGet(r *http.Request, args *PresignedGetArgs,
 	}
 	reply.UIVersion = browser.UIVersion
 	reply.URL = presignedGet(args.HostName, args.BucketName, args.ObjectName, args.Expiry)
+
+	// Object may be created after the URL is presigned, the storage class
+	// is only advertised for an existing object.
+	if objInfo, err := objectAPI.GetObjectInfo(args.BucketName, args.ObjectName); err == nil {
+		reply.StorageClass = objInfo.StorageClass
+		if reply.StorageClass == "" {
+			reply.StorageClass = globalMinioDefaultStorageClass
+		}
+	}
 	return nil
 }
 
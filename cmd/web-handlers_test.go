@@ -1170,6 +1170,9 @@ func testWebPresignedGetHandler(obj ObjectLayer, instanceType string, t TestErrH
 	if err != nil {
 		t.Fatalf("Failed, %v", err)
 	}
+	if presignGetRep.StorageClass != globalMinioDefaultStorageClass {
+		t.Fatalf("Expected storage class %s, got %s", globalMinioDefaultStorageClass, presignGetRep.StorageClass)
+	}
 
 	// Register the API end points with XL/FS object layer.
 	apiRouter = initTestAPIEndPoints(obj, []string{"GetObject"})
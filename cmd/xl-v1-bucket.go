@@ -258,5 +258,9 @@ func (xl xlObjects) DeleteBucket(bucket string) error {
 	if errors.Cause(err) == errXLWriteQuorum {
 		xl.undoDeleteBucket(bucket)
 	}
+	if err == nil {
+		// A bucket created with the same name stores no bytes.
+		globalBucketQuotaUsage.Remove(bucket)
+	}
 	return toObjectErr(err, bucket)
 }
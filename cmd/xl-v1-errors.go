@@ -16,10 +16,39 @@
 
 package cmd
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	errors2 "github.com/minio/minio/pkg/errors"
+)
 
 // errXLReadQuorum - did not meet read quorum.
 var errXLReadQuorum = errors.New("Read failed. Insufficient number of disks online")
 
+// xlReadQuorumErr - errXLReadQuorum with the object which failed read
+// quorum, the count of valid metas found and the data blocks needed.
+type xlReadQuorumErr struct {
+	Bucket     string
+	Object     string
+	Available  int
+	DataBlocks int
+}
+
+func (e xlReadQuorumErr) Error() string {
+	return fmt.Sprintf("%s: %s/%s: found %d valid metas, need %d data blocks",
+		errXLReadQuorum, e.Bucket, e.Object, e.Available, e.DataBlocks)
+}
+
+// isErrXLReadQuorum - checks if err is errXLReadQuorum or
+// xlReadQuorumErr, possibly traced.
+func isErrXLReadQuorum(err error) bool {
+	err = errors2.Cause(err)
+	if _, ok := err.(xlReadQuorumErr); ok {
+		return true
+	}
+	return err == errXLReadQuorum
+}
+
 // errXLWriteQuorum - did not meet write quorum.
 var errXLWriteQuorum = errors.New("Write failed. Insufficient number of disks online")
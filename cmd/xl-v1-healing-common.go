@@ -121,27 +121,100 @@ func listOnlineDisks(disks []StorageAPI, partsMetadata []xlMetaV1, errs []error)
 	return onlineDisks, modTime
 }
 
-// Returns one of the latest updated xlMeta files and count of total valid xlMeta(s) updated latest
-func getLatestXLMeta(partsMetadata []xlMetaV1, errs []error) (xlMetaV1, int) {
+// Returns the index of the latest updated xlMeta and count of total valid
+// xlMeta(s) updated latest, index is -1 if there is no valid xlMeta. Of the
+// xlMeta(s) sharing the latest modTime, e.g. written by concurrent writes,
+// the one preferred by isPreferredXLMeta is picked, which doesn't depend on
+// the order of the disks the xlMeta(s) are read from. If none is preferred
+// the one on the lowest disk index is picked.
+func getLatestXLMetaIndex(partsMetadata []xlMetaV1, errs []error) (latestIndex, count int) {
 	// List all the file commit ids from parts metadata.
 	modTimes := listObjectModtimes(partsMetadata, errs)
 
-	// Count all lastest updated xlMeta values
-	var count int
-	var latestXLMeta xlMetaV1
-
 	// Reduce list of UUIDs to a single common value - i.e. the last updated Time
 	modTime, _ := commonTime(modTimes)
 
 	// Interate through all the modTimes and count the xlMeta(s) with latest time.
+	latestIndex = -1
 	for index, t := range modTimes {
 		if t == modTime && partsMetadata[index].IsValid() {
-			latestXLMeta = partsMetadata[index]
+			if latestIndex == -1 || isPreferredXLMeta(partsMetadata[index], partsMetadata[latestIndex]) {
+				latestIndex = index
+			}
 			count++
 		}
 	}
-	// Return one of the latest xlMetaData, and the count of lastest updated xlMeta files
-	return latestXLMeta, count
+	// Return the index of the latest xlMetaData, and the count of lastest updated xlMeta files
+	return latestIndex, count
+}
+
+// Tie breaker between two xlMeta(s) with the same modTime, returns true if
+// a is preferred over b. Lower erasure index is preferred, for equal erasure
+// index lower data blocks, i.e. the more durable layout, is preferred.
+func isPreferredXLMeta(a, b xlMetaV1) bool {
+	if a.Erasure.Index != b.Erasure.Index {
+		return a.Erasure.Index < b.Erasure.Index
+	}
+	if a.Erasure.DataBlocks != b.Erasure.DataBlocks {
+		return a.Erasure.DataBlocks < b.Erasure.DataBlocks
+	}
+	return a.Erasure.ParityBlocks < b.Erasure.ParityBlocks
+}
+
+// checkErasureConsistency - returns the indices of the xl.json(s) whose data
+// and parity blocks disagree with the layout most of the latest xl.json(s)
+// agree on, e.g. written across a storage class parity change. Layouts held
+// by as many xl.json(s) are tie broken by isPreferredXLMeta, so that a single
+// deviant xl.json never turns the healthy ones into conflicts whatever disk
+// it is on. Shards of these disks can't be decoded along with the others and
+// need to be healed. Invalid and older xl.json(s) are not reported, they are
+// healed as outdated anyway. Returns nil if all the latest xl.json(s) agree.
+func checkErasureConsistency(partsMetaData []xlMetaV1) (conflicts []int) {
+	errs := make([]error, len(partsMetaData))
+	for index, meta := range partsMetaData {
+		if !meta.IsValid() {
+			errs[index] = errCorruptedFormat
+		}
+	}
+	modTime, _ := commonTime(listObjectModtimes(partsMetaData, errs))
+
+	// Count the latest xl.json(s) per data and parity blocks, along
+	// with the preferred xl.json of each layout for tie breaks.
+	type erasureLayout struct {
+		dataBlocks, parityBlocks int
+	}
+	counts := make(map[erasureLayout]int)
+	preferred := make(map[erasureLayout]xlMetaV1)
+	for index, meta := range partsMetaData {
+		if errs[index] != nil || !meta.Stat.ModTime.Equal(modTime) {
+			continue
+		}
+		layout := erasureLayout{meta.Erasure.DataBlocks, meta.Erasure.ParityBlocks}
+		if counts[layout] == 0 || isPreferredXLMeta(meta, preferred[layout]) {
+			preferred[layout] = meta
+		}
+		counts[layout]++
+	}
+	if len(counts) < 2 {
+		return nil
+	}
+
+	var reference erasureLayout
+	maxCount := 0
+	for layout, count := range counts {
+		if count > maxCount || (count == maxCount && isPreferredXLMeta(preferred[layout], preferred[reference])) {
+			reference, maxCount = layout, count
+		}
+	}
+	for index, meta := range partsMetaData {
+		if errs[index] != nil || !meta.Stat.ModTime.Equal(modTime) {
+			continue
+		}
+		if meta.Erasure.DataBlocks != reference.dataBlocks || meta.Erasure.ParityBlocks != reference.parityBlocks {
+			conflicts = append(conflicts, index)
+		}
+	}
+	return conflicts
 }
 
 // outDatedDisks - return disks which don't have the latest object (i.e xl.json).
@@ -197,7 +270,8 @@ func xlShouldHeal(disks []StorageAPI, partsMetadata []xlMetaV1, errs []error, bu
 		}
 	}
 
-	return false
+	// Return true if xl.json(s) disagree on the data and parity blocks.
+	return len(checkErasureConsistency(partsMetadata)) > 0
 }
 
 // xlHealStat - returns a structure which describes how many data,
@@ -208,10 +282,19 @@ func xlHealStat(xl xlObjects, partsMetadata []xlMetaV1, errs []error) HealObject
 	// This object can't be healed with the information we have.
 	modTime, count := commonTime(listObjectModtimes(partsMetadata, errs))
 
+	// Disks which are offline can't be healed, so the object stays
+	// under-protected until they are back.
+	onlineDisks := len(errs)
+	for _, err := range errs {
+		if errors.Cause(err) == errDiskNotFound {
+			onlineDisks--
+		}
+	}
+
 	// get read quorum for this object
-	readQuorum, _, err := objectQuorumFromMeta(xl, partsMetadata, errs)
+	qInfo, fullRedundancy, err := objectRedundancyFromMeta(xl, partsMetadata, errs, onlineDisks)
 
-	if count < readQuorum || err != nil {
+	if count < qInfo.ReadQuorum || err != nil {
 		return HealObjectInfo{
 			Status:             quorumUnavailable,
 			MissingDataCount:   0,
@@ -234,16 +317,12 @@ func xlHealStat(xl xlObjects, partsMetadata []xlMetaV1, errs []error) HealObject
 	missingDataCount := 0
 	missingParityCount := 0
 
-	disksMissing := false
 	for i, err := range errs {
 		// xl.json is not found, which implies the erasure
 		// coded blocks are unavailable in the corresponding disk.
 		// First half of the disks are data and the rest are parity.
 		switch realErr := errors.Cause(err); realErr {
-		case errDiskNotFound:
-			disksMissing = true
-			fallthrough
-		case errFileNotFound:
+		case errDiskNotFound, errFileNotFound:
 			if xlMeta.Erasure.Distribution[i]-1 < xlMeta.Erasure.DataBlocks {
 				missingDataCount++
 			} else {
@@ -254,11 +333,12 @@ func xlHealStat(xl xlObjects, partsMetadata []xlMetaV1, errs []error) HealObject
 
 	// The object may not be healed completely, since some of the
 	// disks needing healing are unavailable.
-	if disksMissing {
+	if !fullRedundancy {
 		return HealObjectInfo{
 			Status:             canPartiallyHeal,
 			MissingDataCount:   missingDataCount,
 			MissingParityCount: missingParityCount,
+			HealPriority:       qInfo.HealPriority(),
 		}
 	}
 
@@ -268,6 +348,7 @@ func xlHealStat(xl xlObjects, partsMetadata []xlMetaV1, errs []error) HealObject
 		Status:             canHeal,
 		MissingDataCount:   missingDataCount,
 		MissingParityCount: missingParityCount,
+		HealPriority:       qInfo.HealPriority(),
 	}
 }
 
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -439,3 +440,97 @@ func TestDisksWithAllParts(t *testing.T) {
 	}
 
 }
+
+// Tests the latest xl.json is picked deterministically among
+// xl.json(s) with the same modTime.
+func TestGetLatestXLMetaIndex(t *testing.T) {
+	modTime := time.Unix(1500000000, 0).UTC()
+	newMeta := func(index, dataBlocks, parityBlocks int, modTime time.Time) xlMetaV1 {
+		meta := newXLMetaV1("object", dataBlocks, parityBlocks)
+		meta.Erasure.Index = index
+		meta.Stat.ModTime = modTime
+		return meta
+	}
+
+	tests := []struct {
+		name          int
+		metas         []xlMetaV1
+		errs          []error
+		expectedIndex int
+		expectedCount int
+	}{
+		// Lowest erasure index wins irrespective of disk order.
+		{1, []xlMetaV1{newMeta(3, 2, 2, modTime), newMeta(1, 2, 2, modTime), newMeta(2, 2, 2, modTime), newMeta(4, 2, 2, modTime)},
+			[]error{nil, nil, nil, nil}, 1, 4},
+		// Concurrent writes with the same erasure index, more durable layout wins.
+		{2, []xlMetaV1{newMeta(1, 3, 1, modTime), newMeta(1, 2, 2, modTime), newMeta(2, 3, 1, modTime), newMeta(2, 2, 2, modTime)},
+			[]error{nil, nil, nil, nil}, 1, 4},
+		{3, []xlMetaV1{newMeta(1, 2, 2, modTime), newMeta(1, 3, 1, modTime), newMeta(2, 2, 2, modTime), newMeta(2, 3, 1, modTime)},
+			[]error{nil, nil, nil, nil}, 0, 4},
+		// Identical xl.json(s), the first disk wins.
+		{4, []xlMetaV1{newMeta(1, 2, 2, modTime), newMeta(1, 2, 2, modTime), {}, newMeta(1, 2, 2, modTime)},
+			[]error{nil, nil, errDiskNotFound, nil}, 0, 3},
+		// Older xl.json is not picked even with a lower erasure index.
+		{5, []xlMetaV1{newMeta(1, 2, 2, modTime.Add(-time.Hour)), newMeta(2, 2, 2, modTime), newMeta(3, 2, 2, modTime), newMeta(4, 2, 2, modTime)},
+			[]error{nil, nil, nil, nil}, 1, 3},
+		// No valid xl.json.
+		{6, []xlMetaV1{{}, {}, {}, {}},
+			[]error{errDiskNotFound, errDiskNotFound, errDiskNotFound, errDiskNotFound}, -1, 0},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 3; i++ {
+			index, count := getLatestXLMetaIndex(tt.metas, tt.errs)
+			if index != tt.expectedIndex || count != tt.expectedCount {
+				t.Errorf("Test %d, Expected index %d and count %d, got %d and %d",
+					tt.name, tt.expectedIndex, tt.expectedCount, index, count)
+			}
+		}
+	}
+
+	// Same xl.json is picked for any order of the disks.
+	metas := []xlMetaV1{newMeta(2, 3, 1, modTime), newMeta(1, 3, 1, modTime), newMeta(1, 2, 2, modTime), newMeta(2, 2, 2, modTime)}
+	errs := make([]error, len(metas))
+	index, _ := getLatestXLMetaIndex(metas, errs)
+	expected := metas[index]
+	for i := range metas {
+		rotated := append(append([]xlMetaV1{}, metas[i:]...), metas[:i]...)
+		if index, _ = getLatestXLMetaIndex(rotated, errs); !reflect.DeepEqual(rotated[index], expected) {
+			t.Errorf("Rotation %d, Expected %v, got %v", i, expected.Erasure, rotated[index].Erasure)
+		}
+	}
+}
+
+func TestCheckErasureConsistency(t *testing.T) {
+	modTime := time.Unix(1500000000, 0).UTC()
+	newMeta := func(index, dataBlocks, parityBlocks int, modTime time.Time) xlMetaV1 {
+		meta := newXLMetaV1("object", dataBlocks, parityBlocks)
+		meta.Erasure.Index = index
+		meta.Stat.ModTime = modTime
+		return meta
+	}
+
+	tests := []struct {
+		name              int
+		metas             []xlMetaV1
+		expectedConflicts []int
+	}{
+		// All xl.json(s) agree.
+		{1, []xlMetaV1{newMeta(1, 2, 2, modTime), newMeta(2, 2, 2, modTime), newMeta(3, 2, 2, modTime), newMeta(4, 2, 2, modTime)}, nil},
+		// Layouts held by as many xl.json(s), the one of the xl.json
+		// with the lowest erasure index is the reference.
+		{2, []xlMetaV1{newMeta(2, 3, 1, modTime), newMeta(1, 2, 2, modTime), newMeta(3, 2, 2, modTime), newMeta(4, 3, 1, modTime)}, []int{0, 3}},
+		// Layout most xl.json(s) agree on is the reference, even if the
+		// deviant xl.json has the lowest erasure index.
+		{3, []xlMetaV1{newMeta(1, 3, 1, modTime), newMeta(2, 2, 2, modTime), newMeta(3, 2, 2, modTime), newMeta(4, 2, 2, modTime)}, []int{0}},
+		{4, []xlMetaV1{newMeta(4, 2, 2, modTime), newMeta(3, 2, 2, modTime), newMeta(2, 3, 1, modTime), newMeta(1, 2, 2, modTime)}, []int{2}},
+		// Missing and older xl.json(s) are not reported.
+		{5, []xlMetaV1{newMeta(1, 2, 2, modTime), {}, newMeta(3, 3, 1, modTime.Add(-time.Hour)), newMeta(4, 2, 2, modTime)}, nil},
+		// No valid xl.json.
+		{6, []xlMetaV1{{}, {}, {}, {}}, nil},
+	}
+	for _, tt := range tests {
+		if conflicts := checkErasureConsistency(tt.metas); !reflect.DeepEqual(conflicts, tt.expectedConflicts) {
+			t.Errorf("Test %d, Expected conflicts %v, got %v", tt.name, tt.expectedConflicts, conflicts)
+		}
+	}
+}
@@ -17,7 +17,9 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"path"
 	"sort"
 	"sync"
@@ -141,7 +143,8 @@ func healBucket(storageDisks []StorageAPI, bucket string, writeQuorum int) error
 }
 
 // Heals all the metadata associated for a given bucket, this function
-// heals `policy.json`, `notification.xml` and `listeners.json`.
+// heals `policy.json`, `notification.xml`, `listeners.json` and
+// `storageclass.json`.
 func healBucketMetadata(xlObj xlObjects, bucket string) error {
 	healBucketMetaFn := func(metaPath string) error {
 		if _, _, err := xlObj.HealObject(minioMetaBucket, metaPath); err != nil && !isErrObjectNotFound(err) {
@@ -164,7 +167,13 @@ func healBucketMetadata(xlObj xlObjects, bucket string) error {
 
 	// Heal `listeners.json` for missing entries, ignores if `listeners.json` is not found.
 	lConfigPath := path.Join(bucketConfigPrefix, bucket, bucketListenerConfig)
-	return healBucketMetaFn(lConfigPath)
+	if err := healBucketMetaFn(lConfigPath); err != nil {
+		return err
+	}
+
+	// Heal `storageclass.json` for missing entries, ignores if `storageclass.json` is not found.
+	scConfigPath := path.Join(bucketConfigPrefix, bucket, bucketStorageClassConfigFile)
+	return healBucketMetaFn(scConfigPath)
 }
 
 // listAllBuckets lists all buckets from all disks. It also
@@ -354,6 +363,16 @@ func healObject(storageDisks []StorageAPI, bucket, object string, quorum int) (i
 		return 0, 0, toObjectErr(aErr, bucket, object)
 	}
 
+	// Disks whose xl.json disagrees with the data and parity blocks of
+	// the latest xl.json are healed as outdated disks, their shards are
+	// neither used to heal nor is their xl.json picked as reference.
+	for _, index := range checkErasureConsistency(partsMetadata) {
+		latestDisks[index] = nil
+		availableDisks[index] = nil
+		errs[index] = errFileNotFound
+		partsMetadata[index] = xlMetaV1{}
+	}
+
 	// Number of disks which don't serve data.
 	numOfflineDisks := 0
 	for index, disk := range storageDisks {
@@ -536,7 +555,7 @@ func (xl xlObjects) HealObject(bucket, object string) (int, int, error) {
 	partsMetadata, errs := readAllXLMetadata(xl.storageDisks, bucket, object)
 
 	// get read quorum for this object
-	readQuorum, _, err := objectQuorumFromMeta(xl, partsMetadata, errs)
+	readQuorum, _, err := objectQuorumFromMeta(context.Background(), xl, bucket, object, partsMetadata, errs)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -546,8 +565,113 @@ func (xl xlObjects) HealObject(bucket, object string) (int, int, error) {
 	if err := objectLock.GetRLock(globalHealingTimeout); err != nil {
 		return 0, 0, err
 	}
-	defer objectLock.RUnlock()
 
 	// Heal the object.
-	return healObject(xl.storageDisks, bucket, object, readQuorum)
+	numOfflineDisks, numHealedDisks, err := healObject(xl.storageDisks, bucket, object, readQuorum)
+	objectLock.RUnlock()
+	if err != nil || !globalStorageClassHealReparity || isMinioMetaBucketName(bucket) {
+		return numOfflineDisks, numHealedDisks, err
+	}
+
+	// Re-encode the healed object with the current parity of its storage class.
+	if err = xl.healObjectParity(bucket, object); err != nil {
+		return numOfflineDisks, numHealedDisks, err
+	}
+	return numOfflineDisks, numHealedDisks, nil
+}
+
+// Re-encodes an object with the current parity of its storage class,
+// objects already at that parity are skipped, see getHealRedundancyInfo.
+// The parts, object metadata and modification time are kept as is, only
+// the erasure layout changes.
+func (xl xlObjects) healObjectParity(bucket, object string) error {
+	// Lock the object before re-encoding.
+	objectLock := globalNSMutex.NewNSLock(bucket, object)
+	if err := objectLock.GetLock(globalHealingTimeout); err != nil {
+		return err
+	}
+	defer objectLock.Unlock()
+
+	partsMetadata, errs := readAllXLMetadata(xl.storageDisks, bucket, object)
+	readQuorum, _, err := objectQuorumFromMeta(context.Background(), xl, bucket, object, partsMetadata, errs)
+	if err != nil {
+		return err
+	}
+	if rErr := reduceReadQuorumErrs(errs, nil, readQuorum); rErr != nil {
+		return toObjectErr(rErr, bucket, object)
+	}
+	_, modTime := listOnlineDisks(xl.storageDisks, partsMetadata, errs)
+	latestMeta, err := pickValidXLMeta(partsMetadata, modTime)
+	if err != nil {
+		return toObjectErr(err, bucket, object)
+	}
+
+	scInfo, metadata, reencode := getHealRedundancyInfo(latestMeta, xl.setDriveCount())
+	if !reencode {
+		return nil
+	}
+	_, writeQuorum := quorumFromDataBlocks(scInfo.Class, scInfo.Data)
+	if err = checkOnlineWriteQuorum(scInfo.Class, writeQuorum, countOnlineDisks(xl.storageDisks)); err != nil {
+		return toObjectErr(errors.Trace(err), bucket, object)
+	}
+
+	xlMeta := newXLMetaV1(object, scInfo.Data, scInfo.Parity)
+	xlMeta.Meta = metadata
+	xlMeta.Stat = latestMeta.Stat
+	partsMetadata = make([]xlMetaV1, len(xl.storageDisks))
+	for index := range partsMetadata {
+		partsMetadata[index] = xlMeta
+	}
+
+	// Order disks according to the new erasure distribution.
+	onlineDisks := shuffleDisks(xl.storageDisks, xlMeta.Erasure.Distribution)
+	storage, err := NewErasureStorage(onlineDisks, xlMeta.Erasure.DataBlocks, xlMeta.Erasure.ParityBlocks, xlMeta.Erasure.BlockSize)
+	if err != nil {
+		return toObjectErr(err, bucket, object)
+	}
+
+	// We write at temporary location and then rename to final location.
+	tmpID := mustGetUUID()
+	defer xl.deleteObject(minioMetaTmpBucket, tmpID)
+
+	// Read the object while its parts are re-encoded.
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(xl.GetObject(bucket, object, 0, latestMeta.Stat.Size, pw))
+	}()
+	defer pr.Close()
+
+	buffer := make([]byte, xlMeta.Erasure.BlockSize, 2*xlMeta.Erasure.BlockSize)
+	for _, part := range latestMeta.Parts {
+		file, cErr := storage.CreateFile(io.LimitReader(pr, part.Size), minioMetaTmpBucket,
+			pathJoin(tmpID, part.Name), buffer, DefaultBitrotAlgorithm, writeQuorum)
+		if cErr != nil {
+			return toObjectErr(cErr, bucket, object)
+		}
+		if file.Size < part.Size {
+			return errors.Trace(IncompleteBody{})
+		}
+		for index := range partsMetadata {
+			partsMetadata[index].AddObjectPart(part.Number, part.Name, part.ETag, part.Size)
+			partsMetadata[index].Erasure.AddChecksumInfo(ChecksumInfo{part.Name, file.Algorithm, file.Checksums[index]})
+		}
+	}
+
+	if onlineDisks, err = writeUniqueXLMetadata(onlineDisks, minioMetaTmpBucket, tmpID, partsMetadata, writeQuorum); err != nil {
+		return toObjectErr(err, bucket, object)
+	}
+
+	// Move the object being replaced aside, it is purged regardless
+	// of its `xl.json` status.
+	oldID := mustGetUUID()
+	defer xl.deleteObject(minioMetaTmpBucket, oldID)
+	if _, err = renameObject(xl.storageDisks, bucket, object, minioMetaTmpBucket, oldID, writeQuorum); err != nil {
+		return toObjectErr(err, bucket, object)
+	}
+
+	// Rename the re-encoded object to final location.
+	if _, err = renameObject(onlineDisks, minioMetaTmpBucket, tmpID, bucket, object, writeQuorum); err != nil {
+		return toObjectErr(err, bucket, object)
+	}
+	return nil
 }
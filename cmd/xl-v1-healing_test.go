@@ -538,7 +538,205 @@ func TestHealObjectXL(t *testing.T) {
 	// Try healing now, expect to receive errDiskNotFound.
 	_, _, err = obj.HealObject(bucket, object)
 	// since majority of xl.jsons are not available, object quorum can't be read properly and error will be errXLReadQuorum
-	if errors.Cause(err) != errXLReadQuorum {
-		t.Errorf("Expected %v but received %v", errDiskNotFound, err)
+	if !isErrXLReadQuorum(err) {
+		t.Errorf("Expected %v but received %v", errXLReadQuorum, err)
 	}
 }
+
+// Tests xl.json(s) disagreeing on the data and parity blocks are healed.
+func TestHealObjectXLErasureConsistency(t *testing.T) {
+	root, err := newTestConfig(globalMinioDefaultRegion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	nDisks := 16
+	fsDirs, err := getRandomDisks(nDisks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	obj, _, err := initObjectLayer(mustGetNewEndpointList(fsDirs...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bucket := "bucket"
+	data := bytes.Repeat([]byte("a"), 1024*1024)
+	if err = obj.MakeBucketWithLocation(bucket, ""); err != nil {
+		t.Fatalf("Failed to make a bucket - %v", err)
+	}
+
+	// Rewrite xl.json of a disk with a different layout, the disk holding
+	// erasure index 1 is healed as any other disk.
+	xl := obj.(*xlObjects)
+	for _, object := range []string{"object", "object-index-1"} {
+		if _, err = obj.PutObject(bucket, object, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), nil); err != nil {
+			t.Fatalf("Failed to put an object - %v", err)
+		}
+
+		var disk StorageAPI
+		var xlMeta xlMetaV1
+		for _, disk = range xl.storageDisks {
+			if xlMeta, err = readXLMeta(disk, bucket, object); err != nil {
+				t.Fatalf("Failed to read xl.json - %v", err)
+			}
+			if (xlMeta.Erasure.Index == 1) == (object == "object-index-1") {
+				break
+			}
+		}
+		dataBlocks := xlMeta.Erasure.DataBlocks
+		xlMeta.Erasure.DataBlocks, xlMeta.Erasure.ParityBlocks = dataBlocks+2, xlMeta.Erasure.ParityBlocks-2
+		if err = disk.DeleteFile(bucket, filepath.Join(object, xlMetaJSONFile)); err != nil {
+			t.Fatalf("Failed to delete a file - %v", err)
+		}
+		if err = writeXLMetadata(disk, bucket, object, xlMeta); err != nil {
+			t.Fatalf("Failed to write xl.json - %v", err)
+		}
+
+		partsMetadata, errs := readAllXLMetadata(xl.storageDisks, bucket, object)
+		if !xlShouldHeal(xl.storageDisks, partsMetadata, errs, bucket, object) {
+			t.Fatalf("%s: Expected object with inconsistent xl.json to need healing", object)
+		}
+
+		_, numHealedDisks, err := obj.HealObject(bucket, object)
+		if err != nil {
+			t.Fatalf("%s: Failed to heal object - %v", object, err)
+		}
+		if numHealedDisks != 1 {
+			t.Errorf("%s: Expected 1 healed disk, got %d", object, numHealedDisks)
+		}
+		if xlMeta, err = readXLMeta(disk, bucket, object); err != nil {
+			t.Fatalf("Failed to read xl.json - %v", err)
+		}
+		if xlMeta.Erasure.DataBlocks != dataBlocks {
+			t.Errorf("%s: Expected %d data blocks after heal, got %d", object, dataBlocks, xlMeta.Erasure.DataBlocks)
+		}
+
+		partsMetadata, errs = readAllXLMetadata(xl.storageDisks, bucket, object)
+		if xlShouldHeal(xl.storageDisks, partsMetadata, errs, bucket, object) {
+			t.Errorf("%s: Expected healed object to not need healing", object)
+		}
+		var buf bytes.Buffer
+		if err = obj.GetObject(bucket, object, 0, int64(len(data)), &buf); err != nil {
+			t.Fatalf("%s: Failed to get healed object - %v", object, err)
+		}
+		if !bytes.Equal(buf.Bytes(), data) {
+			t.Errorf("%s: Healed object content mismatch", object)
+		}
+	}
+}
+
+// Tests healed objects are re-encoded with the current parity of their
+// storage class only if globalStorageClassHealReparity is set.
+func TestHealObjectXLReparity(t *testing.T) {
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+
+	root, err := newTestConfig(globalMinioDefaultRegion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	nDisks := 16
+	fsDirs, err := getRandomDisks(nDisks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	obj, _, err := initObjectLayer(mustGetNewEndpointList(fsDirs...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	xl := obj.(*xlObjects)
+
+	bucket := "bucket"
+	object := "object"
+	data := bytes.Repeat([]byte("a"), 5*1024*1024)
+
+	if err = obj.MakeBucketWithLocation(bucket, ""); err != nil {
+		t.Fatalf("Failed to make a bucket - %v", err)
+	}
+
+	// Create a multipart object with reduced redundancy, parity 2.
+	uploadID, err := obj.NewMultipartUpload(bucket, object, map[string]string{amzStorageClass: reducedRedundancyStorageClass})
+	if err != nil {
+		t.Fatalf("Failed to create a multipart upload - %v", err)
+	}
+	var uploadedParts []CompletePart
+	for _, partID := range []int{1, 2} {
+		pInfo, err1 := obj.PutObjectPart(bucket, object, uploadID, partID, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""))
+		if err1 != nil {
+			t.Fatalf("Failed to upload a part - %v", err1)
+		}
+		uploadedParts = append(uploadedParts, CompletePart{
+			PartNumber: pInfo.PartNumber,
+			ETag:       pInfo.ETag,
+		})
+	}
+	objInfo, err := obj.CompleteMultipartUpload(bucket, object, uploadID, uploadedParts)
+	if err != nil {
+		t.Fatalf("Failed to complete multipart upload - %v", err)
+	}
+
+	checkParity := func(expected int) {
+		metas, _ := readAllXLMetadata(xl.storageDisks, bucket, object)
+		for i, meta := range metas {
+			if meta.Erasure.ParityBlocks != expected {
+				t.Fatalf("Disk %d, Expected parity %d, got %d", i, expected, meta.Erasure.ParityBlocks)
+			}
+			if meta.Erasure.DataBlocks != nDisks-expected {
+				t.Fatalf("Disk %d, Expected data %d, got %d", i, nDisks-expected, meta.Erasure.DataBlocks)
+			}
+		}
+	}
+	checkParity(2)
+
+	// Reduced redundancy storage class parity is raised to 4.
+	globalRRStorageClass = storageClass{Scheme: supportedStorageClassScheme, Parity: 4}
+	globalRedundancyCache.Invalidate()
+
+	// Healed objects keep their parity unless opted in.
+	if _, _, err = obj.HealObject(bucket, object); err != nil {
+		t.Fatalf("Failed to heal object - %v", err)
+	}
+	checkParity(2)
+
+	globalStorageClassHealReparity = true
+	if _, _, err = obj.HealObject(bucket, object); err != nil {
+		t.Fatalf("Failed to heal object - %v", err)
+	}
+	checkParity(4)
+
+	// Object is re-encoded with its parts, ETag and content as is.
+	healedInfo, err := obj.GetObjectInfo(bucket, object)
+	if err != nil {
+		t.Fatalf("Failed to get object info - %v", err)
+	}
+	if healedInfo.ETag != objInfo.ETag {
+		t.Errorf("Expected ETag %s, got %s", objInfo.ETag, healedInfo.ETag)
+	}
+	if !healedInfo.ModTime.Equal(objInfo.ModTime) {
+		t.Errorf("Expected modification time %s, got %s", objInfo.ModTime, healedInfo.ModTime)
+	}
+	if healedInfo.StorageClass != reducedRedundancyStorageClass {
+		t.Errorf("Expected storage class %s, got %s", reducedRedundancyStorageClass, healedInfo.StorageClass)
+	}
+	var buf bytes.Buffer
+	if err = obj.GetObject(bucket, object, 0, healedInfo.Size, &buf); err != nil {
+		t.Fatalf("Failed to read object - %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), append(data, data...)) {
+		t.Errorf("Expected re-encoded object content to be unchanged")
+	}
+
+	// Objects already at the target parity are skipped.
+	if _, _, err = obj.HealObject(bucket, object); err != nil {
+		t.Fatalf("Failed to heal object - %v", err)
+	}
+	checkParity(4)
+}
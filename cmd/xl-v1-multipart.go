@@ -17,6 +17,7 @@
 package cmd
 
 import (
+	"context"
 	"encoding/hex"
 	"fmt"
 	"io"
@@ -500,14 +501,22 @@ func (xl xlObjects) ListMultipartUploads(bucket, object, keyMarker, uploadIDMark
 // disks. `uploads.json` carries metadata regarding on-going multipart
 // operation(s) on the object.
 func (xl xlObjects) newMultipartUpload(bucket string, object string, meta map[string]string) (string, error) {
+	// Objects without storage class are written with the bucket default storage class.
+	requestedClass := meta[amzStorageClass]
+	setBucketDefaultStorageClass(bucket, meta)
 
-	dataBlocks, parityBlocks := getRedundancyCount(meta[amzStorageClass], len(xl.storageDisks))
+	scInfo, err := getObjectRedundancyInfo(bucket, meta, xl.setDriveCount(), -1)
+	if err != nil {
+		return "", toObjectErr(errors.Trace(err), bucket, object)
+	}
+	dataBlocks, parityBlocks := scInfo.Data, scInfo.Parity
+	auditStorageClass(bucket, object, requestedClass, scInfo)
 
 	xlMeta := newXLMetaV1(object, dataBlocks, parityBlocks)
 
 	// we now know the number of blocks this object needs for data and parity.
 	// establish the writeQuorum using this data
-	writeQuorum := dataBlocks + 1
+	_, writeQuorum := quorumFromDataBlocks(meta[amzStorageClass], dataBlocks)
 
 	// If not set default to "application/octet-stream"
 	if meta["content-type"] == "" {
@@ -652,7 +661,7 @@ func (xl xlObjects) PutObjectPart(bucket, object, uploadID string, partID int, d
 		uploadIDPath)
 
 	// get Quorum for this object
-	_, writeQuorum, err := objectQuorumFromMeta(xl, partsMetadata, errs)
+	_, writeQuorum, err := objectQuorumFromMeta(context.Background(), xl, bucket, object, partsMetadata, errs)
 	if err != nil {
 		return pi, toObjectErr(err, bucket, object)
 	}
@@ -792,7 +801,7 @@ func (xl xlObjects) listObjectParts(bucket, object, uploadID string, partNumberM
 
 	uploadIDPath := path.Join(bucket, object, uploadID)
 
-	xlParts, err := xl.readXLMetaParts(minioMetaMultipartBucket, uploadIDPath)
+	xlParts, xlMetaMap, err := xl.readXLMetaParts(minioMetaMultipartBucket, uploadIDPath)
 	if err != nil {
 		return lpi, toObjectErr(err, minioMetaMultipartBucket, uploadIDPath)
 	}
@@ -801,6 +810,7 @@ func (xl xlObjects) listObjectParts(bucket, object, uploadID string, partNumberM
 	result.Bucket = bucket
 	result.Object = object
 	result.UploadID = uploadID
+	result.StorageClass = getObjectStorageClass(xlMetaMap)
 	result.MaxParts = maxParts
 
 	// For empty number of parts or maxParts as zero, return right here.
@@ -922,7 +932,7 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 	partsMetadata, errs := readAllXLMetadata(xl.storageDisks, minioMetaMultipartBucket, uploadIDPath)
 
 	// get Quorum for this object
-	_, writeQuorum, err := objectQuorumFromMeta(xl, partsMetadata, errs)
+	_, writeQuorum, err := objectQuorumFromMeta(context.Background(), xl, bucket, object, partsMetadata, errs)
 	if err != nil {
 		return oi, toObjectErr(err, bucket, object)
 	}
@@ -996,6 +1006,26 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 		}
 	}
 
+	// Object overwritten by the upload, only looked up for buckets with
+	// a storage class quota to account the bytes freed by the upload.
+	var oldObjInfo ObjectInfo
+	if hasBucketStorageClassQuota(bucket) {
+		if objInfo, oErr := xl.getObjectInfo(bucket, object); oErr == nil {
+			oldObjInfo = objInfo
+		}
+	}
+
+	// Reject the object if it exceeds the bucket quota of its storage class.
+	if err = checkBucketStorageClassQuota(xl, bucket, getObjectStorageClass(xlMeta.Meta), objectSize, oldObjInfo); err != nil {
+		return oi, toObjectErr(errors.Trace(err), bucket, object)
+	}
+
+	// Reject overwriting the object with a less durable storage class, if the bucket protects against it.
+	if err = xl.checkStorageClassDowngrade(bucket, object, getObjectStorageClass(xlMeta.Meta),
+		popForceDowngrade(xlMeta.Meta)); err != nil {
+		return oi, toObjectErr(errors.Trace(err), bucket, object)
+	}
+
 	// Save the final object size and modtime.
 	xlMeta.Stat.Size = objectSize
 	xlMeta.Stat.ModTime = UTCNow()
@@ -1038,17 +1068,23 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 		}
 	}()
 
+	// Object overwritten by the upload, deleted once the upload is done.
+	var oldUniqueID string
 	if xl.isObject(bucket, object) {
 		// Rename if an object already exists to temporary location.
-		newUniqueID := mustGetUUID()
+		oldUniqueID = mustGetUUID()
 
-		// Delete success renamed object.
-		defer xl.deleteObject(minioMetaTmpBucket, newUniqueID)
+		// Delete success renamed object if the upload fails.
+		defer func() {
+			if oldUniqueID != "" {
+				xl.deleteObject(minioMetaTmpBucket, oldUniqueID)
+			}
+		}()
 
 		// NOTE: Do not use online disks slice here.
 		// The reason is that existing object should be purged
 		// regardless of `xl.json` status and rolled back in case of errors.
-		_, err = renameObject(xl.storageDisks, bucket, object, minioMetaTmpBucket, newUniqueID, writeQuorum)
+		_, err = renameObject(xl.storageDisks, bucket, object, minioMetaTmpBucket, oldUniqueID, writeQuorum)
 		if err != nil {
 			return oi, toObjectErr(err, bucket, object)
 		}
@@ -1087,6 +1123,21 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 		return oi, toObjectErr(err, minioMetaMultipartBucket, path.Join(bucket, object))
 	}
 
+	// Delete the overwritten object, its `xl.json` is no longer accounted.
+	var oldXLMeta xlMetaV1
+	if oldUniqueID != "" {
+		oldXLMeta, _ = xl.deleteObject(minioMetaTmpBucket, oldUniqueID)
+		oldUniqueID = ""
+	}
+
+	// Account the object against its storage class.
+	if oldXLMeta.IsValid() {
+		globalStorageClassStats.overwriteStats(oldXLMeta.Meta[amzStorageClass], oldXLMeta.Stat.Size)
+	}
+	globalStorageClassStats.updateStats(xlMeta.Meta[amzStorageClass], xlMeta.Stat.Size)
+	globalBucketQuotaUsage.Add(bucket, oldObjInfo.StorageClass, -oldObjInfo.Size)
+	globalBucketQuotaUsage.Add(bucket, xlMeta.Meta[amzStorageClass], xlMeta.Stat.Size)
+
 	objInfo := ObjectInfo{
 		IsDir:           false,
 		Bucket:          bucket,
@@ -1096,6 +1147,7 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 		ETag:            xlMeta.Meta["etag"],
 		ContentType:     xlMeta.Meta["content-type"],
 		ContentEncoding: xlMeta.Meta["content-encoding"],
+		StorageClass:    getObjectStorageClass(xlMeta.Meta),
 		UserDefined:     xlMeta.Meta,
 	}
 
@@ -1143,7 +1195,7 @@ func (xl xlObjects) abortMultipartUpload(bucket, object, uploadID string) (err e
 	partsMetadata, errs := readAllXLMetadata(xl.storageDisks, minioMetaMultipartBucket, uploadIDPath)
 
 	// get Quorum for this object
-	_, writeQuorum, err := objectQuorumFromMeta(xl, partsMetadata, errs)
+	_, writeQuorum, err := objectQuorumFromMeta(context.Background(), xl, bucket, object, partsMetadata, errs)
 	if err != nil {
 		return toObjectErr(err, bucket, object)
 	}
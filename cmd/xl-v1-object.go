@@ -17,6 +17,7 @@
 package cmd
 
 import (
+	"context"
 	"encoding/hex"
 	"io"
 	"path"
@@ -62,7 +63,7 @@ func (xl xlObjects) CopyObject(srcBucket, srcObject, dstBucket, dstObject string
 	metaArr, errs := readAllXLMetadata(xl.storageDisks, srcBucket, srcObject)
 
 	// get Quorum for this object
-	readQuorum, writeQuorum, err := objectQuorumFromMeta(xl, metaArr, errs)
+	readQuorum, writeQuorum, err := objectQuorumFromMeta(context.Background(), xl, srcBucket, srcObject, metaArr, errs)
 	if err != nil {
 		return oi, toObjectErr(err, srcBucket, srcObject)
 	}
@@ -83,12 +84,42 @@ func (xl xlObjects) CopyObject(srcBucket, srcObject, dstBucket, dstObject string
 	// Reorder online disks based on erasure distribution order.
 	onlineDisks = shuffleDisks(onlineDisks, xlMeta.Erasure.Distribution)
 
+	// Objects without storage class are written with the bucket default storage class.
+	setBucketDefaultStorageClass(dstBucket, metadata)
+
+	// Keep track of the storage classes the object has lived in.
+	recordStorageClassTransition(xlMeta.Meta, metadata, UTCNow())
+
 	// Length of the file to read.
 	length := xlMeta.Stat.Size
 
-	// Check if this request is only metadata update.
+	// Check if this request is only metadata update, a change in
+	// storage class needs the object to be rewritten with the new
+	// data and parity layout. If the new storage class resolves to
+	// the same parity only the storage class label is updated. Storage
+	// class metadata is compared as is, an object without storage class
+	// is laid out with the default parity, not with the Standard parity.
+	// Parity is tuned for the size of the object like on PutObject.
 	cpMetadataOnly := isStringEqual(pathJoin(srcBucket, srcObject), pathJoin(dstBucket, dstObject))
+	if xlMeta.Meta[amzStorageClass] != metadata[amzStorageClass] || xlMeta.Meta[forceParityKey] != metadata[forceParityKey] {
+		scInfo, err := getObjectRedundancyInfo(dstBucket, metadata, xl.setDriveCount(), length)
+		if err != nil {
+			return oi, toObjectErr(errors.Trace(err), dstBucket, dstObject)
+		}
+		if tuneParityForSize(scInfo, length, metadata).Parity != xlMeta.Erasure.ParityBlocks {
+			cpMetadataOnly = false
+		}
+	}
 	if cpMetadataOnly {
+		// Parity is unchanged, nothing is downgraded.
+		popForceDowngrade(metadata)
+		oldClass, newClass := getObjectStorageClass(xlMeta.Meta), getObjectStorageClass(metadata)
+		if oldClass != newClass {
+			// Object is now accounted against the quota of the new storage class.
+			if err = checkBucketStorageClassQuota(xl, srcBucket, newClass, length, ObjectInfo{}); err != nil {
+				return oi, toObjectErr(errors.Trace(err), srcBucket, srcObject)
+			}
+		}
 		xlMeta.Meta = metadata
 		partsMetadata := make([]xlMetaV1, len(xl.storageDisks))
 		// Update `xl.json` content on each disks.
@@ -106,6 +137,12 @@ func (xl xlObjects) CopyObject(srcBucket, srcObject, dstBucket, dstObject string
 		if _, err = renameXLMetadata(onlineDisks, minioMetaTmpBucket, tempObj, srcBucket, srcObject, writeQuorum); err != nil {
 			return oi, toObjectErr(err, srcBucket, srcObject)
 		}
+		if oldClass != newClass && !isMinioMetaBucketName(srcBucket) {
+			globalStorageClassStats.overwriteStats(oldClass, length)
+			globalStorageClassStats.updateStats(newClass, length)
+		}
+		globalBucketQuotaUsage.Add(srcBucket, oldClass, -length)
+		globalBucketQuotaUsage.Add(srcBucket, newClass, length)
 		return xlMeta.ToObjectInfo(srcBucket, srcObject), nil
 	}
 
@@ -138,6 +175,38 @@ func (xl xlObjects) CopyObject(srcBucket, srcObject, dstBucket, dstObject string
 	return objInfo, nil
 }
 
+// Returns StorageClassDowngrade if the bucket has storage class downgrade
+// protection and the object exists with a storage class more durable than
+// sc, the storage class it is overwritten with, see compareStorageClass.
+// Storage classes are compared rather than the parity the objects are laid
+// out with, so that parity lowered for small objects or forced while
+// writing is not mistaken for a change of storage class. A forced
+// downgrade is allowed.
+func (xl xlObjects) checkStorageClassDowngrade(bucket, object, sc string, force bool) error {
+	if force || !isBucketDowngradeProtected(bucket) {
+		return nil
+	}
+	metaArr, errs := readAllXLMetadata(xl.storageDisks, bucket, object)
+	_, modTime := listOnlineDisks(xl.storageDisks, metaArr, errs)
+	xlMeta, err := pickValidXLMeta(metaArr, modTime)
+	if err != nil {
+		// Object doesn't exist, nothing is downgraded.
+		return nil
+	}
+	from := getObjectStorageClass(xlMeta.Meta)
+	if compareStorageClass(sc, from) < 0 {
+		return StorageClassDowngrade{
+			Bucket:     bucket,
+			Object:     object,
+			From:       from,
+			FromParity: getRedundancyCount(from, xl.setDriveCount()).Parity,
+			To:         sc,
+			ToParity:   getRedundancyCount(sc, xl.setDriveCount()).Parity,
+		}
+	}
+	return nil
+}
+
 // GetObject - reads an object erasured coded across multiple
 // disks. Supports additional parameters like offset and length
 // which are synonymous with HTTP Range requests.
@@ -163,10 +232,14 @@ func (xl xlObjects) GetObject(bucket, object string, startOffset int64, length i
 	metaArr, errs := readAllXLMetadata(xl.storageDisks, bucket, object)
 
 	// get Quorum for this object
-	readQuorum, _, err := objectQuorumFromMeta(xl, metaArr, errs)
+	qInfo, err := objectQuorumInfoFromMeta(xl, metaArr, errs)
+	// Account how far the object is from losing read quorum.
+	globalQuorumMarginStats.updateStats(qInfo)
 	if err != nil {
+		errorIf(err, "Unable to read %s/%s, %s", bucket, object, qInfo)
 		return toObjectErr(err, bucket, object)
 	}
+	readQuorum := qInfo.ReadQuorum
 
 	if reducedErr := reduceReadQuorumErrs(errs, objectOpIgnoredErrs, readQuorum); reducedErr != nil {
 		return toObjectErr(reducedErr, bucket, object)
@@ -344,6 +417,10 @@ func (xl xlObjects) getObjectInfo(bucket, object string) (objInfo ObjectInfo, er
 	// Extract etag.
 	objInfo.ETag = extractETag(xlMetaMap)
 
+	// Extract storage class, before STANDARD storage
+	// class is removed from the user defined metadata.
+	objInfo.StorageClass = getObjectStorageClass(xlMetaMap)
+
 	// etag/md5Sum has already been extracted. We need to
 	// remove to avoid it from appearing as part of
 	// response headers. e.g, X-Minio-* or X-Amz-*.
@@ -507,12 +584,47 @@ func (xl xlObjects) PutObject(bucket string, object string, data *hash.Reader, m
 			}
 		}
 	}
+	// Objects without storage class are written with the bucket default storage class.
+	requestedClass := metadata[amzStorageClass]
+	setBucketDefaultStorageClass(bucket, metadata)
+
 	// Get parity and data drive count based on storage class metadata
-	dataDrives, parityDrives := getRedundancyCount(metadata[amzStorageClass], len(xl.storageDisks))
+	scInfo, err := getObjectRedundancyInfo(bucket, metadata, xl.setDriveCount(), data.Size())
+	if err != nil {
+		return ObjectInfo{}, toObjectErr(errors.Trace(err), bucket, object)
+	}
+	// Small objects may be written with less parity.
+	scInfo = tuneParityForSize(scInfo, data.Size(), metadata)
+	dataDrives, parityDrives := scInfo.Data, scInfo.Parity
+	auditStorageClass(bucket, object, requestedClass, scInfo)
 
 	// we now know the number of blocks this object needs for data and parity.
-	// writeQuorum is dataBlocks + 1
-	writeQuorum := dataDrives + 1
+	// writeQuorum is dataBlocks + 1 unless the storage class quorum policy says otherwise
+	_, writeQuorum := quorumFromDataBlocks(metadata[amzStorageClass], dataDrives)
+
+	// Fail fast if write quorum can't be met with the disks currently online.
+	if err = checkOnlineWriteQuorum(scInfo.Class, writeQuorum, countOnlineDisks(xl.storageDisks)); err != nil {
+		return ObjectInfo{}, toObjectErr(errors.Trace(err), bucket, object)
+	}
+
+	// Reject overwriting the object with a less durable storage class, if the bucket protects against it.
+	if err = xl.checkStorageClassDowngrade(bucket, object, scInfo.Class, popForceDowngrade(metadata)); err != nil {
+		return ObjectInfo{}, toObjectErr(errors.Trace(err), bucket, object)
+	}
+
+	// Object overwritten by the write, only looked up for buckets with
+	// a storage class quota to account the bytes freed by the write.
+	var oldObjInfo ObjectInfo
+	if hasBucketStorageClassQuota(bucket) {
+		if oi, oErr := xl.getObjectInfo(bucket, object); oErr == nil {
+			oldObjInfo = oi
+		}
+	}
+
+	// Reject the object if it exceeds the bucket quota of its storage class.
+	if err = checkBucketStorageClassQuota(xl, bucket, getObjectStorageClass(metadata), data.Size(), oldObjInfo); err != nil {
+		return ObjectInfo{}, toObjectErr(errors.Trace(err), bucket, object)
+	}
 
 	// Initialize parts metadata
 	partsMetadata := make([]xlMetaV1, len(xl.storageDisks))
@@ -612,17 +724,23 @@ func (xl xlObjects) PutObject(bucket string, object string, data *hash.Reader, m
 		}
 	}
 
+	// Object overwritten by the write, deleted once the write is done.
+	var oldUniqueID string
 	if xl.isObject(bucket, object) {
 		// Rename if an object already exists to temporary location.
-		newUniqueID := mustGetUUID()
+		oldUniqueID = mustGetUUID()
 
-		// Delete successfully renamed object.
-		defer xl.deleteObject(minioMetaTmpBucket, newUniqueID)
+		// Delete successfully renamed object if the write fails.
+		defer func() {
+			if oldUniqueID != "" {
+				xl.deleteObject(minioMetaTmpBucket, oldUniqueID)
+			}
+		}()
 
 		// NOTE: Do not use online disks slice here.
 		// The reason is that existing object should be purged
 		// regardless of `xl.json` status and rolled back in case of errors.
-		_, err = renameObject(xl.storageDisks, bucket, object, minioMetaTmpBucket, newUniqueID, writeQuorum)
+		_, err = renameObject(xl.storageDisks, bucket, object, minioMetaTmpBucket, oldUniqueID, writeQuorum)
 		if err != nil {
 			return ObjectInfo{}, toObjectErr(err, bucket, object)
 		}
@@ -656,6 +774,24 @@ func (xl xlObjects) PutObject(bucket string, object string, data *hash.Reader, m
 	// of the first disk
 	xlMeta = partsMetadata[0]
 
+	// Delete the overwritten object, its `xl.json` is no longer accounted.
+	var oldXLMeta xlMetaV1
+	if oldUniqueID != "" {
+		oldXLMeta, _ = xl.deleteObject(minioMetaTmpBucket, oldUniqueID)
+		oldUniqueID = ""
+	}
+
+	// Account the object against its storage class, internal
+	// objects in minio meta buckets are not accounted.
+	if !isMinioMetaBucketName(bucket) {
+		if oldXLMeta.IsValid() {
+			globalStorageClassStats.overwriteStats(oldXLMeta.Meta[amzStorageClass], oldXLMeta.Stat.Size)
+		}
+		globalStorageClassStats.updateStats(xlMeta.Meta[amzStorageClass], xlMeta.Stat.Size)
+		globalBucketQuotaUsage.Add(bucket, oldObjInfo.StorageClass, -oldObjInfo.Size)
+		globalBucketQuotaUsage.Add(bucket, xlMeta.Meta[amzStorageClass], xlMeta.Stat.Size)
+	}
+
 	objInfo = ObjectInfo{
 		IsDir:           false,
 		Bucket:          bucket,
@@ -665,6 +801,7 @@ func (xl xlObjects) PutObject(bucket string, object string, data *hash.Reader, m
 		ETag:            xlMeta.Meta["etag"],
 		ContentType:     xlMeta.Meta["content-type"],
 		ContentEncoding: xlMeta.Meta["content-encoding"],
+		StorageClass:    getObjectStorageClass(xlMeta.Meta),
 		UserDefined:     xlMeta.Meta,
 	}
 
@@ -674,8 +811,9 @@ func (xl xlObjects) PutObject(bucket string, object string, data *hash.Reader, m
 
 // deleteObject - wrapper for delete object, deletes an object from
 // all the disks in parallel, including `xl.json` associated with the
-// object.
-func (xl xlObjects) deleteObject(bucket, object string) error {
+// object. Returns the latest `xl.json` of the deleted object, e.g. to
+// account the bytes freed by the deletion.
+func (xl xlObjects) deleteObject(bucket, object string) (xlMetaV1, error) {
 	// Initialize sync waitgroup.
 	var wg = &sync.WaitGroup{}
 
@@ -683,9 +821,14 @@ func (xl xlObjects) deleteObject(bucket, object string) error {
 	metaArr, errs := readAllXLMetadata(xl.storageDisks, bucket, object)
 
 	// get Quorum for this object
-	_, writeQuorum, err := objectQuorumFromMeta(xl, metaArr, errs)
+	_, writeQuorum, err := objectQuorumFromMeta(context.Background(), xl, bucket, object, metaArr, errs)
 	if err != nil {
-		return err
+		return xlMetaV1{}, err
+	}
+
+	var xlMeta xlMetaV1
+	if index, _ := getLatestXLMetaIndex(metaArr, errs); index != -1 {
+		xlMeta = metaArr[index]
 	}
 
 	// Initialize list of errors.
@@ -709,7 +852,25 @@ func (xl xlObjects) deleteObject(bucket, object string) error {
 	// Wait for all routines to finish.
 	wg.Wait()
 
-	return reduceWriteQuorumErrs(dErrs, objectOpIgnoredErrs, writeQuorum)
+	return xlMeta, reduceWriteQuorumErrs(dErrs, objectOpIgnoredErrs, writeQuorum)
+}
+
+// Returns the bytes an object takes across all the disks, parity included,
+// as laid out in its xlMeta. If the layout is missing the object is assumed
+// to be laid out with the data and parity disks of its storage class, or
+// with the default data and parity disks if the storage class is missing
+// as well.
+func getRawObjectSize(xlMeta xlMetaV1, totalDisks int) int64 {
+	size := xlMeta.Stat.Size
+	data, parity := xlMeta.Erasure.DataBlocks, xlMeta.Erasure.ParityBlocks
+	if data <= 0 {
+		info := getRedundancyCount(xlMeta.Meta[amzStorageClass], totalDisks)
+		data, parity = info.Data, info.Parity
+	}
+	if size <= 0 || data <= 0 {
+		return 0
+	}
+	return erasureShardSize(size, data) * int64(data+parity)
 }
 
 // DeleteObject - deletes an object, this call doesn't necessary reply
@@ -726,11 +887,19 @@ func (xl xlObjects) DeleteObject(bucket, object string) (err error) {
 	} // else proceed to delete the object.
 
 	// Delete the object on all disks.
-	err = xl.deleteObject(bucket, object)
+	xlMeta, err := xl.deleteObject(bucket, object)
 	if err != nil {
 		return toObjectErr(err, bucket, object)
 	}
 
+	// Account the bytes freed by the deletion from the storage class and
+	// the layout of the object, objects in minio meta buckets are not accounted.
+	if !isMinioMetaBucketName(bucket) {
+		sc := xlMeta.Meta[amzStorageClass]
+		globalStorageClassStats.deleteStats(sc, xlMeta.Stat.Size, getRawObjectSize(xlMeta, xl.setDriveCount()))
+		globalBucketQuotaUsage.Add(bucket, sc, -xlMeta.Stat.Size)
+	}
+
 	if xl.objCacheEnabled {
 		// Delete from the cache.
 		xl.objCache.Delete(pathJoin(bucket, object))
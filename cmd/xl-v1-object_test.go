@@ -257,7 +257,8 @@ func TestPutObjectNoQuorum(t *testing.T) {
 		// Upload new content to same object "object"
 		_, err = obj.PutObject(bucket, object, mustGetHashReader(t, bytes.NewReader([]byte("abcd")), int64(len("abcd")), "", ""), nil)
 		err = errors.Cause(err)
-		if err != toObjectErr(errXLWriteQuorum, bucket, object) {
+		// Disks failing before the write are caught by the online write quorum check.
+		if _, ok := err.(StorageClassWriteQuorum); !ok && err != toObjectErr(errXLWriteQuorum, bucket, object) {
 			t.Errorf("Expected putObject to fail with %v, but failed with %v", toObjectErr(errXLWriteQuorum, bucket, object), err)
 		}
 	}
@@ -265,6 +266,183 @@ func TestPutObjectNoQuorum(t *testing.T) {
 	removeRoots(fsDirs)
 }
 
+// Tests that PutObject fails before writing if fewer disks are
+// online than the write quorum of the storage class.
+func TestPutObjectOnlineWriteQuorum(t *testing.T) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+
+	obj, fsDirs, err := prepareXL16()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+	xl := obj.(*xlObjects)
+
+	bucket := "bucket"
+	if err = obj.MakeBucketWithLocation(bucket, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// Leave 12 out of 16 disks online.
+	for i := range xl.storageDisks[:4] {
+		xl.storageDisks[i] = nil
+	}
+
+	tests := []struct {
+		name        int
+		sc          string
+		expectedErr error
+	}{
+		// Standard storage class needs 9 disks online.
+		{1, standardStorageClass, nil},
+		// Reduced redundancy storage class needs 15 disks online.
+		{2, reducedRedundancyStorageClass, StorageClassWriteQuorum{StorageClass: reducedRedundancyStorageClass, WriteQuorum: 15, OnlineDisks: 12}},
+	}
+	for _, tt := range tests {
+		metadata := map[string]string{amzStorageClass: tt.sc}
+		_, err = obj.PutObject(bucket, "object", mustGetHashReader(t, bytes.NewReader([]byte("abcd")), int64(len("abcd")), "", ""), metadata)
+		if err = errors.Cause(err); err != tt.expectedErr {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedErr, err)
+		}
+	}
+}
+
+func TestPutObjectStorageClassDowngrade(t *testing.T) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+
+	obj, fsDirs, err := prepareXL16()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+	// Storage classes are compared by their parity on the disks of the setup.
+	globalEndpoints = mustGetNewEndpointList(fsDirs...)
+	defer resetGlobalEndpoints()
+
+	bucket := "bucket"
+	if err = obj.MakeBucketWithLocation(bucket, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err = initBucketStorageClass(obj); err != nil {
+		t.Fatal(err)
+	}
+	globalBucketStorageClass.SetBucketStorageClass(bucket, &bucketStorageClassConfig{DowngradeProtection: true})
+	defer globalBucketStorageClass.SetBucketStorageClass(bucket, nil)
+
+	downgradeErr := StorageClassDowngrade{
+		Bucket:     bucket,
+		Object:     "object",
+		From:       standardStorageClass,
+		FromParity: 8,
+		To:         reducedRedundancyStorageClass,
+		ToParity:   2,
+	}
+	tests := []struct {
+		name        int
+		sc          string
+		force       string
+		expectedErr error
+	}{
+		// New object, nothing is downgraded.
+		{1, standardStorageClass, "", nil},
+		{2, reducedRedundancyStorageClass, "", downgradeErr},
+		{3, reducedRedundancyStorageClass, "false", downgradeErr},
+		{4, reducedRedundancyStorageClass, "true", nil},
+		// Upgrade is always allowed.
+		{5, standardStorageClass, "", nil},
+	}
+	for _, tt := range tests {
+		metadata := map[string]string{amzStorageClass: tt.sc}
+		if tt.force != "" {
+			metadata[forceDowngradeKey] = tt.force
+		}
+		_, err = obj.PutObject(bucket, "object", mustGetHashReader(t, bytes.NewReader([]byte("abcd")), int64(len("abcd")), "", ""), metadata)
+		if err = errors.Cause(err); err != tt.expectedErr {
+			t.Errorf("Test %d, Expected %v, got %v", tt.name, tt.expectedErr, err)
+		}
+	}
+
+	objInfo, err := obj.GetObjectInfo(bucket, "object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := objInfo.UserDefined[forceDowngradeKey]; ok {
+		t.Errorf("Expected forced downgrade not to be saved with the object")
+	}
+
+	// Copy is rejected like any other overwrite.
+	_, err = obj.CopyObject(bucket, "object", bucket, "object", map[string]string{amzStorageClass: reducedRedundancyStorageClass})
+	if err = errors.Cause(err); err != downgradeErr {
+		t.Errorf("Expected %v, got %v", downgradeErr, err)
+	}
+
+	// Storage classes are compared, not parity. An object written with
+	// forced parity is overwritten with the parity of the same storage class.
+	metadata := map[string]string{amzStorageClass: reducedRedundancyStorageClass, forceParityKey: "6", forceDowngradeKey: "true"}
+	if _, err = obj.PutObject(bucket, "forced", mustGetHashReader(t, bytes.NewReader([]byte("abcd")), int64(len("abcd")), "", ""), metadata); err != nil {
+		t.Fatal(err)
+	}
+	metadata = map[string]string{amzStorageClass: reducedRedundancyStorageClass}
+	if _, err = obj.PutObject(bucket, "forced", mustGetHashReader(t, bytes.NewReader([]byte("abcd")), int64(len("abcd")), "", ""), metadata); err != nil {
+		t.Errorf("Expected overwrite with the same storage class to be allowed, got %v", err)
+	}
+}
+
+// Tests that small objects are written with the tuned parity.
+func TestPutObjectSmallObjectParity(t *testing.T) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+	defer resetGlobalStorageEnvs()
+	globalSmallObjectParityThresholds = map[string]int64{standardStorageClass: 1024}
+
+	obj, fsDirs, err := prepareXL16()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+	xl := obj.(*xlObjects)
+	globalEndpoints = mustGetNewEndpointList(fsDirs...)
+	defer resetGlobalEndpoints()
+
+	bucket := "bucket"
+	if err = obj.MakeBucketWithLocation(bucket, ""); err != nil {
+		t.Fatal(err)
+	}
+	// Tuned parity is not a downgrade of the storage class.
+	if err = initBucketStorageClass(obj); err != nil {
+		t.Fatal(err)
+	}
+	globalBucketStorageClass.SetBucketStorageClass(bucket, &bucketStorageClassConfig{DowngradeProtection: true})
+	defer globalBucketStorageClass.SetBucketStorageClass(bucket, nil)
+
+	tests := []struct {
+		name           int
+		size           int
+		expectedParity int
+	}{
+		{1, 100, 2},
+		{2, 1024, 8},
+		// Large object overwritten with a small object of the same storage class.
+		{3, 100, 2},
+	}
+	for _, tt := range tests {
+		data := bytes.Repeat([]byte("a"), tt.size)
+		if _, err = obj.PutObject(bucket, "object", mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), nil); err != nil {
+			t.Fatalf("Test %d, Unexpected error %v", tt.name, err)
+		}
+		xlMeta, err := readXLMeta(xl.storageDisks[0], bucket, "object")
+		if err != nil {
+			t.Fatalf("Test %d, Unexpected error %v", tt.name, err)
+		}
+		if xlMeta.Erasure.ParityBlocks != tt.expectedParity {
+			t.Errorf("Test %d, Expected parity %d, got %d", tt.name, tt.expectedParity, xlMeta.Erasure.ParityBlocks)
+		}
+	}
+}
+
 // Tests both object and bucket healing.
 func TestHealing(t *testing.T) {
 	rootPath, err := newTestConfig(globalMinioDefaultRegion)
@@ -370,3 +548,150 @@ func TestHealing(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// Tests that quorum margin of objects read is accounted if enabled.
+func TestGetObjectQuorumMarginStats(t *testing.T) {
+	obj, fsDirs, err := prepareXL16()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+	globalQuorumMarginStats = newQuorumMarginStats()
+	defer func() { globalQuorumMarginStats = nil }()
+
+	bucket := "bucket"
+	if err = obj.MakeBucketWithLocation(bucket, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = obj.PutObject(bucket, "object", mustGetHashReader(t, bytes.NewReader([]byte("abcd")), int64(len("abcd")), "", ""), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err = obj.GetObject(bucket, "object", 0, 4, ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	// All 16 xl.json(s) are valid, 8 above the 8 data blocks.
+	stats := globalQuorumMarginStats.toServerQuorumMarginStats()
+	if stats.Margins[8] != 1 {
+		t.Errorf("Expected 1 read with margin 8, got %v", stats)
+	}
+}
+
+// Tests deleting objects frees the bytes of their storage class.
+func TestDeleteObjectStorageClassStats(t *testing.T) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+
+	obj, fsDirs, err := prepareXL16()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+	globalStorageClassStats = newStorageClassStats()
+	defer func() { globalStorageClassStats = newStorageClassStats() }()
+
+	bucket := "bucket"
+	if err = obj.MakeBucketWithLocation(bucket, ""); err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Repeat([]byte("a"), 1000)
+	for object, sc := range map[string]string{"standard": "", "rrs": reducedRedundancyStorageClass} {
+		metadata := map[string]string{}
+		if sc != "" {
+			metadata[amzStorageClass] = sc
+		}
+		if _, err = obj.PutObject(bucket, object, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), metadata); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, object := range []string{"standard", "rrs"} {
+		if err = obj.DeleteObject(bucket, object); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 1000 bytes are 63 bytes per disk with 16 data disks, 72 bytes with
+	// 14 data disks for Reduced Redundancy storage class, 125 bytes with
+	// 8 data disks for Standard storage class.
+	expected := ServerStorageClassStats{
+		Standard: ServerStorageClassCounter{FreedBytes: 125 * 16},
+		RRS:      ServerStorageClassCounter{FreedBytes: 72 * 16},
+	}
+	if got := globalStorageClassStats.toServerStorageClassStats(); got != expected {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+// Tests overwritten objects are no longer accounted against their
+// storage class, the objects overwriting them are.
+func TestOverwriteObjectStorageClassStats(t *testing.T) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+
+	obj, fsDirs, err := prepareXL16()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+	globalStorageClassStats = newStorageClassStats()
+	defer func() { globalStorageClassStats = newStorageClassStats() }()
+
+	bucket := "bucket"
+	if err = obj.MakeBucketWithLocation(bucket, ""); err != nil {
+		t.Fatal(err)
+	}
+	for _, sc := range []string{"", reducedRedundancyStorageClass, reducedRedundancyStorageClass} {
+		data := bytes.Repeat([]byte("a"), 1000)
+		metadata := map[string]string{}
+		if sc != "" {
+			data = data[:500]
+			metadata[amzStorageClass] = sc
+		}
+		if _, err = obj.PutObject(bucket, "object", mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), metadata); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Overwriting is not a deletion, no bytes are accounted as freed.
+	expected := ServerStorageClassStats{
+		RRS: ServerStorageClassCounter{Objects: 1, Bytes: 500},
+	}
+	if got := globalStorageClassStats.toServerStorageClassStats(); got != expected {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestGetRawObjectSize(t *testing.T) {
+	// Reset global storage class flags
+	resetGlobalStorageEnvs()
+
+	newXLMeta := func(size int64, sc string, data, parity int) xlMetaV1 {
+		xlMeta := xlMetaV1{}
+		xlMeta.Stat.Size = size
+		xlMeta.Erasure.DataBlocks, xlMeta.Erasure.ParityBlocks = data, parity
+		if sc != "" {
+			xlMeta.Meta = map[string]string{amzStorageClass: sc}
+		}
+		return xlMeta
+	}
+	tests := []struct {
+		name         int
+		xlMeta       xlMetaV1
+		expectedSize int64
+	}{
+		{1, newXLMeta(1000, standardStorageClass, 8, 8), 125 * 16},
+		{2, newXLMeta(1000, reducedRedundancyStorageClass, 14, 2), 72 * 16},
+		// Layout in xlMeta is used, irrespective of the storage class.
+		{3, newXLMeta(1000, standardStorageClass, 12, 4), 84 * 16},
+		// Missing layout is resolved from the storage class.
+		{4, newXLMeta(1000, reducedRedundancyStorageClass, 0, 0), 72 * 16},
+		// Missing storage class is resolved to the default layout.
+		{5, newXLMeta(1000, "", 0, 0), 125 * 16},
+		{6, newXLMeta(0, "", 0, 0), 0},
+	}
+	for _, tt := range tests {
+		if size := getRawObjectSize(tt.xlMeta, 16); size != tt.expectedSize {
+			t.Errorf("Test %d, Expected %d, got %d", tt.name, tt.expectedSize, size)
+		}
+	}
+}
@@ -241,17 +241,20 @@ func xlMetaV1UnmarshalJSON(xlMetaBuf []byte) (xlMeta xlMetaV1, e error) {
 	return xlMeta, nil
 }
 
-// read xl.json from the given disk, parse and return xlV1MetaV1.Parts.
-func readXLMetaParts(disk StorageAPI, bucket string, object string) ([]objectPartInfo, error) {
+// read xl.json from the given disk, parse and return xlV1MetaV1.Parts and xlV1MetaV1.Meta.
+func readXLMetaParts(disk StorageAPI, bucket string, object string) ([]objectPartInfo, map[string]string, error) {
 	// Reads entire `xl.json`.
 	xlMetaBuf, err := disk.ReadAll(bucket, path.Join(object, xlMetaJSONFile))
 	if err != nil {
-		return nil, errors2.Trace(err)
+		return nil, nil, errors2.Trace(err)
 	}
 	// obtain xlMetaV1{}.Partsusing `github.com/tidwall/gjson`.
 	xlMetaParts := parseXLParts(xlMetaBuf)
 
-	return xlMetaParts, nil
+	// obtain xlMetaV1{}.Meta using `github.com/tidwall/gjson`.
+	xlMetaMap := parseXLMetaMap(xlMetaBuf)
+
+	return xlMetaParts, xlMetaMap, nil
 }
 
 // read xl.json from the given disk and parse xlV1Meta.Stat and xlV1Meta.Meta using gjson.
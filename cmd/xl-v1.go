@@ -74,6 +74,23 @@ func newXLObjectLayer(storageDisks []StorageAPI) (ObjectLayer, error) {
 	err = initBucketPolicies(objAPI)
 	fatalIf(err, "Unable to load all bucket policies.")
 
+	// Initialize and load bucket storage class configs.
+	err = initBucketStorageClass(objAPI)
+	fatalIf(err, "Unable to load all bucket storage class configs.")
+
+	// Storage class parity is resolved for the disks of the erasure set
+	// an object is written to, validate it for every set.
+	ssc, rrsc, maxsc := getStorageClassGlobals()
+	err = validateStorageClassForSets(ssc, rrsc, maxsc, []int{objAPI.(*xlObjects).setDriveCount()})
+	fatalIf(err, "Invalid storage class for the erasure sets.")
+
+	// Warn about storage classes which can not be written with
+	// the disks online during startup, this is only advisory.
+	scInfo := getStorageClassInfo()
+	for _, err = range scInfo.checkFeasibility(objAPI.StorageInfo().Backend.OnlineDisks) {
+		errorIf(err, "Storage class is not writable with the disks currently online.")
+	}
+
 	// Initialize a new event notifier.
 	err = initEventNotifier(objAPI)
 	fatalIf(err, "Unable to initialize event notification.")
@@ -154,6 +171,11 @@ func newXLObjects(storageDisks []StorageAPI) (ObjectLayer, error) {
 	// Start background process to cleanup old multipart objects in `.minio.sys`.
 	go cleanupStaleMultipartUploads(multipartCleanupInterval, multipartExpiry, xl, xl.listMultipartUploadsCleanup, globalServiceDoneCh)
 
+	// Start background process to transition objects as per the bucket storage class transition rules.
+	if isStorageClassTransitionServer(globalEndpoints) {
+		go transitionStorageClasses(storageClassTransitionInterval, xl, globalServiceDoneCh)
+	}
+
 	return xl, nil
 }
 
@@ -171,6 +193,13 @@ func (xl xlObjects) Shutdown() error {
 	return nil
 }
 
+// setDriveCount - number of disks of the erasure set objects are written
+// to, storage class parity is resolved for these disks. XL runs a single
+// erasure set spanning all its disks.
+func (xl xlObjects) setDriveCount() int {
+	return len(xl.storageDisks)
+}
+
 // byDiskTotal is a collection satisfying sort.Interface.
 type byDiskTotal []disk.Info
 
@@ -205,6 +234,22 @@ func getDisksInfo(disks []StorageAPI) (disksInfo []disk.Info, onlineDisks int, o
 	return disksInfo, onlineDisks, offlineDisks
 }
 
+// Returns the number of disks online without any disk I/O, a disk is
+// offline if it is not available or was marked offline by retryStorage.
+// Disks failing in between are caught by the write quorum of the write.
+func countOnlineDisks(disks []StorageAPI) (onlineDisks int) {
+	for _, storageDisk := range disks {
+		if storageDisk == nil {
+			continue
+		}
+		if rs, ok := storageDisk.(*retryStorage); ok && rs.IsOffline() {
+			continue
+		}
+		onlineDisks++
+	}
+	return onlineDisks
+}
+
 // returns sorted disksInfo slice which has only valid entries.
 // i.e the entries where the total size of the disk is not stated
 // as 0Bytes, this means that the disk is not online or ignored.
@@ -240,17 +285,17 @@ func getStorageInfo(disks []StorageAPI) StorageInfo {
 	storageInfo := StorageInfo{
 		Total: validDisksInfo[0].Total * uint64(onlineDisks) / 2,
 		Free:  validDisksInfo[0].Free * uint64(onlineDisks) / 2,
+		// Usable free space depends on the parity objects are written with.
+		UsableFree:     getUsableFreeSpaces(validDisksInfo[0].Free*uint64(onlineDisks), len(disks)),
+		StorageClasses: getStorageClassLayouts(len(disks)),
 	}
 
 	storageInfo.Backend.Type = Erasure
 	storageInfo.Backend.OnlineDisks = onlineDisks
 	storageInfo.Backend.OfflineDisks = offlineDisks
 
-	_, scParity := getRedundancyCount(standardStorageClass, len(disks))
-	storageInfo.Backend.standardSCParity = scParity
-
-	_, rrSCparity := getRedundancyCount(reducedRedundancyStorageClass, len(disks))
-	storageInfo.Backend.rrSCParity = rrSCparity
+	storageInfo.Backend.standardSCParity = getRedundancyCount(standardStorageClass, len(disks)).Parity
+	storageInfo.Backend.rrSCParity = getRedundancyCount(reducedRedundancyStorageClass, len(disks)).Parity
 
 	return storageInfo
 }
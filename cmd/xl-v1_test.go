@@ -21,6 +21,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/minio/minio/pkg/disk"
 )
@@ -50,6 +51,20 @@ func TestStorageInfo(t *testing.T) {
 	if disks16Info.Total <= 0 {
 		t.Fatalf("Diskinfo total values should be greater 0")
 	}
+	// Usable free space of Standard storage class with N/2 parity is the free space.
+	if disks16Info.UsableFree[standardStorageClass] != disks16Info.Free {
+		t.Fatalf("Expected usable free space %d, got %d", disks16Info.Free, disks16Info.UsableFree[standardStorageClass])
+	}
+	if disks16Info.UsableFree[reducedRedundancyStorageClass] <= disks16Info.Free {
+		t.Fatalf("Expected usable free space of reduced redundancy to be greater than %d", disks16Info.Free)
+	}
+	// Storage classes resolve to their default parity.
+	if layout := disks16Info.StorageClasses[standardStorageClass]; layout.Data != 8 || layout.Parity != 8 {
+		t.Fatalf("Expected 8 data and 8 parity disks, got %d data and %d parity disks", layout.Data, layout.Parity)
+	}
+	if layout := disks16Info.StorageClasses[reducedRedundancyStorageClass]; layout.Data != 14 || layout.Parity != 2 {
+		t.Fatalf("Expected 14 data and 2 parity disks, got %d data and %d parity disks", layout.Data, layout.Parity)
+	}
 
 	storageDisks, err := initStorageDisks(mustGetNewEndpointList(fsDirs...))
 	if err != nil {
@@ -121,6 +136,33 @@ func TestSortingValidDisks(t *testing.T) {
 	}
 }
 
+// Tests counting online disks without any disk I/O.
+func TestCountOnlineDisks(t *testing.T) {
+	// Disks without remote storage would panic on any disk I/O.
+	offlineDisk := &retryStorage{offline: true, offlineTimestamp: UTCNow(), retryInterval: time.Hour}
+	onlineDisk := &retryStorage{}
+
+	testCases := []struct {
+		name        int
+		disks       []StorageAPI
+		onlineDisks int
+	}{
+		// All disks online.
+		{1, []StorageAPI{onlineDisk, onlineDisk, onlineDisk, onlineDisk}, 4},
+		// Missing disks are offline.
+		{2, []StorageAPI{nil, onlineDisk, nil, onlineDisk}, 2},
+		// Disks marked offline are offline.
+		{3, []StorageAPI{offlineDisk, onlineDisk, nil, onlineDisk}, 2},
+		// No disks online.
+		{4, []StorageAPI{offlineDisk, nil}, 0},
+	}
+	for _, testCase := range testCases {
+		if onlineDisks := countOnlineDisks(testCase.disks); onlineDisks != testCase.onlineDisks {
+			t.Errorf("Test %d, Expected %d online disks, got %d", testCase.name, testCase.onlineDisks, onlineDisks)
+		}
+	}
+}
+
 // TestNewXL - tests initialization of all input disks
 // and constructs a valid `XL` object
 func TestNewXL(t *testing.T) {
@@ -136,6 +136,9 @@ type HealObjectInfo struct {
 	Status             HealStatus
 	MissingDataCount   int
 	MissingParityCount int
+	// Higher is more urgent, objects with the highest priority
+	// are one disk failure away from being unreadable.
+	HealPriority int
 }
 
 // ObjectInfo container for object metadata.
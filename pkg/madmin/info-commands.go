@@ -45,6 +45,12 @@ type StorageInfo struct {
 	Total int64
 	// Free available disk space.
 	Free int64
+	// Free disk space usable for object data per storage class,
+	// i.e. free disk space without erasure coding parity.
+	UsableFree map[string]int64
+	// Data and parity disks each supported storage class resolves
+	// to, only meaningful if BackendType is Erasure.
+	StorageClasses map[string]ServerStorageClassLayout
 	// Backend type.
 	Backend struct {
 		// Represents various backend types, currently on FS and Erasure.
@@ -96,6 +102,55 @@ type ServerHTTPStats struct {
 	SuccessDELETEStats ServerHTTPMethodStats `json:"successDELETEs"`
 }
 
+// ServerStorageClassCounter holds the number of objects and bytes
+// stored with a storage class, and the bytes freed on disks by
+// deleting objects of the storage class
+type ServerStorageClassCounter struct {
+	Objects    uint64 `json:"objects"`
+	Bytes      uint64 `json:"bytes"`
+	FreedBytes uint64 `json:"freedBytes"`
+}
+
+// ServerStorageClassStats holds the objects and bytes written
+// by the server per storage class
+type ServerStorageClassStats struct {
+	Standard      ServerStorageClassCounter `json:"STANDARD"`
+	RRS           ServerStorageClassCounter `json:"REDUCED_REDUNDANCY"`
+	MaxDurability ServerStorageClassCounter `json:"MAX_DURABILITY"`
+	Scratch       ServerStorageClassCounter `json:"SCRATCH"`
+}
+
+// ServerQuorumMarginStats holds the number of objects read per quorum
+// margin, the number of valid xl.json(s) found above the data blocks
+// needed. Margins[i] is the number of reads with margin i, the last
+// element also counts larger margins. Lost is the number of reads
+// below read quorum. Empty if the stats are disabled.
+type ServerQuorumMarginStats struct {
+	Lost    uint64   `json:"lost"`
+	Margins []uint64 `json:"margins,omitempty"`
+}
+
+// ServerStorageClassLayout holds the data and parity disks a
+// storage class resolves to, Scheme is empty and Source is
+// "default" if the storage class parity is not configured.
+type ServerStorageClassLayout struct {
+	Scheme string `json:"scheme,omitempty"`
+	Data   int    `json:"data"`
+	Parity int    `json:"parity"`
+	Source string `json:"source"`
+}
+
+// ServerStorageClassConfig holds the active storage class
+// configuration of a server. Layouts are empty if the server
+// is not erasure coded.
+type ServerStorageClassConfig struct {
+	StorageClasses []string                 `json:"storageClasses"`
+	Standard       ServerStorageClassLayout `json:"STANDARD"`
+	RRS            ServerStorageClassLayout `json:"REDUCED_REDUNDANCY"`
+	MaxDurability  ServerStorageClassLayout `json:"MAX_DURABILITY"`
+	Scratch        ServerStorageClassLayout `json:"SCRATCH"`
+}
+
 // ServerInfoData holds storage, connections and other
 // information of a given server
 type ServerInfoData struct {
@@ -103,6 +158,11 @@ type ServerInfoData struct {
 	ConnStats   ServerConnStats  `json:"network"`
 	HTTPStats   ServerHTTPStats  `json:"http"`
 	Properties  ServerProperties `json:"server"`
+
+	StorageClassStats ServerStorageClassStats `json:"storageClass"`
+	QuorumMarginStats ServerQuorumMarginStats `json:"quorumMargin"`
+
+	StorageClassConfig ServerStorageClassConfig `json:"storageClassConfig"`
 }
 
 // ServerInfo holds server information result of one node